@@ -0,0 +1,81 @@
+package automod
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/util"
+)
+
+// lockTimeout is how long appending to the audit log waits for another
+// process.
+const lockTimeout = 5 * time.Second
+
+// Entry is an automated decision in the audit log.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	CommentID uint64    `json:"comment_id"`
+	ListID    uint64    `json:"list_id"`
+	PostID    uint64    `json:"post_id"`
+	AuthorID  uint64    `json:"author_id"`
+	Content   string    `json:"content"`
+	Decision
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// DryRun is set on decisions reported but not applied, which aren't
+	// logged.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Audit is the audit log, a file of JSON lines only ever appended to.
+type Audit struct {
+	path string
+}
+
+// DefaultAuditPath is the audit log in the state directory.
+func DefaultAuditPath() string {
+	return filepath.Join(util.GetStateDir(), "automod-audit.jsonl")
+}
+
+func OpenAudit(path string) *Audit {
+	return &Audit{path: path}
+}
+
+func (a *Audit) Path() string {
+	return a.path
+}
+
+// Append adds entries to the log.
+func (a *Audit) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	if err := util.EnsureDir(filepath.Dir(a.path)); err != nil {
+		return err
+	}
+	unlock, err := util.LockFile(a.path, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
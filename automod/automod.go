@@ -0,0 +1,204 @@
+// Package automod decides what to do with comments by rules kept in a local
+// YAML file, like marking repeated advertisements as spam.
+//
+// A rule matches a comment when all its conditions do, and the first rule
+// matching a comment decides its action:
+//
+//	rules:
+//	  - name: casino spam
+//	    action: spam
+//	    content: '(?i)casino|jackpot'
+//	    min_links: 2
+//	  - name: new accounts repeating themselves
+//	    action: reject
+//	    max_account_age: 7d
+//	    min_duplicates: 3
+package automod
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/util"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Actions are the operations rules map comments to, as OperateComment
+// takes them.
+var Actions = []string{"approve", "reject", "spam", "delete"}
+
+// Rules are the rules of a rules file, in order.
+type Rules struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule maps the comments matching all of its conditions to an action.
+type Rule struct {
+	Name   string `yaml:"name"`
+	Action string `yaml:"action"`
+
+	// Content is a regular expression the content matches.
+	Content string `yaml:"content"`
+	// Keywords match when the content has any of them, ignoring case.
+	Keywords []string `yaml:"keywords"`
+	// MinLinks matches comments with at least this many links.
+	MinLinks int `yaml:"min_links"`
+	// AuthorIDs match comments by these users.
+	AuthorIDs []uint64 `yaml:"author_ids"`
+	// MaxAccountAge matches authors whose account is younger, like 7d.
+	// Comments whose author's age is unknown don't match.
+	MaxAccountAge string `yaml:"max_account_age"`
+	// MinDuplicates matches comments whose content, ignoring case and
+	// spacing, is in at least this many comments, this one included.
+	MinDuplicates int `yaml:"min_duplicates"`
+
+	re     *regexp.Regexp
+	maxAge time.Duration
+}
+
+// DefaultPath is where the rules are read from by default.
+func DefaultPath() string {
+	return filepath.Join(util.GetConfigFilePath(), "automod.yaml")
+}
+
+// Load reads a rules file.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse parses and checks rules.
+func Parse(data []byte) (*Rules, error) {
+	rules := &Rules{}
+	if err := yaml.UnmarshalStrict(data, rules); err != nil {
+		return nil, err
+	}
+	if len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+	for i, r := range rules.Rules {
+		if r.Name == "" {
+			r.Name = "rule " + strconv.Itoa(i+1)
+		}
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) compile() error {
+	if !slices.Contains(Actions, r.Action) {
+		return fmt.Errorf("invalid action %q, use approve, reject, spam or delete", r.Action)
+	}
+	if r.Content == "" && len(r.Keywords) == 0 && r.MinLinks == 0 && len(r.AuthorIDs) == 0 && r.MaxAccountAge == "" && r.MinDuplicates == 0 {
+		return fmt.Errorf("no conditions, a rule needs at least one")
+	}
+	if r.Content != "" {
+		re, err := regexp.Compile(r.Content)
+		if err != nil {
+			return fmt.Errorf("content: %w", err)
+		}
+		r.re = re
+	}
+	for i, k := range r.Keywords {
+		r.Keywords[i] = strings.ToLower(k)
+	}
+	if r.MaxAccountAge != "" {
+		age, err := config.ParseAge(r.MaxAccountAge)
+		if err != nil {
+			return fmt.Errorf("max_account_age: %w", err)
+		}
+		r.maxAge = age
+	}
+	return nil
+}
+
+// Decision is the action a rule chose for a comment.
+type Decision struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+}
+
+// Engine applies rules to comments. It counts the contents of the comments
+// it observes, for duplicates.
+type Engine struct {
+	rules *Rules
+	// Now is the time account ages are counted from.
+	Now func() time.Time
+
+	observed map[uint64]bool
+	contents map[string]int
+}
+
+func NewEngine(rules *Rules) *Engine {
+	return &Engine{rules: rules, Now: time.Now, observed: map[uint64]bool{}, contents: map[string]int{}}
+}
+
+// Observe counts the contents of comments, once per comment. Observe a
+// batch before deciding on it, so all the copies of a content are caught,
+// not only the later ones.
+func (e *Engine) Observe(comments []client.Comment) {
+	for _, c := range comments {
+		if e.observed[c.ID] {
+			continue
+		}
+		e.observed[c.ID] = true
+		e.contents[normalize(c.Content)]++
+	}
+}
+
+// Decide returns the decision of the first rule matching a comment.
+func (e *Engine) Decide(c client.Comment) (Decision, bool) {
+	for _, r := range e.rules.Rules {
+		if e.match(r, c) {
+			return Decision{Rule: r.Name, Action: r.Action}, true
+		}
+	}
+	return Decision{}, false
+}
+
+var linkRe = regexp.MustCompile(`(?i)\bhttps?://\S+|\bwww\.\S+`)
+
+func (e *Engine) match(r *Rule, c client.Comment) bool {
+	if r.re != nil && !r.re.MatchString(c.Content) {
+		return false
+	}
+	if len(r.Keywords) > 0 {
+		content := strings.ToLower(c.Content)
+		if !slices.ContainsFunc(r.Keywords, func(k string) bool { return strings.Contains(content, k) }) {
+			return false
+		}
+	}
+	if r.MinLinks > 0 && len(linkRe.FindAllString(c.Content, -1)) < r.MinLinks {
+		return false
+	}
+	if len(r.AuthorIDs) > 0 && !slices.Contains(r.AuthorIDs, c.AuthorID) {
+		return false
+	}
+	if r.MaxAccountAge != "" {
+		if c.Author == nil || c.Author.CreatedAt.IsZero() || e.Now().Sub(c.Author.CreatedAt) >= r.maxAge {
+			return false
+		}
+	}
+	return r.MinDuplicates == 0 || e.contents[normalize(c.Content)] >= r.MinDuplicates
+}
+
+func normalize(content string) string {
+	return strings.Join(strings.Fields(strings.ToLower(content)), " ")
+}
@@ -0,0 +1,118 @@
+package automod
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+const testRules = `
+rules:
+  - name: blocked
+    action: delete
+    author_ids: [66]
+  - name: casino
+    action: spam
+    content: '(?i)casino'
+    min_links: 2
+  - name: keywords
+    action: reject
+    keywords: [Crypto, forex]
+  - name: new and repeating
+    action: spam
+    max_account_age: 7d
+    min_duplicates: 2
+`
+
+func TestDecide(t *testing.T) {
+	rules, err := Parse([]byte(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	e := NewEngine(rules)
+	e.Now = func() time.Time { return now }
+
+	fresh := &client.User{CreatedAt: now.Add(-24 * time.Hour)}
+	old := &client.User{CreatedAt: now.Add(-365 * 24 * time.Hour)}
+	comments := []client.Comment{
+		{ID: 1, AuthorID: 66, Content: "hi"},
+		{ID: 2, Content: "CASINO https://a.example www.b.example"},
+		{ID: 3, Content: "Casino https://a.example"},
+		{ID: 4, Content: "Buy crypto now"},
+		{ID: 5, Author: fresh, Content: "Great  post!"},
+		{ID: 6, Author: fresh, Content: "great post!"},
+		{ID: 7, Author: old, Content: "Great post!"},
+		{ID: 8, Content: "Another one"},
+	}
+	e.Observe(comments)
+	e.Observe(comments)
+
+	want := map[uint64]string{1: "blocked", 2: "casino", 4: "keywords", 5: "new and repeating", 6: "new and repeating"}
+	for _, c := range comments {
+		d, ok := e.Decide(c)
+		if want[c.ID] == "" {
+			if ok {
+				t.Errorf("comment %d matched %q, want no rule", c.ID, d.Rule)
+			}
+			continue
+		}
+		if !ok || d.Rule != want[c.ID] {
+			t.Errorf("comment %d matched %q, want %q", c.ID, d.Rule, want[c.ID])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"action":     "rules:\n  - action: ban\n    keywords: [a]\n",
+		"conditions": "rules:\n  - action: spam\n",
+		"regexp":     "rules:\n  - action: spam\n    content: '('\n",
+		"age":        "rules:\n  - action: spam\n    max_account_age: soon\n",
+		"unknown":    "rules:\n  - action: spam\n    keyword: [a]\n",
+		"empty":      "rules: []\n",
+	}
+	for name, src := range tests {
+		if _, err := Parse([]byte(src)); err == nil {
+			t.Errorf("%s: Parse() succeeded, want an error", name)
+		}
+	}
+}
+
+func TestAuditAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "audit.jsonl")
+	a := OpenAudit(path)
+	if err := a.Append(Entry{CommentID: 1, Decision: Decision{Rule: "r", Action: "spam"}, OK: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Append(Entry{CommentID: 2, Error: "boom"}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, e)
+	}
+	if len(got) != 2 || got[0].Action != "spam" || got[0].Rule != "r" || got[1].Error != "boom" {
+		t.Fatalf("audit log = %+v", got)
+	}
+	if line, _ := os.ReadFile(path); !strings.Contains(string(line), `"action":"spam"`) {
+		t.Fatalf("decision isn't flattened in %s", line)
+	}
+}
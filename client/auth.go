@@ -0,0 +1,183 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// expiryDelta refreshes tokens slightly before they expire, so a request
+// doesn't race the server-side expiry.
+const expiryDelta = 30 * time.Second
+
+// minRefreshInterval stops a 401 that a fresh token doesn't fix, like paid
+// content without access, from refreshing the token again and again.
+const minRefreshInterval = time.Minute
+
+var ErrNoRefreshToken = errors.New("no refresh token, please login again")
+
+// TokenStore persists OAuth tokens. Lock must exclude other CLI processes,
+// so that only one of them spends the refresh token.
+type TokenStore interface {
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+	Lock() (unlock func(), err error)
+}
+
+type RefreshFunc func(refreshToken string) (*oauth2.Token, error)
+
+// AuthTransport authorizes requests with an OAuth token. It refreshes the
+// token when it has expired or the server answers 401, and retries once.
+type AuthTransport struct {
+	Base    http.RoundTripper
+	Store   TokenStore
+	Refresh RefreshFunc
+
+	mu          sync.Mutex
+	token       *oauth2.Token
+	refreshedAt time.Time
+}
+
+func NewAuthTransport(token *oauth2.Token, store TokenStore, refresh RefreshFunc) *AuthTransport {
+	if token == nil {
+		token = &oauth2.Token{}
+	}
+	return &AuthTransport{
+		Store:   store,
+		Refresh: refresh,
+		token:   token,
+	}
+}
+
+// Token returns a copy of the token currently in use.
+func (t *AuthTransport) Token() oauth2.Token {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return *t.token
+}
+
+func (t *AuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.validToken()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base().RoundTrip(authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if !t.canRefresh() || (req.Body != nil && req.GetBody == nil) {
+		// nothing to refresh with, or the body can't be replayed
+		return resp, nil
+	}
+
+	token, err = t.refresh(token)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+	return t.base().RoundTrip(authorize(retry, token))
+}
+
+func (t *AuthTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *AuthTransport) canRefresh() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token.RefreshToken != "" && time.Since(t.refreshedAt) > minRefreshInterval
+}
+
+func (t *AuthTransport) validToken() (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token.RefreshToken != "" && expired(t.token) {
+		return t.refreshLocked()
+	}
+	return t.token, nil
+}
+
+// refresh replaces stale with a new token, unless another goroutine already did.
+func (t *AuthTransport) refresh(stale *oauth2.Token) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token.AccessToken != stale.AccessToken {
+		return t.token, nil
+	}
+	return t.refreshLocked()
+}
+
+func (t *AuthTransport) refreshLocked() (*oauth2.Token, error) {
+	refreshToken := t.token.RefreshToken
+	if t.Store != nil {
+		unlock, err := t.Store.Lock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock token store: %w", err)
+		}
+		defer unlock()
+
+		// another process may have refreshed while we were waiting for the lock
+		stored, err := t.Store.Load()
+		if err == nil && stored != nil && stored.AccessToken != "" {
+			if stored.AccessToken != t.token.AccessToken && !expired(stored) {
+				t.token = stored
+				t.refreshedAt = time.Now()
+				return stored, nil
+			}
+			if stored.RefreshToken != "" {
+				refreshToken = stored.RefreshToken
+			}
+		}
+	}
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	if t.Refresh == nil {
+		return nil, fmt.Errorf("token refresh is not configured")
+	}
+
+	token, err := t.Refresh(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token, please login again: %w", err)
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	if t.Store != nil {
+		if err := t.Store.Save(token); err != nil {
+			return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+		}
+	}
+	t.token = token
+	t.refreshedAt = time.Now()
+	return token, nil
+}
+
+func expired(token *oauth2.Token) bool {
+	return !token.Expiry.IsZero() && time.Now().Add(expiryDelta).After(token.Expiry)
+}
+
+func authorize(req *http.Request, token *oauth2.Token) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return r
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type memTokenStore struct {
+	mu    sync.Mutex
+	lock  sync.Mutex
+	token *oauth2.Token
+	saves int
+}
+
+func (s *memTokenStore) Load() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, nil
+	}
+	token := *s.token
+	return &token, nil
+}
+
+func (s *memTokenStore) Save(token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *token
+	s.token = &saved
+	s.saves++
+	return nil
+}
+
+func (s *memTokenStore) Lock() (func(), error) {
+	s.lock.Lock()
+	return s.lock.Unlock, nil
+}
+
+func newTokenServer(t *testing.T, valid string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAuthTransportRefreshesOnUnauthorized(t *testing.T) {
+	srv := newTokenServer(t, "new")
+	store := &memTokenStore{}
+	refreshes := 0
+	transport := NewAuthTransport(&oauth2.Token{AccessToken: "old", RefreshToken: "r1"}, store, func(refreshToken string) (*oauth2.Token, error) {
+		refreshes++
+		if refreshToken != "r1" {
+			t.Fatalf("refresh token = %q, want r1", refreshToken)
+		}
+		return &oauth2.Token{AccessToken: "new", RefreshToken: "r2"}, nil
+	})
+	cl := NewWithAuth(transport, srv.URL)
+
+	buf, err := cl.sendRequest("POST", srv.URL, map[string]any{"a": 1})
+	if err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
+	}
+	if string(buf) != `{"a":1}` {
+		t.Fatalf("retried body = %q, want original payload", buf)
+	}
+	if refreshes != 1 || store.saves != 1 {
+		t.Fatalf("refreshes = %d, saves = %d; want 1, 1", refreshes, store.saves)
+	}
+	if got := transport.Token(); got.AccessToken != "new" || got.RefreshToken != "r2" {
+		t.Fatalf("Token() = %+v, want refreshed token", got)
+	}
+}
+
+func TestAuthTransportRefreshesExpiredToken(t *testing.T) {
+	srv := newTokenServer(t, "new")
+	refreshes := 0
+	transport := NewAuthTransport(&oauth2.Token{
+		AccessToken:  "old",
+		RefreshToken: "r1",
+		Expiry:       time.Now().Add(-time.Hour),
+	}, &memTokenStore{}, func(refreshToken string) (*oauth2.Token, error) {
+		refreshes++
+		// the server may omit the refresh token when it doesn't rotate it
+		return &oauth2.Token{AccessToken: "new", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+	cl := NewWithAuth(transport, srv.URL)
+
+	if _, err := cl.sendRequest("GET", srv.URL, nil); err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
+	}
+	if refreshes != 1 {
+		t.Fatalf("refreshes = %d, want 1", refreshes)
+	}
+	if got := transport.Token(); got.RefreshToken != "r1" {
+		t.Fatalf("RefreshToken = %q, want the previous refresh token kept", got.RefreshToken)
+	}
+}
+
+func TestAuthTransportUsesTokenRefreshedByAnotherProcess(t *testing.T) {
+	srv := newTokenServer(t, "new")
+	store := &memTokenStore{token: &oauth2.Token{AccessToken: "new", RefreshToken: "r2"}}
+	transport := NewAuthTransport(&oauth2.Token{AccessToken: "old", RefreshToken: "r1"}, store, func(string) (*oauth2.Token, error) {
+		t.Fatal("refresh token must not be spent twice")
+		return nil, nil
+	})
+	cl := NewWithAuth(transport, srv.URL)
+
+	if _, err := cl.sendRequest("GET", srv.URL, nil); err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
+	}
+	if store.saves != 0 {
+		t.Fatalf("saves = %d, want 0", store.saves)
+	}
+}
+
+func TestAuthTransportDoesNotRefreshTwiceForSameUnauthorized(t *testing.T) {
+	srv := newTokenServer(t, "nobody")
+	refreshes := 0
+	transport := NewAuthTransport(&oauth2.Token{AccessToken: "old", RefreshToken: "r1"}, &memTokenStore{}, func(string) (*oauth2.Token, error) {
+		refreshes++
+		return &oauth2.Token{AccessToken: "new", RefreshToken: "r2"}, nil
+	})
+	cl := NewWithAuth(transport, srv.URL)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cl.sendRequest("GET", srv.URL, nil); err == nil {
+			t.Fatal("sendRequest() error = nil, want 401")
+		}
+	}
+	if refreshes != 1 {
+		t.Fatalf("refreshes = %d, want 1", refreshes)
+	}
+}
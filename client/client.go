@@ -5,16 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"text/tabwriter"
 	"time"
 )
 
 type Client struct {
 	AccessToken string
 	APIBase     string
+	HTTPClient  *http.Client
 }
 
 type CreateOrUpdateListPostPayload struct {
@@ -36,6 +34,24 @@ func New(accessToken, apiBase string) *Client {
 	}
 }
 
+// NewWithAuth creates a client whose requests are authorized, and refreshed
+// when needed, by the given transport.
+func NewWithAuth(transport *AuthTransport, apiBase string) *Client {
+	return &Client{
+		APIBase:    apiBase,
+		HTTPClient: &http.Client{Transport: transport},
+	}
+}
+
+// Auth returns the OAuth transport of the client, or nil for static tokens.
+func (c *Client) Auth() *AuthTransport {
+	if c.HTTPClient == nil {
+		return nil
+	}
+	transport, _ := c.HTTPClient.Transport.(*AuthTransport)
+	return transport
+}
+
 func (c *Client) GetList(listID uint64) (*ListResponse, error) {
 	url := fmt.Sprintf("%s/lists/%d", c.APIBase, listID)
 	resp, err := c.sendRequest("GET", url, nil)
@@ -78,9 +94,12 @@ func (c *Client) sendRequest(method, url string, payload any) ([]byte, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	client := &http.Client{}
+	client := c.HTTPClient
+	if client == nil {
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+		client = &http.Client{}
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -99,43 +118,3 @@ func (c *Client) sendRequest(method, url string, payload any) ([]byte, error) {
 
 	return buf, nil
 }
-
-func PrettyPrintJSON(data any) {
-	prettyJSON, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		log.Fatalf("Error generating pretty JSON: %v", err)
-	}
-	fmt.Println(string(prettyJSON))
-}
-
-func PrettyPrintUser(data *UserResponse) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "User:")
-	fmt.Fprintf(w, "ID:\t%d\n", data.Data.ID)
-	fmt.Fprintf(w, "Name:\t%s\n", data.Data.Name)
-	fmt.Fprintf(w, "Email:\t%s\n", data.Data.Email)
-	fmt.Fprintf(w, "Avatar Image URL:\t%s\n", data.Data.AvatarImageURL)
-	fmt.Fprintf(w, "Bio:\t%s\n", data.Data.Bio)
-	fmt.Fprintf(w, "Tagline:\t%s\n", data.Data.Tagline)
-	fmt.Fprintf(w, "Created At:\t%s\n", data.Data.CreatedAt)
-	fmt.Fprintf(w, "Social IDs:\n")
-	for _, socialID := range data.Data.SocialIDs {
-		fmt.Fprintf(w, "\t%s: %s\n", socialID.Name, socialID.Value)
-	}
-	w.Flush()
-}
-
-func PrettyPrintPost(data *PostResponse) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "Post:")
-	fmt.Fprintf(w, "ID:\t%d\n", data.Data.ID)
-	fmt.Fprintf(w, "Slug:\t%s\n", data.Data.Slug)
-	fmt.Fprintf(w, "Cover Image URL:\t%s\n", data.Data.CoverImageURL)
-	fmt.Fprintf(w, "Title:\t%s\n", data.Data.Title)
-	fmt.Fprintf(w, "Summary:\t%s\n", data.Data.Summary)
-	fmt.Fprintf(w, "PublishedAt:\t%s\n", data.Data.PublishedAt)
-	fmt.Fprintf(w, "First Published At:\t%s\n", data.Data.FirstPublishedAt)
-	fmt.Fprintf(w, "Tags:\t%s\n", data.Data.Tags)
-	fmt.Fprintf(w, "Theme:\t%s\n", data.Data.Theme)
-	w.Flush()
-}
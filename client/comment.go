@@ -3,8 +3,46 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
+// Comment statuses. Rejecting a comment puts it back to pending.
+const (
+	CommentStatusPending  = 0
+	CommentStatusApproved = 1
+	CommentStatusSpam     = 2
+)
+
+var commentStatuses = map[string]int{
+	"pending":  CommentStatusPending,
+	"approved": CommentStatusApproved,
+	"spam":     CommentStatusSpam,
+}
+
+// CommentStatusName returns the name of a status, or its number for
+// statuses without a name.
+func CommentStatusName(status int) string {
+	for name, s := range commentStatuses {
+		if s == status {
+			return name
+		}
+	}
+	return strconv.Itoa(status)
+}
+
+// ParseCommentStatus parses a status name, like pending, or number.
+func ParseCommentStatus(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if status, ok := commentStatuses[s]; ok {
+		return status, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	return 0, fmt.Errorf("invalid comment status %q, use pending, approved or spam", s)
+}
+
 func (c *Client) GetCommentsByPost(postID uint64, offset, limit int) (*CommentsResponse, error) {
 	resp, err := c.sendRequest("GET", fmt.Sprintf("%s/comments?post_id=%d&offset=%d&limit=%d", c.APIBase, postID, offset, limit), nil)
 	if err != nil {
@@ -30,10 +68,20 @@ func (c *Client) GetCommentsByList(listIDOrSlug string, offset, limit int) (*Com
 }
 
 func (c *Client) CreateComment(postID uint64, content string) (*CommentResponse, error) {
-	resp, err := c.sendRequest("POST", fmt.Sprintf("%s/comments", c.APIBase), map[string]any{
+	return c.ReplyComment(postID, 0, content)
+}
+
+// ReplyComment creates a comment on a post that quotes the comment it
+// replies to. A quoteCommentID of 0 makes a top level comment.
+func (c *Client) ReplyComment(postID, quoteCommentID uint64, content string) (*CommentResponse, error) {
+	payload := map[string]any{
 		"post_id": postID,
 		"content": content,
-	})
+	}
+	if quoteCommentID != 0 {
+		payload["quote_comment_id"] = quoteCommentID
+	}
+	resp, err := c.sendRequest("POST", fmt.Sprintf("%s/comments", c.APIBase), payload)
 	if err != nil {
 		return nil, err
 	}
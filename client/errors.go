@@ -0,0 +1,19 @@
+package client
+
+import (
+	"errors"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// IsNetworkError reports whether err means the API couldn't be reached, as
+// opposed to the API answering with an error.
+func IsNetworkError(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
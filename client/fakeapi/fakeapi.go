@@ -0,0 +1,286 @@
+// Package fakeapi is a fake of the Quaily API for tests and benchmarks. It
+// serves the user, lists, comments and subscribers it is given, newest
+// comments first like the API, keeps the settings lists are created or
+// updated with, and can add latency and fail lists.
+package fakeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+type Server struct {
+	*httptest.Server
+	// Latency delays every response.
+	Latency time.Duration
+
+	requests atomic.Int64
+
+	mu          sync.Mutex
+	user        client.UserProfile
+	lists       []client.List
+	settings    map[uint64]map[string]any
+	comments    map[uint64][]client.Comment
+	subscribers map[uint64][]client.Subscription
+	failing     map[uint64]bool
+}
+
+// New starts a server for the user with the given ID. Close it when done.
+func New(userID uint64) *Server {
+	s := &Server{
+		comments:    map[uint64][]client.Comment{},
+		subscribers: map[uint64][]client.Subscription{},
+		settings:    map[uint64]map[string]any{},
+		failing:     map[uint64]bool{},
+	}
+	s.user.ID = userID
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/me", s.handleMe)
+	mux.HandleFunc("GET /users/{id}/lists", s.handleLists)
+	mux.HandleFunc("GET /lists/{list}", s.handleList)
+	mux.HandleFunc("POST /lists", s.handleCreateList)
+	mux.HandleFunc("PUT /lists/{list}", s.handleUpdateList)
+	mux.HandleFunc("GET /lists/{list}/comments", s.handleComments)
+	mux.HandleFunc("GET /lists/{list}/subscribers", s.handleSubscribers)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.Latency > 0 {
+			time.Sleep(s.Latency)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// Client returns a client of the server.
+func (s *Server) Client() *client.Client {
+	return client.New("test-token", s.URL)
+}
+
+// Requests returns how many requests the server got.
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+// ResetRequests sets the count of requests back to 0.
+func (s *Server) ResetRequests() {
+	s.requests.Store(0)
+}
+
+func (s *Server) AddList(l client.List) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists = append(s.lists, l)
+}
+
+// AddComments adds comments to a list. The list of each comment is set.
+func (s *Server) AddComments(listID uint64, comments ...client.Comment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range comments {
+		c.ListID = listID
+		s.comments[listID] = append(s.comments[listID], c)
+	}
+	all := s.comments[listID]
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.After(all[j].CreatedAt) })
+}
+
+// AddSubscribers adds subscriptions to a list, served in the order given.
+// The list of each subscription is set.
+func (s *Server) AddSubscribers(listID uint64, subs ...client.Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range subs {
+		sub.ListID = listID
+		s.subscribers[listID] = append(s.subscribers[listID], sub)
+	}
+}
+
+// FailList makes the comments and subscribers of a list fail with a server error.
+func (s *Server) FailList(listID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing[listID] = true
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, client.UserResponse{Data: s.user})
+}
+
+func (s *Server) handleLists(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.PathValue("id") != strconv.FormatUint(s.user.ID, 10) {
+		writeJSON(w, client.ListsResponse{Data: []client.List{}})
+		return
+	}
+	writeJSON(w, client.ListsResponse{Data: s.lists})
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	listID, ok := s.findList(r.PathValue("list"))
+	if !ok {
+		http.Error(w, `{"error":"list not found"}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]any{"data": s.listSettings(listID)})
+}
+
+func (s *Server) handleCreateList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, `{"error":"invalid payload"}`, http.StatusBadRequest)
+		return
+	}
+	listID := uint64(len(s.lists) + 1)
+	for _, l := range s.lists {
+		listID = max(listID, l.ID+1)
+	}
+	s.lists = append(s.lists, client.List{ID: listID})
+	s.updateList(listID, payload)
+	writeJSON(w, map[string]any{"data": s.listSettings(listID)})
+}
+
+func (s *Server) handleUpdateList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	listID, ok := s.findList(r.PathValue("list"))
+	if !ok {
+		http.Error(w, `{"error":"list not found"}`, http.StatusNotFound)
+		return
+	}
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, `{"error":"invalid payload"}`, http.StatusBadRequest)
+		return
+	}
+	s.updateList(listID, payload)
+	writeJSON(w, map[string]any{"data": s.listSettings(listID)})
+}
+
+// updateList sets the fields of the list in the payload, and keeps the
+// other settings.
+func (s *Server) updateList(listID uint64, payload map[string]any) {
+	for i := range s.lists {
+		if s.lists[i].ID != listID {
+			continue
+		}
+		l := &s.lists[i]
+		for k, v := range payload {
+			text, _ := v.(string)
+			switch k {
+			case "id":
+			case "slug":
+				l.Slug = text
+			case "title":
+				l.Title = text
+			case "description":
+				l.Description = text
+			case "tagline":
+				l.Tagline = text
+			default:
+				if s.settings[listID] == nil {
+					s.settings[listID] = map[string]any{}
+				}
+				s.settings[listID][k] = v
+			}
+		}
+	}
+}
+
+// listSettings returns the list with the settings it has no field for.
+func (s *Server) listSettings(listID uint64) map[string]any {
+	ret := map[string]any{}
+	for k, v := range s.settings[listID] {
+		ret[k] = v
+	}
+	for _, l := range s.lists {
+		if l.ID == listID {
+			ret["id"], ret["slug"], ret["title"] = l.ID, l.Slug, l.Title
+			ret["description"], ret["tagline"] = l.Description, l.Tagline
+		}
+	}
+	return ret
+}
+
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	listID, ok := s.findList(r.PathValue("list"))
+	if !ok {
+		http.Error(w, `{"error":"list not found"}`, http.StatusNotFound)
+		return
+	}
+	if s.failing[listID] {
+		http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	all := s.comments[listID]
+	start := min(max(offset, 0), len(all))
+	end := min(start+max(limit, 0), len(all))
+
+	resp := client.CommentsResponse{}
+	resp.Data.Items = append([]client.Comment{}, all[start:end]...)
+	resp.Data.Pagination.Offset = uint64(start)
+	resp.Data.Pagination.Limit = uint64(limit)
+	resp.Data.Pagination.NextOffset = uint64(end)
+	resp.Data.Pagination.Total = uint64(len(all))
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleSubscribers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	listID, ok := s.findList(r.PathValue("list"))
+	if !ok {
+		http.Error(w, `{"error":"list not found"}`, http.StatusNotFound)
+		return
+	}
+	if s.failing[listID] {
+		http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	all := s.subscribers[listID]
+	start := min(max(offset, 0), len(all))
+	end := min(start+max(limit, 0), len(all))
+
+	resp := client.SubscribersResponse{}
+	resp.Data.Items = append([]client.Subscription{}, all[start:end]...)
+	resp.Data.Pagination.Offset = uint64(start)
+	resp.Data.Pagination.Limit = uint64(limit)
+	resp.Data.Pagination.NextOffset = uint64(end)
+	resp.Data.Pagination.Total = uint64(len(all))
+	writeJSON(w, resp)
+}
+
+func (s *Server) findList(idOrSlug string) (uint64, bool) {
+	for _, l := range s.lists {
+		if strconv.FormatUint(l.ID, 10) == idOrSlug || l.Slug == idOrSlug {
+			return l.ID, true
+		}
+	}
+	return 0, false
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -1,8 +1,13 @@
 package client
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"text/tabwriter"
 )
 
 func (c *Client) GetUserLists(userID uint64) ([]List, error) {
@@ -16,3 +21,81 @@ func (c *Client) GetUserLists(userID uint64) ([]List, error) {
 	}
 	return ltsp.Data, nil
 }
+
+// ListSettings is a list with all of its settings as the API returns them,
+// including those List has no field for.
+type ListSettings map[string]any
+
+// listFields are the settings printed first, in this order.
+var listFields = []string{"id", "slug", "title", "tagline", "description"}
+
+// PrintTable prints a setting per line, those of List first and the others
+// by name. Values that aren't text are printed as JSON.
+func (s ListSettings) PrintTable(w io.Writer) error {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		if !slices.Contains(listFields, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for i := len(listFields) - 1; i >= 0; i-- {
+		if _, ok := s[listFields[i]]; ok {
+			keys = append([]string{listFields[i]}, keys...)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 1, 1, 1, ' ', 0)
+	for _, k := range keys {
+		value, ok := s[k].(string)
+		if !ok {
+			buf, err := json.Marshal(s[k])
+			if err != nil {
+				return err
+			}
+			value = string(buf)
+		}
+		fmt.Fprintf(tw, "%s:\t%s\n", k, value)
+	}
+	return tw.Flush()
+}
+
+func decodeListSettings(resp []byte) (*ListSettingsResponse, error) {
+	// numbers are kept as they are, IDs don't fit in a float64
+	dec := json.NewDecoder(bytes.NewReader(resp))
+	dec.UseNumber()
+	lr := &ListSettingsResponse{}
+	if err := dec.Decode(lr); err != nil {
+		return nil, err
+	}
+	return lr, nil
+}
+
+// GetListSettings gets a list by its ID or slug, with all of its settings.
+func (c *Client) GetListSettings(listIDOrSlug string) (*ListSettingsResponse, error) {
+	resp, err := c.sendRequest("GET", fmt.Sprintf("%s/lists/%s", c.APIBase, listIDOrSlug), nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeListSettings(resp)
+}
+
+// CreateList creates a list with the settings of the payload, like slug,
+// title, description and tagline.
+func (c *Client) CreateList(payload map[string]any) (*ListSettingsResponse, error) {
+	resp, err := c.sendRequest("POST", fmt.Sprintf("%s/lists", c.APIBase), payload)
+	if err != nil {
+		return nil, err
+	}
+	return decodeListSettings(resp)
+}
+
+// UpdateList changes the settings of a list in the payload, leaving the
+// others as they are.
+func (c *Client) UpdateList(listIDOrSlug string, payload map[string]any) (*ListSettingsResponse, error) {
+	resp, err := c.sendRequest("PUT", fmt.Sprintf("%s/lists/%s", c.APIBase, listIDOrSlug), payload)
+	if err != nil {
+		return nil, err
+	}
+	return decodeListSettings(resp)
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListRequests(t *testing.T) {
+	var method, path string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, body = r.Method, r.URL.Path, nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{"id":3,"slug":"gophers","title":"Gophers","tagline":"Go news","theme":"dark"}}`))
+	}))
+	defer srv.Close()
+	cl := New("token", srv.URL)
+
+	resp, err := cl.GetListSettings("gophers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != "GET" || path != "/lists/gophers" || resp.Data["id"] != json.Number("3") || resp.Data["tagline"] != "Go news" || resp.Data["theme"] != "dark" {
+		t.Fatalf("get: %s %s, %v", method, path, resp.Data)
+	}
+
+	if _, err := cl.CreateList(map[string]any{"slug": "gophers", "title": "Gophers"}); err != nil {
+		t.Fatal(err)
+	}
+	if method != "POST" || path != "/lists" || body["slug"] != "gophers" || body["title"] != "Gophers" {
+		t.Fatalf("create: %s %s %v", method, path, body)
+	}
+
+	if _, err := cl.UpdateList("3", map[string]any{"tagline": "Go news"}); err != nil {
+		t.Fatal(err)
+	}
+	if method != "PUT" || path != "/lists/3" || len(body) != 1 || body["tagline"] != "Go news" {
+		t.Fatalf("update: %s %s %v", method, path, body)
+	}
+}
+
+func TestListSettingsPrintTable(t *testing.T) {
+	settings := ListSettings{"theme": "dark", "slug": "gophers", "id": json.Number("3"), "paid": true}
+	var buf bytes.Buffer
+	if err := settings.PrintTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "id:    3\nslug:  gophers\npaid:  true\ntheme: dark\n"
+	if buf.String() != want {
+		t.Fatalf("PrintTable() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
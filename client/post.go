@@ -4,8 +4,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+
+	"github.com/quailyquaily/quail-cli/quailyurl"
 )
 
+// URL is the address of the post on quaily.com. The list ID and post ID
+// stand in for slugs the API left out.
+func (p Post) URL() string {
+	list, slug := p.List.Slug, p.Slug
+	if list == "" {
+		list = strconv.FormatUint(p.ListID, 10)
+	}
+	if slug == "" {
+		slug = strconv.FormatUint(p.ID, 10)
+	}
+	return quailyurl.PostURL(list, slug)
+}
+
 func (c *Client) GetPost(listIDOrSlug string, postIDOrSlug string) (*PostResponse, error) {
 	resp, err := c.sendRequest("GET", fmt.Sprintf("%s/lists/%s/posts/%s", c.APIBase, listIDOrSlug, postIDOrSlug), nil)
 	if err != nil {
@@ -4,22 +4,23 @@ import "time"
 
 type (
 	UserResponse struct {
-		Data struct {
-			ID             uint64         `json:"id"`
-			Name           string         `json:"name"`
-			Email          string         `json:"email"`
-			AvatarImageURL string         `json:"avatar_image_url"`
-			Bio            string         `json:"bio"`
-			Tagline        string         `json:"tagline"`
-			CreatedAt      string         `json:"created_at"`
-			SocialIDs      []UserSocialID `json:"social_ids"`
-			Status         int            `json:"status"`
-			UserOptions    struct {
-				EditorLayout         string `json:"editor_layout"`
-				KindLineBreakEnabled bool   `json:"kind_line_break_enabled"`
-				Languages            string `json:"languages"`
-			}
-		} `json:"data"`
+		Data UserProfile `json:"data"`
+	}
+	UserProfile struct {
+		ID             uint64         `json:"id"`
+		Name           string         `json:"name"`
+		Email          string         `json:"email"`
+		AvatarImageURL string         `json:"avatar_image_url"`
+		Bio            string         `json:"bio"`
+		Tagline        string         `json:"tagline"`
+		CreatedAt      string         `json:"created_at"`
+		SocialIDs      []UserSocialID `json:"social_ids"`
+		Status         int            `json:"status"`
+		UserOptions    struct {
+			EditorLayout         string `json:"editor_layout"`
+			KindLineBreakEnabled bool   `json:"kind_line_break_enabled"`
+			Languages            string `json:"languages"`
+		}
 	}
 	UserSocialID struct {
 		Name  string `json:"name"`
@@ -29,13 +30,14 @@ type (
 
 type (
 	User struct {
-		ID             uint64 `json:"id"`
-		Name           string `json:"name"`
-		Email          string `json:"email"`
-		AvatarImageURL string `json:"avatar_image_url"`
-		Bio            string `json:"bio"`
-		Tagline        string `json:"tagline"`
-		Status         int    `json:"status"`
+		ID             uint64    `json:"id"`
+		Name           string    `json:"name"`
+		Email          string    `json:"email"`
+		AvatarImageURL string    `json:"avatar_image_url"`
+		Bio            string    `json:"bio"`
+		Tagline        string    `json:"tagline"`
+		Status         int       `json:"status"`
+		CreatedAt      time.Time `json:"created_at"`
 	}
 
 	Post struct {
@@ -68,11 +70,13 @@ type (
 		Data Post `json:"data"`
 	}
 
+	PostContent struct {
+		FreeContent string `json:"free-content"`
+		PaidContent string `json:"paid-content"`
+	}
+
 	PostContentResponse struct {
-		Data struct {
-			FreeContent string `json:"free-content"`
-			PaidContent string `json:"paid-content"`
-		} `json:"data"`
+		Data PostContent `json:"data"`
 	}
 
 	ListsResponse struct {
@@ -83,6 +87,10 @@ type (
 		Data List `json:"data"`
 	}
 
+	ListSettingsResponse struct {
+		Data ListSettings `json:"data"`
+	}
+
 	GenerateMetadataResponse struct {
 		Data struct {
 			Slug    string `json:"slug"`
@@ -126,6 +134,21 @@ type (
 		Data []Subscription `json:"data"`
 	}
 
+	SubscriberPaginationResponse struct {
+		Pagination struct {
+			Current    uint64 `json:"current"`
+			Offset     uint64 `json:"offset"`
+			Limit      uint64 `json:"limit"`
+			NextOffset uint64 `json:"next_offset"`
+			Total      uint64 `json:"total"`
+		} `json:"pagination"`
+		Items []Subscription `json:"items"`
+	}
+
+	SubscribersResponse struct {
+		Data SubscriberPaginationResponse `json:"data"`
+	}
+
 	Comment struct {
 		ID              uint64     `json:"id"`
 		QuoteCommentID  uint64     `json:"quote_comment_id"`
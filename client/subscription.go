@@ -28,3 +28,17 @@ func (c *Client) GetSubscribedPosts(offset, limit int) (*SearchResponse, error)
 	}
 	return sr, nil
 }
+
+// GetListSubscribers gets the subscriptions to a list, for its owner. The
+// user of each subscription is set.
+func (c *Client) GetListSubscribers(listIDOrSlug string, offset, limit int) (*SubscribersResponse, error) {
+	resp, err := c.sendRequest("GET", fmt.Sprintf("%s/lists/%s/subscribers?offset=%d&limit=%d", c.APIBase, listIDOrSlug, offset, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+	sr := &SubscribersResponse{}
+	if err := json.Unmarshal(resp, sr); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
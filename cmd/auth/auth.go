@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/oauth"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/util"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Inspect authentication",
+	}
+
+	cmd.AddCommand(newStatusCmd())
+
+	return cmd
+}
+
+type statusUser struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+type status struct {
+	Source     string      `json:"source"`
+	ConfigFile string      `json:"config_file"`
+	Credential string      `json:"credential"`
+	Expiry     *time.Time  `json:"expiry,omitempty"`
+	Scopes     []string    `json:"scopes"`
+	User       *statusUser `json:"user,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+func (s status) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 1, 1, 1, ' ', 0)
+	fmt.Fprintf(w, "Source:\t%s\n", sourceDescription(s.Source))
+	fmt.Fprintf(w, "Config File:\t%s\n", s.ConfigFile)
+	if s.Source == util.CredentialSourceNone {
+		fmt.Fprintf(w, "Status:\tnot logged in, run quail-cli login\n")
+		return w.Flush()
+	}
+	fmt.Fprintf(w, "Credential:\t%s\n", s.Credential)
+	fmt.Fprintf(w, "Expiry:\t%s\n", formatExpiry(s.Expiry))
+	fmt.Fprintf(w, "Scopes:\t%s\n", formatScopes(s.Source, s.Scopes))
+	if s.Error != "" {
+		fmt.Fprintf(w, "User:\terror: %s\n", s.Error)
+	} else if s.User != nil {
+		fmt.Fprintf(w, "User:\t%s (%d)\n", s.User.Name, s.User.ID)
+	}
+	return w.Flush()
+}
+
+func newStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the active credential and who it belongs to",
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			credential := cmd.Context().Value(common.CTX_CREDENTIAL{}).(util.Credential)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			st := status{
+				Source:     credential.Source,
+				ConfigFile: util.ResolveConfigFile(),
+				Credential: util.MaskSecret(credential.Token),
+				Scopes:     credential.Scopes,
+			}
+
+			if cl != nil {
+				me, err := cl.GetMe()
+				if err != nil {
+					st.Error = err.Error()
+				} else {
+					st.User = &statusUser{ID: me.Data.ID, Name: me.Data.Name}
+				}
+				// the request may have refreshed the token
+				if transport := cl.Auth(); transport != nil {
+					token := transport.Token()
+					credential.Expiry = token.Expiry
+					st.Credential = util.MaskSecret(token.AccessToken)
+					if scopes := oauth.Scopes(&token); len(scopes) > 0 {
+						st.Scopes = scopes
+					}
+				}
+			}
+			if !credential.Expiry.IsZero() {
+				st.Expiry = &credential.Expiry
+			}
+
+			if err := out.Render(st); err != nil {
+				slog.Error("failed to render output", "error", err)
+			}
+		},
+	}
+}
+
+func sourceDescription(source string) string {
+	switch source {
+	case util.CredentialSourceEnv:
+		return "QUAIL_API_KEY environment variable"
+	case util.CredentialSourceAPIKey:
+		return "api_key in config"
+	case util.CredentialSourceOAuth:
+		return "OAuth token in config"
+	}
+	return "none"
+}
+
+func formatExpiry(expiry *time.Time) string {
+	if expiry == nil {
+		return "never"
+	}
+	if time.Now().After(*expiry) {
+		return fmt.Sprintf("%s (expired)", expiry.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s (in %s)", expiry.Format(time.RFC3339), time.Until(*expiry).Round(time.Minute))
+}
+
+func formatScopes(source string, scopes []string) string {
+	if source != util.CredentialSourceOAuth {
+		return "all scopes of the API key"
+	}
+	if len(scopes) == 0 {
+		return "unknown"
+	}
+	return strings.Join(scopes, " ")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/output"
+)
+
+// default columns of the API result types in table and CSV output
+func init() {
+	output.Register[client.UserProfile](
+		output.Col("id", func(u client.UserProfile) any { return u.ID }),
+		output.Col("name", func(u client.UserProfile) any { return u.Name }),
+		output.Col("email", func(u client.UserProfile) any { return u.Email }),
+		output.Col("avatar_image_url", func(u client.UserProfile) any { return u.AvatarImageURL }).AsDetail(),
+		output.Col("bio", func(u client.UserProfile) any { return u.Bio }).AsDetail(),
+		output.Col("tagline", func(u client.UserProfile) any { return u.Tagline }).AsDetail(),
+		output.Col("created_at", func(u client.UserProfile) any { return u.CreatedAt }).AsDetail(),
+		output.Col("social_ids", func(u client.UserProfile) any {
+			ids := make([]string, len(u.SocialIDs))
+			for i, id := range u.SocialIDs {
+				ids[i] = fmt.Sprintf("%s: %s", id.Name, id.Value)
+			}
+			return ids
+		}).AsDetail(),
+	)
+
+	output.Register[client.User](
+		output.Col("id", func(u client.User) any { return u.ID }),
+		output.Col("name", func(u client.User) any { return u.Name }),
+		output.Col("email", func(u client.User) any { return u.Email }).AsDetail(),
+		output.Col("bio", func(u client.User) any { return u.Bio }).AsDetail(),
+	)
+
+	output.Register[client.List](
+		output.Col("id", func(l client.List) any { return l.ID }),
+		output.Col("slug", func(l client.List) any { return l.Slug }),
+		output.Col("title", func(l client.List) any { return l.Title }),
+		output.Col("tagline", func(l client.List) any { return l.Tagline }).AsDetail(),
+		output.Col("description", func(l client.List) any { return l.Description }).AsDetail(),
+	)
+
+	output.Register[client.Post](
+		output.Col("id", func(p client.Post) any { return p.ID }),
+		output.Col("list", func(p client.Post) any {
+			if p.List.Slug != "" {
+				return p.List.Slug
+			}
+			return strconv.FormatUint(p.ListID, 10)
+		}),
+		output.Col("slug", func(p client.Post) any { return p.Slug }),
+		output.Col("title", func(p client.Post) any { return p.Title }),
+		output.Col("published_at", func(p client.Post) any { return p.PublishedAt }),
+		output.Col("paid", func(p client.Post) any { return p.IsPaidContent }),
+		output.Col("cover_image_url", func(p client.Post) any { return p.CoverImageURL }).AsDetail(),
+		output.Col("summary", func(p client.Post) any { return p.Summary }).AsDetail(),
+		output.Col("first_published_at", func(p client.Post) any { return p.FirstPublishedAt }).AsDetail(),
+		output.Col("tags", func(p client.Post) any { return p.Tags }).AsDetail(),
+		output.Col("theme", func(p client.Post) any { return p.Theme }).AsDetail(),
+	)
+
+	output.Register[client.Subscription](
+		output.Col("id", func(s client.Subscription) any { return s.ID }),
+		output.Col("list", func(s client.Subscription) any {
+			if s.List != nil {
+				return s.List.Title
+			}
+			return ""
+		}),
+		output.Col("slug", func(s client.Subscription) any {
+			if s.List != nil {
+				return s.List.Slug
+			}
+			return strconv.FormatUint(s.ListID, 10)
+		}),
+		output.Col("type", func(s client.Subscription) any { return s.Type }),
+		output.Col("email", func(s client.Subscription) any { return s.EmailEnabled }),
+		output.Col("paid_expiry", func(s client.Subscription) any { return s.PaidExpiry }),
+	)
+
+	output.Register[client.Comment](
+		output.Col("id", func(c client.Comment) any { return c.ID }),
+		output.Col("list", func(c client.Comment) any { return c.ListID }),
+		output.Col("post", func(c client.Comment) any { return c.PostID }),
+		output.Col("author", func(c client.Comment) any {
+			if c.Author != nil && c.Author.Name != "" {
+				return c.Author.Name
+			}
+			return strconv.FormatUint(c.AuthorID, 10)
+		}),
+		output.Col("status", func(c client.Comment) any { return c.Status }),
+		output.Col("created_at", func(c client.Comment) any { return c.CreatedAt }),
+		output.Col("content", func(c client.Comment) any { return strings.TrimSpace(c.Content) }),
+	)
+}
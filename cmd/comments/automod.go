@@ -0,0 +1,264 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/automod"
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/commentwatch"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/spf13/cobra"
+)
+
+// auditExcerpt is how much of a comment the audit log keeps.
+const auditExcerpt = 200
+
+func init() {
+	output.Register[automod.Entry](
+		output.Col("comment_id", func(e automod.Entry) any { return e.CommentID }),
+		output.Col("list", func(e automod.Entry) any { return e.ListID }),
+		output.Col("post", func(e automod.Entry) any { return e.PostID }),
+		output.Col("author_id", func(e automod.Entry) any { return e.AuthorID }),
+		output.Col("rule", func(e automod.Entry) any { return e.Rule }),
+		output.Col("action", func(e automod.Entry) any { return e.Action }),
+		output.Col("ok", func(e automod.Entry) any { return e.OK }),
+		output.Col("error", func(e automod.Entry) any { return e.Error }),
+		output.Col("dry_run", func(e automod.Entry) any { return e.DryRun }),
+		output.Col("content", func(e automod.Entry) any { return e.Content }).AsDetail(),
+	)
+}
+
+// automoder applies rules to batches of comments.
+type automoder struct {
+	cl          *client.Client
+	engine      *automod.Engine
+	audit       *automod.Audit
+	listID      uint64
+	dryRun      bool
+	concurrency int
+}
+
+func newAutoModerateCmd() *cobra.Command {
+	var rulesPath, auditPath string
+	var list, since string
+	var limit, concurrency int
+	var watch, dryRun bool
+	var interval, maxInterval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "auto-moderate",
+		Short: "Approve, reject, spam or delete comments by local rules",
+		Long: `Apply the rules of a rules file to comments, on --list or on all your lists.
+Without --watch, the comments of the --since window are checked once. With
+--watch, new comments are checked as they arrive, until interrupted.
+
+A rule matches when all its conditions do, and the first matching rule decides.
+Comments already in the status an action would give are left alone. Every
+decision applied is appended to the audit log. --dry-run reports decisions
+without applying or logging them.
+
+rules:
+  - name: casino spam
+    action: spam            # approve, reject, spam or delete
+    content: '(?i)casino'   # regular expression
+    keywords: [jackpot]     # any of them, ignoring case
+    min_links: 2
+    author_ids: [123]
+    max_account_age: 7d     # authors younger than this
+    min_duplicates: 3       # same content in this many comments`,
+		Run: func(cmd *cobra.Command, args []string) {
+			rules, err := automod.Load(rulesPath)
+			if err != nil {
+				slog.Error("failed to load rules", "error", err)
+				return
+			}
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := *cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			m := &automoder{
+				cl:          cl,
+				engine:      automod.NewEngine(rules),
+				audit:       automod.OpenAudit(auditPath),
+				dryRun:      dryRun,
+				concurrency: concurrency,
+			}
+
+			if !watch {
+				start, err := config.ParseSince(since)
+				if err != nil {
+					slog.Error("invalid --since", "error", err)
+					return
+				}
+				comments, err := matchComments(cl, list, commentFilter{since: start}, limit)
+				if err != nil {
+					slog.Error("failed to get comments", "error", err)
+					return
+				}
+				render(&out, decisions(m.run(comments)))
+				return
+			}
+
+			if list != "" {
+				if m.listID, err = resolveListID(cl, list); err != nil {
+					slog.Error("failed to find list", "error", err)
+					return
+				}
+			}
+			if out.Format.Kind == output.JSON {
+				out.Format.Kind = output.NDJSON
+			}
+			w := commentwatch.New(cl)
+			w.Interval, w.MaxInterval = interval, maxInterval
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			slog.Info("auto-moderating new comments, press Ctrl-C to stop", "interval", interval, "dry_run", dryRun)
+			err = w.Run(ctx, func(comments []client.Comment) {
+				if entries := m.run(comments); len(entries) > 0 {
+					render(&out, decisions(entries))
+				}
+			}, func(err error) {
+				slog.Warn("failed to poll comments", "error", err)
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("failed to watch comments", "error", err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&rulesPath, "rules", automod.DefaultPath(), "Rules file")
+	cmd.Flags().StringVar(&auditPath, "audit-log", automod.DefaultAuditPath(), "File the decisions applied are appended to")
+	cmd.Flags().StringVar(&list, "list", "", "List id or slug, all your lists by default")
+	cmd.Flags().StringVar(&since, "since", "7d", "Check comments from this age, like 2d, or date, without --watch")
+	cmd.Flags().IntVar(&limit, "limit", 200, "Maximum number of comments checked, without --watch")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of comments moderated at once")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Check new comments as they arrive")
+	cmd.Flags().DurationVar(&interval, "interval", commentwatch.DefaultInterval, "Time between polls with --watch")
+	cmd.Flags().DurationVar(&maxInterval, "max-interval", commentwatch.DefaultMaxInterval, "Longest time between polls while the API can't be reached")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report decisions without applying them")
+	return cmd
+}
+
+// run decides on comments, applies the decisions unless in a dry run, and
+// logs them.
+func (m *automoder) run(comments []client.Comment) []automod.Entry {
+	if m.listID != 0 {
+		var kept []client.Comment
+		for _, c := range comments {
+			if c.ListID == m.listID {
+				kept = append(kept, c)
+			}
+		}
+		comments = kept
+	}
+	m.engine.Observe(comments)
+
+	now := time.Now().UTC()
+	var entries []automod.Entry
+	var ops []operationResult
+	for _, c := range comments {
+		d, ok := m.engine.Decide(c)
+		if !ok || settled(c, d.Action) {
+			continue
+		}
+		entries = append(entries, automod.Entry{
+			Time:      now,
+			CommentID: c.ID,
+			ListID:    c.ListID,
+			PostID:    c.PostID,
+			AuthorID:  c.AuthorID,
+			Content:   excerpt(c.Content, auditExcerpt),
+			Decision:  d,
+			DryRun:    m.dryRun,
+		})
+		ops = append(ops, operationResult{CommentID: c.ID, Operation: d.Action})
+	}
+	if m.dryRun || len(ops) == 0 {
+		return entries
+	}
+
+	for i, r := range operateAll(m.cl, ops, m.concurrency) {
+		entries[i].OK, entries[i].Error = r.OK, r.Error
+	}
+	if err := m.audit.Append(entries...); err != nil {
+		slog.Warn("failed to write the audit log", "path", m.audit.Path(), "error", err)
+	}
+	return entries
+}
+
+// settled reports whether a comment is already in the status an action
+// gives.
+func settled(c client.Comment, action string) bool {
+	switch action {
+	case "approve":
+		return c.Status == client.CommentStatusApproved
+	case "reject":
+		return c.Status == client.CommentStatusPending
+	case "spam":
+		return c.Status == client.CommentStatusSpam
+	}
+	return false
+}
+
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// resolveListID returns the ID of one of the lists of the user, given by ID
+// or slug.
+func resolveListID(cl *client.Client, list string) (uint64, error) {
+	if id, err := strconv.ParseUint(list, 10, 64); err == nil {
+		return id, nil
+	}
+	me, err := cl.GetMe()
+	if err != nil {
+		return 0, err
+	}
+	lists, err := cl.GetUserLists(me.Data.ID)
+	if err != nil {
+		return 0, err
+	}
+	for _, l := range lists {
+		if l.Slug == list {
+			return l.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("no list %q among your lists", list)
+}
+
+// decisions prints a line per decision of auto-moderate.
+type decisions []automod.Entry
+
+func (d decisions) PrintTable(w io.Writer) error {
+	if len(d) == 0 {
+		_, err := fmt.Fprintln(w, "no comments matched the rules")
+		return err
+	}
+	for _, e := range d {
+		outcome := "ok"
+		switch {
+		case e.DryRun:
+			outcome = "dry run"
+		case !e.OK:
+			outcome = "failed: " + e.Error
+		}
+		_, err := fmt.Fprintf(w, "#%d  list %d  post %d  %s by %q: %s\n  %s\n",
+			e.CommentID, e.ListID, e.PostID, e.Action, e.Rule, outcome, excerpt(e.Content, 100))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
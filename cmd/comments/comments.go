@@ -2,16 +2,15 @@ package comments
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
-	"sort"
 	"strconv"
 	"strings"
-	"text/tabwriter"
-	"time"
 
 	"github.com/quailyquaily/quail-cli/client"
 	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/commentmerge"
+	"github.com/quailyquaily/quail-cli/output"
 	"github.com/spf13/cobra"
 )
 
@@ -23,16 +22,22 @@ func NewCmd() *cobra.Command {
 
 	cmd.AddCommand(newLatestCmd())
 	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newWatchCmd())
 	cmd.AddCommand(newOperateCmd("approve"))
 	cmd.AddCommand(newOperateCmd("reject"))
 	cmd.AddCommand(newOperateCmd("spam"))
 	cmd.AddCommand(newOperateCmd("delete"))
+	cmd.AddCommand(newModerateCmd())
+	cmd.AddCommand(newAutoModerateCmd())
+	cmd.AddCommand(newReviewCmd())
+	cmd.AddCommand(newExportCmd())
 
 	return cmd
 }
 
 func newLatestCmd() *cobra.Command {
 	var limit int
+	var concurrency int
 
 	cmd := &cobra.Command{
 		Use:   "latest",
@@ -42,7 +47,7 @@ func newLatestCmd() *cobra.Command {
 				limit = 50
 			}
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
 
 			me, err := cl.GetMe()
 			if err != nil {
@@ -55,30 +60,23 @@ func newLatestCmd() *cobra.Command {
 				return
 			}
 
-			items := make([]client.Comment, 0)
-			for _, list := range lists {
-				resp, err := cl.GetCommentsByList(strconv.FormatUint(list.ID, 10), 0, limit)
-				if err != nil {
-					slog.Warn("failed to get list comments", "list_id", list.ID, "error", err)
-					continue
-				}
-				items = append(items, resp.Data.Items...)
+			ids := make([]uint64, len(lists))
+			for i, list := range lists {
+				ids[i] = list.ID
 			}
-			sort.Slice(items, func(i, j int) bool {
-				return items[i].CreatedAt.After(items[j].CreatedAt)
-			})
-			if len(items) > limit {
-				items = items[:limit]
+			items, errs := commentmerge.Latest(cl, ids, limit, commentmerge.Options{Concurrency: concurrency})
+			for _, err := range errs {
+				slog.Warn("failed to get list comments", "list_id", err.ListID, "error", err.Err)
 			}
-
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(map[string]any{"data": items})
-				return
+			if items == nil {
+				items = []client.Comment{}
 			}
-			printComments(items)
+
+			render(out, items)
 		},
 	}
 	cmd.Flags().IntVar(&limit, "limit", 50, "Comment list limit")
+	cmd.Flags().IntVar(&concurrency, "concurrency", commentmerge.DefaultConcurrency, "Number of lists read at once")
 	return cmd
 }
 
@@ -86,10 +84,14 @@ func newListCmd() *cobra.Command {
 	var list string
 	var offset int
 	var limit int
+	var filters filterFlags
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List comments for a list",
+		Long: `List comments for a list, newest first. --status, --author, --post, --since
+and --contains select comments, and --offset and --limit then page through the
+matching ones.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if list == "" {
 				cmd.Help()
@@ -98,24 +100,26 @@ func newListCmd() *cobra.Command {
 			if limit <= 0 {
 				limit = 20
 			}
+			filter, err := filters.parse()
+			if err != nil {
+				slog.Error("invalid filter", "error", err)
+				return
+			}
 
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
-			resp, err := cl.GetCommentsByList(list, offset, limit)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			comments, err := filterComments(cl, list, filter, offset, limit)
 			if err != nil {
 				slog.Error("failed to get comments", "error", err)
 				return
 			}
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(resp)
-				return
-			}
-			printComments(resp.Data.Items)
+			render(out, comments)
 		},
 	}
 	cmd.Flags().StringVar(&list, "list", "", "List id or slug")
 	cmd.Flags().IntVar(&offset, "offset", 0, "Comment list offset")
 	cmd.Flags().IntVar(&limit, "limit", 20, "Comment list limit")
+	filters.register(cmd)
 	return cmd
 }
 
@@ -137,16 +141,8 @@ func newOperateCmd(op string) *cobra.Command {
 				return
 			}
 
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(map[string]any{
-					"comment_id": commentID,
-					"operation":  op,
-					"ok":         true,
-				})
-				return
-			}
-			fmt.Printf("comment %d %s ok\n", commentID, op)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			render(out, operationResult{CommentID: commentID, Operation: op, OK: true})
 		},
 	}
 }
@@ -158,30 +154,24 @@ func title(s string) string {
 	return strings.ToUpper(s[:1]) + s[1:]
 }
 
-func printComments(items []client.Comment) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "ID\tLIST\tPOST\tAUTHOR\tSTATUS\tCREATED_AT\tCONTENT")
-	for _, item := range items {
-		author := strconv.FormatUint(item.AuthorID, 10)
-		if item.Author != nil && item.Author.Name != "" {
-			author = item.Author.Name
-		}
-		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%d\t%s\t%s\n",
-			item.ID,
-			item.ListID,
-			item.PostID,
-			author,
-			item.Status,
-			formatTime(item.CreatedAt),
-			strings.ReplaceAll(item.Content, "\n", " "),
-		)
+type operationResult struct {
+	CommentID uint64 `json:"comment_id"`
+	Operation string `json:"operation"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (r operationResult) PrintTable(w io.Writer) error {
+	if !r.OK {
+		_, err := fmt.Fprintf(w, "comment %d %s failed: %s\n", r.CommentID, r.Operation, r.Error)
+		return err
 	}
-	w.Flush()
+	_, err := fmt.Fprintf(w, "comment %d %s ok\n", r.CommentID, r.Operation)
+	return err
 }
 
-func formatTime(t time.Time) string {
-	if t.IsZero() {
-		return ""
+func render(out *output.Renderer, v any) {
+	if err := out.Render(v); err != nil {
+		slog.Error("failed to render output", "error", err)
 	}
-	return t.Format(time.RFC3339)
 }
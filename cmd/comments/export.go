@@ -0,0 +1,139 @@
+package comments
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/commentexport"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/spf13/cobra"
+)
+
+const exportPageSize = 50
+
+func newExportCmd() *cobra.Command {
+	var list string
+	var format string
+	var since string
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the comments of a list as CSV or JSON lines",
+		Long: `Export all comments of a list, newest first, as CSV or JSON lines, for
+spreadsheets. Each comment is a flat row with its author, post title,
+status, quoted comment and times. The columns are stable: new ones are
+only added at the end.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			if list == "" {
+				slog.Error("missing --list")
+				return
+			}
+			f, err := commentexport.ParseFormat(format)
+			if err != nil {
+				slog.Error("invalid --format", "error", err)
+				return
+			}
+			from, err := config.ParseSince(since)
+			if err != nil {
+				slog.Error("invalid --since", "error", err)
+				return
+			}
+
+			if file == "" || file == "-" {
+				if _, err := exportComments(cl, list, from, commentexport.NewWriter(os.Stdout, f)); err != nil {
+					slog.Error("failed to export comments", "error", err)
+				}
+				return
+			}
+			n, err := exportFile(cl, list, from, f, file)
+			if err != nil {
+				slog.Error("failed to export comments", "error", err)
+				return
+			}
+			fmt.Printf("%d comments written to %s\n", n, file)
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List ID or slug")
+	cmd.Flags().StringVar(&format, "format", string(commentexport.CSV), "Export format: csv|jsonl")
+	cmd.Flags().StringVar(&since, "since", "", "Only comments from this age, like 30d, or date, like 2026-01-31")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write to this file instead of stdout")
+	return cmd
+}
+
+// exportComments pages through the comments of the list, newest first, until
+// the first one older than since, and writes them. It returns how many were
+// written.
+func exportComments(cl *client.Client, list string, since time.Time, w *commentexport.Writer) (int, error) {
+	titles := map[uint64]string{}
+	n := 0
+	for offset := 0; ; offset += exportPageSize {
+		resp, err := cl.GetCommentsByList(list, offset, exportPageSize)
+		if err != nil {
+			return n, err
+		}
+		items := resp.Data.Items
+		for _, c := range items {
+			if !since.IsZero() && c.CreatedAt.Before(since) {
+				return n, w.Flush()
+			}
+			if err := w.Write(commentexport.RowOf(c, postTitle(cl, list, c, titles))); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if len(items) < exportPageSize {
+			return n, w.Flush()
+		}
+	}
+}
+
+// postTitle returns the title of the post of a comment, getting the post
+// when the comment came without it. Titles are cached by post, and left
+// empty for posts that can't be got.
+func postTitle(cl *client.Client, list string, c client.Comment, titles map[uint64]string) string {
+	if c.Post != nil && c.Post.Title != "" {
+		return c.Post.Title
+	}
+	if c.PostID == 0 {
+		return ""
+	}
+	if title, ok := titles[c.PostID]; ok {
+		return title
+	}
+	resp, err := cl.GetPost(list, strconv.FormatUint(c.PostID, 10))
+	if err != nil {
+		slog.Warn("failed to get post", "post_id", c.PostID, "error", err)
+		titles[c.PostID] = ""
+		return ""
+	}
+	titles[c.PostID] = resp.Data.Title
+	return resp.Data.Title
+}
+
+// exportFile exports to a temporary file that is renamed over path once
+// complete, so a failed export never leaves a partial file behind. The file
+// is only readable by the user, as comments may have readers' details.
+func exportFile(cl *client.Client, list string, since time.Time, f commentexport.Format, path string) (int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := exportComments(cl, list, since, commentexport.NewWriter(tmp, f))
+	if err != nil {
+		tmp.Close()
+		return n, err
+	}
+	if err := tmp.Close(); err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), path)
+}
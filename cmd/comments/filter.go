@@ -0,0 +1,130 @@
+package comments
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	filterPageSize = 50
+	// defaultScan bounds the comments of a list read looking for matching
+	// ones, unless --scan is given.
+	defaultScan = 1000
+)
+
+// filterFlags are the flags selecting comments, shared by the commands
+// listing and moderating them.
+type filterFlags struct {
+	status   string
+	author   string
+	post     uint64
+	since    string
+	contains string
+	scan     int
+}
+
+func (f *filterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.status, "status", "", "Only comments with this status: pending, approved or spam")
+	cmd.Flags().StringVar(&f.author, "author", "", "Only comments by this author name or id")
+	cmd.Flags().Uint64Var(&f.post, "post", 0, "Only comments on this post id")
+	cmd.Flags().StringVar(&f.since, "since", "", "Only comments from this age, like 2d or 12h, or date, like 2026-01-31")
+	cmd.Flags().StringVar(&f.contains, "contains", "", "Only comments containing this text, ignoring case")
+	cmd.Flags().IntVar(&f.scan, "scan", defaultScan, "Most comments of a list read looking for matching ones")
+}
+
+func (f *filterFlags) parse() (commentFilter, error) {
+	ret := commentFilter{author: f.author, post: f.post, contains: strings.ToLower(f.contains), scan: f.scan}
+	if f.status != "" {
+		status, err := client.ParseCommentStatus(f.status)
+		if err != nil {
+			return ret, err
+		}
+		ret.status = &status
+	}
+	var err error
+	ret.since, err = config.ParseSince(f.since)
+	return ret, err
+}
+
+// commentFilter selects comments. Zero fields match all comments.
+type commentFilter struct {
+	status   *int
+	author   string
+	post     uint64
+	since    time.Time
+	contains string
+	// scan is the most comments read, defaultScan if zero.
+	scan int
+}
+
+func (f commentFilter) empty() bool {
+	return f.status == nil && f.author == "" && f.post == 0 && f.since.IsZero() && f.contains == ""
+}
+
+func (f commentFilter) match(c client.Comment) bool {
+	if f.status != nil && c.Status != *f.status {
+		return false
+	}
+	if f.author != "" && !strings.EqualFold(authorName(c), f.author) && strconv.FormatUint(c.AuthorID, 10) != f.author {
+		return false
+	}
+	if f.post != 0 && c.PostID != f.post {
+		return false
+	}
+	if !f.since.IsZero() && c.CreatedAt.Before(f.since) {
+		return false
+	}
+	return f.contains == "" || strings.Contains(strings.ToLower(c.Content), f.contains)
+}
+
+// filterComments returns up to limit comments of a list matching the filter,
+// skipping offset of them. Comments come newest first, so reading stops at
+// comments older than --since.
+func filterComments(cl *client.Client, list string, f commentFilter, offset, limit int) ([]client.Comment, error) {
+	if f.empty() {
+		resp, err := cl.GetCommentsByList(list, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data.Items, nil
+	}
+
+	scan := f.scan
+	if scan <= 0 {
+		scan = defaultScan
+	}
+	ret := []client.Comment{}
+	for read := 0; read < scan && len(ret) < limit; read += filterPageSize {
+		size := min(filterPageSize, scan-read)
+		resp, err := cl.GetCommentsByList(list, read, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range resp.Data.Items {
+			if !f.since.IsZero() && c.CreatedAt.Before(f.since) {
+				return ret, nil
+			}
+			if !f.match(c) || len(ret) >= limit {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			ret = append(ret, c)
+		}
+		if len(resp.Data.Items) < size {
+			return ret, nil
+		}
+	}
+	if len(ret) < limit {
+		slog.Warn("stopped looking for matching comments, raise --scan or narrow with --since to find older ones", "list", list, "scanned", scan)
+	}
+	return ret, nil
+}
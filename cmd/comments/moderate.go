@@ -0,0 +1,280 @@
+package comments
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// operations are what OperateComment does to a comment.
+var operations = []string{"approve", "reject", "spam", "delete"}
+
+// maxSummary is how many comments the confirmation of moderate shows.
+const maxSummary = 20
+
+func init() {
+	output.Register[operationResult](
+		output.Col("comment_id", func(r operationResult) any { return r.CommentID }),
+		output.Col("operation", func(r operationResult) any { return r.Operation }),
+		output.Col("ok", func(r operationResult) any { return r.OK }),
+		output.Col("error", func(r operationResult) any { return r.Error }),
+	)
+}
+
+func newModerateCmd() *cobra.Command {
+	var list string
+	var filters filterFlags
+	var limit int
+	var concurrency int
+	var yes, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "moderate <approve|reject|spam|delete> [comment_id...|-]",
+		Short: "Approve, reject, spam or delete many comments at once",
+		Long: `Apply an operation to the comments matching --status, --author, --post,
+--since and --contains, on --list or on all your lists. Or apply it to comment
+IDs, given as arguments or read from stdin with -, one per line or as the
+NDJSON output of comments list.
+
+A summary of the comments is shown for confirmation, unless --yes is set.
+--dry-run shows it and stops.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			op := args[0]
+			if !slices.Contains(operations, op) {
+				slog.Error("invalid operation, use approve, reject, spam or delete", "operation", op)
+				return
+			}
+			filter, err := filters.parse()
+			if err != nil {
+				slog.Error("invalid filter", "error", err)
+				return
+			}
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			var ids []uint64
+			var matched []client.Comment
+			switch {
+			case len(args) > 1:
+				if !filter.empty() || list != "" {
+					slog.Error("give comment ids or filters, not both")
+					return
+				}
+				if ids, err = commentIDs(args[1:]); err != nil {
+					slog.Error("invalid comment ids", "error", err)
+					return
+				}
+				slices.Sort(ids)
+				ids = slices.Compact(ids)
+			case filter.empty():
+				slog.Error("give comment ids, or at least one of --status, --author, --post, --since and --contains")
+				return
+			default:
+				if matched, err = matchComments(cl, list, filter, limit); err != nil {
+					slog.Error("failed to get comments", "error", err)
+					return
+				}
+				for _, c := range matched {
+					ids = append(ids, c.ID)
+				}
+			}
+			if len(ids) == 0 {
+				fmt.Fprintln(os.Stderr, "no comments to moderate")
+				return
+			}
+
+			printSummary(os.Stderr, op, ids, matched)
+			if dryRun {
+				return
+			}
+			if !yes {
+				ok, err := confirm(fmt.Sprintf("%s %d comment(s)?", op, len(ids)))
+				if err != nil {
+					slog.Error("failed to confirm", "error", err)
+					return
+				}
+				if !ok {
+					fmt.Fprintln(os.Stderr, "aborted")
+					return
+				}
+			}
+			ops := make([]operationResult, len(ids))
+			for i, id := range ids {
+				ops[i] = operationResult{CommentID: id, Operation: op}
+			}
+			render(out, operationResults(operateAll(cl, ops, concurrency)))
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List id or slug, all your lists by default")
+	filters.register(cmd)
+	cmd.Flags().IntVar(&limit, "limit", 100, "Maximum number of comments matched by filters")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of comments moderated at once")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask for confirmation")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the comments and stop")
+	return cmd
+}
+
+// matchComments returns up to limit comments matching the filter, on a list
+// or on all the lists of the user.
+func matchComments(cl *client.Client, list string, filter commentFilter, limit int) ([]client.Comment, error) {
+	lists := []string{list}
+	if list == "" {
+		me, err := cl.GetMe()
+		if err != nil {
+			return nil, err
+		}
+		userLists, err := cl.GetUserLists(me.Data.ID)
+		if err != nil {
+			return nil, err
+		}
+		lists = lists[:0]
+		for _, l := range userLists {
+			lists = append(lists, strconv.FormatUint(l.ID, 10))
+		}
+	}
+
+	var ret []client.Comment
+	for _, l := range lists {
+		if len(ret) >= limit {
+			break
+		}
+		comments, err := filterComments(cl, l, filter, 0, limit-len(ret))
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", l, err)
+		}
+		ret = append(ret, comments...)
+	}
+	return ret, nil
+}
+
+// commentIDs parses comment IDs given as arguments, or read from stdin for
+// "-".
+func commentIDs(args []string) ([]uint64, error) {
+	if len(args) == 1 && args[0] == "-" {
+		return readCommentIDs(os.Stdin)
+	}
+	ids := make([]uint64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid comment id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// readCommentIDs reads comment IDs separated by spaces or lines, or JSON
+// lines of comments.
+func readCommentIDs(r io.Reader) ([]uint64, error) {
+	var ids []uint64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "{") {
+			var c struct {
+				ID uint64 `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(line), &c); err != nil || c.ID == 0 {
+				return nil, fmt.Errorf("invalid comment line %q", line)
+			}
+			ids = append(ids, c.ID)
+			continue
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		more, err := commentIDs(args)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, more...)
+	}
+	return ids, scanner.Err()
+}
+
+func printSummary(w io.Writer, op string, ids []uint64, matched []client.Comment) {
+	fmt.Fprintf(w, "%s %d comment(s):\n", op, len(ids))
+	if len(matched) == 0 {
+		shown := make([]string, 0, maxSummary)
+		for _, id := range ids[:min(len(ids), maxSummary)] {
+			shown = append(shown, "#"+strconv.FormatUint(id, 10))
+		}
+		fmt.Fprintf(w, "  %s\n", strings.Join(shown, " "))
+	} else {
+		commentLines(matched[:min(len(matched), maxSummary)]).PrintTable(w)
+	}
+	if len(ids) > maxSummary {
+		fmt.Fprintf(w, "  and %d more\n", len(ids)-maxSummary)
+	}
+}
+
+// confirm asks a yes or no question on the terminal.
+func confirm(question string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("stdin is not a terminal, pass --yes to go on without confirmation")
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// operateAll applies operations to comments, concurrency at a time, and
+// returns them with their outcomes, in order.
+func operateAll(cl *client.Client, ops []operationResult, concurrency int) []operationResult {
+	results := slices.Clone(ops)
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := &results[i]
+			r.OK, r.Error = true, ""
+			if err := cl.OperateComment(r.CommentID, r.Operation); err != nil {
+				r.OK, r.Error = false, err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// operationResults are the outcomes of moderate, with a count of failures.
+type operationResults []operationResult
+
+func (r operationResults) PrintTable(w io.Writer) error {
+	failed := 0
+	for _, result := range r {
+		if !result.OK {
+			failed++
+		}
+		if err := result.PrintTable(w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d ok, %d failed\n", len(r)-failed, failed)
+	return err
+}
@@ -0,0 +1,90 @@
+package comments
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/tui"
+	"github.com/quailyquaily/quail-cli/tui/reviewapp"
+	"github.com/quailyquaily/quail-cli/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newReviewCmd() *cobra.Command {
+	var list string
+	var filters filterFlags
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Step through pending comments and moderate them with single keys",
+		Long: `Step through pending comments, oldest first, on --list or on all your lists.
+Each comment shows its post, author and full content. Press a to approve, r to
+reject, s to mark as spam, d to delete, n to skip, p to go back, R to reply and
+q to quit. Decisions are sent in batches, and a summary is printed at the end.
+
+The filters of comments list select other comments, like --status spam to
+review comments marked as spam.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !util.StdoutIsTerminal() || !term.IsTerminal(int(os.Stdin.Fd())) {
+				slog.Error("comments review needs a terminal")
+				return
+			}
+			filter, err := filters.parse()
+			if err != nil {
+				slog.Error("invalid filter", "error", err)
+				return
+			}
+			if filter.status == nil {
+				pending := client.CommentStatusPending
+				filter.status = &pending
+			}
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			comments, err := matchComments(cl, list, filter, limit)
+			if err != nil {
+				slog.Error("failed to get comments", "error", err)
+				return
+			}
+			if len(comments) == 0 {
+				fmt.Println("no comments to review")
+				return
+			}
+			sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+
+			app := reviewapp.New(cl, comments)
+			if _, err := tui.NewProgram(os.Stdin, os.Stdout).Run(app); err != nil {
+				slog.Error("failed to run comments review", "error", err)
+			}
+			render(out, reviewSummary(app.Summary()))
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List id or slug, all your lists by default")
+	filters.register(cmd)
+	cmd.Flags().IntVar(&limit, "limit", 100, "Maximum number of comments to review")
+	return cmd
+}
+
+// reviewSummary prints the counts of a review session.
+type reviewSummary reviewapp.Summary
+
+func (s reviewSummary) PrintTable(w io.Writer) error {
+	fmt.Fprintf(w, "reviewed %d comment(s), skipped %d, replied %d time(s)\n", s.Reviewed, s.Skipped, s.Replies)
+	for _, op := range operations {
+		if n := s.Counts[op]; n > 0 {
+			fmt.Fprintf(w, "  %s: %d\n", op, n)
+		}
+	}
+	for _, f := range s.Failures {
+		fmt.Fprintf(w, "  comment %d %s failed: %s\n", f.CommentID, f.Operation, f.Error)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package comments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/commentwatch"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/spf13/cobra"
+)
+
+func newWatchCmd() *cobra.Command {
+	var interval, maxInterval time.Duration
+	var hook string
+	var hookTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print new comments on your lists as they arrive",
+		Long: `Poll your lists for comments and print the new ones as they arrive, until
+interrupted. Lists created while watching are picked up.
+
+With --output ndjson or json, each comment is a JSON line. --exec runs a shell
+command for each new comment, with the comment as JSON on stdin and
+QUAIL_COMMENT_ID, QUAIL_LIST_ID, QUAIL_POST_ID, QUAIL_AUTHOR_ID and
+QUAIL_AUTHOR in its environment.
+
+When the API can't be reached, polls are spaced out up to --max-interval.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if interval <= 0 {
+				slog.Error("--interval must be positive")
+				return
+			}
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := *cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			if out.Format.Kind == output.JSON {
+				// one document per poll can't be read as a stream
+				out.Format.Kind = output.NDJSON
+			}
+
+			w := commentwatch.New(cl)
+			w.Interval, w.MaxInterval = interval, maxInterval
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			slog.Info("watching comments, press Ctrl-C to stop", "interval", interval)
+			err := w.Run(ctx, func(comments []client.Comment) {
+				if out.Format.Kind == output.Table && len(out.Columns) == 0 {
+					render(&out, commentLines(comments))
+				} else {
+					render(&out, comments)
+				}
+				if hook != "" {
+					for _, c := range comments {
+						runHook(ctx, hook, hookTimeout, c)
+					}
+				}
+			}, func(err error) {
+				slog.Warn("failed to poll comments", "error", err)
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("failed to watch comments", "error", err)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", commentwatch.DefaultInterval, "Time between polls")
+	cmd.Flags().DurationVar(&maxInterval, "max-interval", commentwatch.DefaultMaxInterval, "Longest time between polls while the API can't be reached")
+	cmd.Flags().StringVar(&hook, "exec", "", "Shell command to run for each new comment, with sh, or cmd on Windows")
+	cmd.Flags().DurationVar(&hookTimeout, "exec-timeout", time.Minute, "Time a --exec command may run")
+	return cmd
+}
+
+// commentLines prints a line per comment, as a log of comments.
+type commentLines []client.Comment
+
+func (c commentLines) PrintTable(w io.Writer) error {
+	for _, comment := range c {
+		_, err := fmt.Fprintf(w, "%s  #%d  list %d  post %d  %s: %s\n",
+			comment.CreatedAt.Local().Format("2006-01-02 15:04"), comment.ID, comment.ListID, comment.PostID, authorName(comment), excerpt(comment.Content, 120))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func authorName(c client.Comment) string {
+	if c.Author != nil && c.Author.Name != "" {
+		return c.Author.Name
+	}
+	if c.AuthorID == 0 {
+		return "anonymous"
+	}
+	return fmt.Sprintf("user %d", c.AuthorID)
+}
+
+// shellCommand runs command with the shell of the platform, cmd on Windows
+// and sh elsewhere.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runHook runs the --exec command for a comment. Its failures are warnings,
+// watching goes on.
+func runHook(ctx context.Context, command string, timeout time.Duration, c client.Comment) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		slog.Warn("failed to encode comment", "comment_id", c.ID, "error", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = strings.NewReader(string(data) + "\n")
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	cmd.Env = append(os.Environ(),
+		"QUAIL_COMMENT_ID="+strconv.FormatUint(c.ID, 10),
+		"QUAIL_LIST_ID="+strconv.FormatUint(c.ListID, 10),
+		"QUAIL_POST_ID="+strconv.FormatUint(c.PostID, 10),
+		"QUAIL_AUTHOR_ID="+strconv.FormatUint(c.AuthorID, 10),
+		"QUAIL_AUTHOR="+authorName(c),
+	)
+	if err := cmd.Run(); err != nil {
+		slog.Warn("--exec command failed", "comment_id", c.ID, "error", err)
+	}
+}
@@ -0,0 +1,336 @@
+package configcmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/util"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change the config file",
+	}
+
+	cmd.AddCommand(newGetCmd())
+	cmd.AddCommand(newSetCmd())
+	cmd.AddCommand(newUnsetCmd())
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newPathCmd())
+	cmd.AddCommand(newValidateCmd())
+
+	return cmd
+}
+
+func newGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a config key",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			key, sub, ok := lookup(args[0])
+			if !ok {
+				return
+			}
+			settings, err := util.ReadConfigSettings(util.ResolveConfigFile())
+			if err != nil && !os.IsNotExist(err) {
+				slog.Error("failed to read config", "error", err)
+				return
+			}
+
+			name := key.Name
+			if sub != "" {
+				name += "." + sub
+			}
+			value := getNested(settings, name)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			if out.Format.Kind != output.Table {
+				render(out, map[string]any{name: displayValue(key, value)})
+				return
+			}
+			if m, ok := value.(map[string]any); ok {
+				for _, k := range sortedKeys(m) {
+					fmt.Printf("%s: %v\n", k, m[k])
+				}
+				return
+			}
+			fmt.Println(displayValue(key, value))
+		},
+	}
+}
+
+func newSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a config key",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			key, sub, ok := lookup(args[0])
+			if !ok {
+				return
+			}
+			if key.Kind == config.KindStringMap && sub == "" {
+				slog.Error("set a sub key of the map", "example", key.Name+".<name>")
+				return
+			}
+
+			var value any = args[1]
+			if sub == "" {
+				var err error
+				if value, err = key.Parse(args[1]); err != nil {
+					slog.Error("invalid value", "key", key.Name, "error", err)
+					return
+				}
+			}
+
+			name := key.Name
+			if sub != "" {
+				name += "." + sub
+			}
+			err := util.EditConfigFile(util.ResolveConfigFile(), func(settings map[string]any) error {
+				setNested(settings, name, value)
+				return nil
+			})
+			if err != nil {
+				slog.Error("failed to write config", "error", err)
+				return
+			}
+			fmt.Printf("%s = %v\n", name, displayValue(key, value))
+		},
+	}
+}
+
+func newUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a config key",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := strings.ToLower(strings.TrimSpace(args[0]))
+			if _, _, ok := config.Lookup(name); !ok {
+				// unknown keys can be removed too, that's how typos get fixed
+				slog.Warn("unknown config key", "key", name)
+			}
+			err := util.EditConfigFile(util.ResolveConfigFile(), func(settings map[string]any) error {
+				deleteNested(settings, name)
+				return nil
+			})
+			if err != nil {
+				slog.Error("failed to write config", "error", err)
+				return
+			}
+			fmt.Printf("%s removed\n", name)
+		},
+	}
+}
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List config keys and values, secrets are masked",
+		Run: func(cmd *cobra.Command, args []string) {
+			settings, err := util.ReadConfigSettings(util.ResolveConfigFile())
+			if err != nil && !os.IsNotExist(err) {
+				slog.Error("failed to read config", "error", err)
+				return
+			}
+
+			values := make(map[string]any)
+			names := make([]string, 0)
+			for _, key := range config.Keys {
+				value := getNested(settings, key.Name)
+				if m, ok := value.(map[string]any); ok && key.Kind == config.KindStringMap {
+					for _, sub := range sortedKeys(m) {
+						name := key.Name + "." + sub
+						names = append(names, name)
+						values[name] = m[sub]
+					}
+					continue
+				}
+				names = append(names, key.Name)
+				values[key.Name] = displayValue(key, value)
+			}
+
+			entries := make([]entry, len(names))
+			for i, name := range names {
+				entries[i] = entry{Key: name, Value: values[name]}
+			}
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			render(out, entries)
+		},
+	}
+}
+
+func newPathCmd() *cobra.Command {
+	var project bool
+
+	cmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the config file",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !project {
+				fmt.Println(util.ResolveConfigFile())
+				return
+			}
+			p := config.CurrentProject()
+			if p == nil {
+				slog.Error("no project config found", "file", config.ProjectFileName)
+				return
+			}
+			fmt.Println(p.Path)
+		},
+	}
+	cmd.Flags().BoolVar(&project, "project", false, "Print the path of the "+config.ProjectFileName+" of the working directory")
+	return cmd
+}
+
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "validate",
+		Short:        "Check the config file for unknown keys and invalid values",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configFile := util.ResolveConfigFile()
+			settings, err := util.ReadConfigSettings(configFile)
+			if err != nil {
+				return fmt.Errorf("failed to read config %s: %w", configFile, err)
+			}
+
+			issues := config.ValidateSettings(settings)
+			if cwd, err := os.Getwd(); err == nil {
+				if _, err := config.FindProject(cwd); err != nil {
+					issues = append(issues, config.Issue{Key: config.ProjectFileName, Message: err.Error()})
+				}
+			}
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			render(out, validation{ConfigFile: configFile, Issues: issues})
+			if len(issues) > 0 {
+				return fmt.Errorf("%s has %d problem(s)", configFile, len(issues))
+			}
+			return nil
+		},
+	}
+}
+
+// entry is a key of config list.
+type entry struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+func init() {
+	output.Register[entry](
+		output.Col("key", func(e entry) any { return e.Key }),
+		output.Col("value", func(e entry) any { return e.Value }),
+	)
+}
+
+type validation struct {
+	ConfigFile string         `json:"config_file"`
+	Issues     []config.Issue `json:"issues"`
+}
+
+func (v validation) PrintTable(w io.Writer) error {
+	if len(v.Issues) == 0 {
+		_, err := fmt.Fprintf(w, "%s is valid\n", v.ConfigFile)
+		return err
+	}
+	for _, issue := range v.Issues {
+		if issue.Suggestion != "" {
+			fmt.Fprintf(w, "%s: %s, did you mean %q?\n", issue.Key, issue.Message, issue.Suggestion)
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", issue.Key, issue.Message)
+		}
+	}
+	return nil
+}
+
+func render(out *output.Renderer, v any) {
+	if err := out.Render(v); err != nil {
+		slog.Error("failed to render output", "error", err)
+	}
+}
+
+func lookup(name string) (config.Key, string, bool) {
+	key, sub, ok := config.Lookup(name)
+	if !ok {
+		if suggestion := config.Suggest(strings.ToLower(name)); suggestion != "" {
+			slog.Error("unknown config key", "key", name, "did_you_mean", suggestion)
+		} else {
+			slog.Error("unknown config key", "key", name)
+		}
+	}
+	return key, sub, ok
+}
+
+func displayValue(key config.Key, value any) any {
+	if value == nil {
+		return ""
+	}
+	if key.Secret {
+		s, _ := value.(string)
+		return util.MaskSecret(s)
+	}
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return value
+}
+
+func getNested(settings map[string]any, name string) any {
+	var cur any = settings
+	for _, part := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+func setNested(settings map[string]any, name string, value any) {
+	parts := strings.Split(name, ".")
+	m := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+func deleteNested(settings map[string]any, name string) {
+	parts := strings.Split(name, ".")
+	m := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			return
+		}
+		m = next
+	}
+	delete(m, parts[len(parts)-1])
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+package listcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Manage your lists (channels)",
+	}
+
+	cmd.AddCommand(newLsCmd())
+	cmd.AddCommand(newShowCmd())
+	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(newUpdateCmd())
+
+	return cmd
+}
+
+func newLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List your lists",
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			me, err := cl.GetMe()
+			if err != nil {
+				slog.Error("failed to get current user", "error", err)
+				return
+			}
+			lists, err := cl.GetUserLists(me.Data.ID)
+			if err != nil {
+				slog.Error("failed to get lists", "error", err)
+				return
+			}
+			if lists == nil {
+				lists = []client.List{}
+			}
+			render(out, lists)
+		},
+	}
+}
+
+func newShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <list>",
+		Short: "Show all settings of a list, by ID or slug",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			resp, err := cl.GetListSettings(args[0])
+			if err != nil {
+				slog.Error("failed to get list", "error", err)
+				return
+			}
+			render(out, resp.Data)
+		},
+	}
+}
+
+// settingsFlags are the settings of a list that create and update set.
+type settingsFlags struct {
+	slug        string
+	title       string
+	description string
+	tagline     string
+	set         []string
+}
+
+func (f *settingsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.slug, "slug", "", "List slug, used in its URL")
+	cmd.Flags().StringVar(&f.title, "title", "", "List title")
+	cmd.Flags().StringVar(&f.description, "description", "", "List description")
+	cmd.Flags().StringVar(&f.tagline, "tagline", "", "List tagline")
+	cmd.Flags().StringArrayVar(&f.set, "set", nil, "Set another setting, like key=value; JSON values, like true or 5, are decoded")
+}
+
+// payload returns the settings given on the command line, and only them, so
+// that an update leaves the others as they are.
+func (f *settingsFlags) payload(cmd *cobra.Command) (map[string]any, error) {
+	ret := map[string]any{}
+	for _, s := range f.set {
+		key, value, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set %q, use key=value", s)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+		ret[key] = v
+	}
+	for name, value := range map[string]string{
+		"slug":        f.slug,
+		"title":       f.title,
+		"description": f.description,
+		"tagline":     f.tagline,
+	} {
+		if cmd.Flags().Changed(name) {
+			ret[name] = value
+		}
+	}
+	return ret, nil
+}
+
+func newCreateCmd() *cobra.Command {
+	var flags settingsFlags
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a list",
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			if flags.slug == "" || flags.title == "" {
+				slog.Error("missing --slug or --title")
+				return
+			}
+			payload, err := flags.payload(cmd)
+			if err != nil {
+				slog.Error("invalid settings", "error", err)
+				return
+			}
+			resp, err := cl.CreateList(payload)
+			if err != nil {
+				slog.Error("failed to create list", "error", err)
+				return
+			}
+			render(out, resp.Data)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func newUpdateCmd() *cobra.Command {
+	var flags settingsFlags
+
+	cmd := &cobra.Command{
+		Use:   "update <list>",
+		Short: "Change the settings of a list, by ID or slug",
+		Long: `Change the settings of a list, by ID or slug. Only the settings given are
+changed. --set changes settings without their own flag, and can be repeated.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+
+			payload, err := flags.payload(cmd)
+			if err != nil {
+				slog.Error("invalid settings", "error", err)
+				return
+			}
+			if len(payload) == 0 {
+				slog.Error("nothing to update, give at least one setting")
+				return
+			}
+			resp, err := cl.UpdateList(args[0], payload)
+			if err != nil {
+				slog.Error("failed to update list", "error", err)
+				return
+			}
+			render(out, resp.Data)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func render(out *output.Renderer, v any) {
+	if err := out.Render(v); err != nil {
+		slog.Error("failed to render output", "error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package listcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/client/fakeapi"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
+)
+
+// run runs the list command with the args and returns its JSON output.
+func run(t *testing.T, cl *client.Client, args ...string) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	ctx := context.WithValue(context.Background(), common.CTX_CLIENT{}, cl)
+	ctx = context.WithValue(ctx, common.CTX_OUTPUT{}, output.New(output.Format{Kind: output.JSON}, &buf))
+	cmd := NewCmd()
+	cmd.SetArgs(args)
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var ret map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatalf("%v: output %q: %v", args, buf.String(), err)
+	}
+	return ret
+}
+
+func TestShowReturnsSetSettings(t *testing.T) {
+	s := fakeapi.New(1)
+	defer s.Close()
+	s.AddList(client.List{ID: 3, Slug: "gophers", Title: "Gophers"})
+	cl := s.Client()
+
+	got := run(t, cl, "update", "gophers", "--tagline", "Go news", "--set", "theme=dark", "--set", "comments_enabled=false")
+	if got["theme"] != "dark" || got["tagline"] != "Go news" {
+		t.Fatalf("update returned %v", got)
+	}
+
+	got = run(t, cl, "show", "gophers")
+	want := map[string]any{
+		"id": float64(3), "slug": "gophers", "title": "Gophers", "description": "", "tagline": "Go news",
+		"theme": "dark", "comments_enabled": false,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("show returned %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("show returned %s = %v, want %v", k, got[k], v)
+		}
+	}
+}
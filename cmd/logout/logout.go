@@ -0,0 +1,36 @@
+package logout
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/util"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Revoke the OAuth token and remove credentials from the config",
+		Run: func(cmd *cobra.Command, args []string) {
+			apiBase := cmd.Context().Value(common.CTX_API_BASE{}).(string)
+			credential := cmd.Context().Value(common.CTX_CREDENTIAL{}).(util.Credential)
+
+			err := util.Logout(apiBase)
+			switch {
+			case errors.Is(err, util.ErrRevokeFailed):
+				slog.Warn("credentials removed from config, but the server did not confirm revocation", "error", err)
+			case err != nil:
+				slog.Error("failed to logout", "error", err)
+				return
+			default:
+				fmt.Println("Logged out.")
+			}
+			if credential.Source == util.CredentialSourceEnv {
+				fmt.Println("QUAIL_API_KEY is still set in your environment. Unset it to stop using the API key.")
+			}
+		},
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/quailyquaily/quail-cli/client"
 	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
 	"github.com/spf13/cobra"
 )
 
@@ -14,16 +15,14 @@ func NewCmd() *cobra.Command {
 		Short: "Get current user information",
 		Run: func(cmd *cobra.Command, args []string) {
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
 			result, err := cl.GetMe()
 			if err != nil {
 				slog.Error("failed to get user information", "error", err)
 				return
 			}
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(result)
-			} else {
-				client.PrettyPrintUser(result)
+			if err := out.Render(result.Data); err != nil {
+				slog.Error("failed to render output", "error", err)
 			}
 		},
 	}
@@ -2,33 +2,53 @@ package post
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/quailyquaily/quail-cli/client"
 	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/output"
 	"github.com/quailyquaily/quail-cli/util"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var (
 	listSlug  string
 	postSlug  string
 	doPublish bool
+	preset    string
 )
 
-func upsertPost(cl *client.Client, filepath string, frontMatterMapping map[string]string, format string) error {
+func upsertPost(cl *client.Client, filepath string, cfg *config.Config, p config.Preset, out *output.Renderer) error {
 	if filepath == "" {
 		return fmt.Errorf("filepath is required")
 	}
+	if listSlug == "" {
+		return fmt.Errorf("list is required, use --list or set list in %s", config.ProjectFileName)
+	}
+	if cfg.Project != nil {
+		filepath = resolvePostPath(cfg.Project, filepath)
+	}
 
-	frontMatter, content, err := util.ParseMarkdownWithFrontMatter(filepath, frontMatterMapping)
+	frontMatter, content, err := util.ParseMarkdownWithFrontMatter(filepath, cfg.Post.FrontmatterMapping)
 	if err != nil {
 		return err
 	}
+	if frontMatter.Theme == "" {
+		frontMatter.Theme = p.Theme
+	}
+	if frontMatter.Tags == "" {
+		frontMatter.Tags = p.Tags
+	}
+	if cfg.Project != nil {
+		frontMatter.CoverImageUrl = cfg.Project.RewriteAssetURL(frontMatter.CoverImageUrl)
+		content = util.RewriteImageURLs(content, cfg.Project.RewriteAssetURL)
+	}
 
 	var datetime *time.Time
-	if doPublish {
+	if doPublish || p.Publish {
 		datetime = frontMatter.Datetime
 		if datetime == nil {
 			now := time.Now()
@@ -53,16 +73,23 @@ func upsertPost(cl *client.Client, filepath string, frontMatterMapping map[strin
 		return err
 	}
 
-	if format == common.FORMAT_JSON {
-		client.PrettyPrintJSON(result)
-	} else {
-		client.PrettyPrintPost(result)
-	}
+	return out.Render(result.Data)
+}
 
-	return nil
+// resolvePostPath finds a post that isn't relative to the working directory
+// in the content root of the project.
+func resolvePostPath(project *config.Project, path string) string {
+	if _, err := os.Stat(path); err == nil || filepath.IsAbs(path) {
+		return path
+	}
+	candidate := filepath.Join(project.ContentDir(), path)
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate
+	}
+	return path
 }
 
-func modPost(cmd *cobra.Command, cl *client.Client, op, format string) {
+func modPost(cmd *cobra.Command, cl *client.Client, op string, out *output.Renderer) {
 	if postSlug == "" || listSlug == "" {
 		cmd.Help()
 		return
@@ -72,10 +99,8 @@ func modPost(cmd *cobra.Command, cl *client.Client, op, format string) {
 		fmt.Println(err)
 		return
 	}
-	if format == common.FORMAT_JSON {
-		client.PrettyPrintJSON(result)
-	} else {
-		client.PrettyPrintPost(result)
+	if err := out.Render(result.Data); err != nil {
+		fmt.Println(err)
 	}
 }
 
@@ -89,10 +114,28 @@ func NewCmd() *cobra.Command {
 				return
 			}
 
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
 
-			frontMatterMapping := viper.GetStringMapString("post.frontmatter_mapping")
+			cfg := config.Current()
+			var p config.Preset
+			if preset != "" {
+				if cfg.Project == nil {
+					fmt.Printf("--preset needs a %s project config\n", config.ProjectFileName)
+					return
+				}
+				var err error
+				if p, err = cfg.Project.Preset(preset); err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
+			if listSlug == "" {
+				listSlug = p.List
+			}
+			if listSlug == "" {
+				listSlug = cfg.Post.List
+			}
 
 			action := args[0]
 			switch action {
@@ -103,7 +146,7 @@ func NewCmd() *cobra.Command {
 				}
 
 				filepath := args[1]
-				if err := upsertPost(cl, filepath, frontMatterMapping, format); err != nil {
+				if err := upsertPost(cl, filepath, cfg, p, out); err != nil {
 					fmt.Println(err)
 					return
 				}
@@ -118,23 +161,21 @@ func NewCmd() *cobra.Command {
 						fmt.Println(err)
 						return
 					}
-					if format == common.FORMAT_JSON {
-						client.PrettyPrintJSON(result)
-					} else {
-						client.PrettyPrintPost(result)
+					if err := out.Render(result.Data); err != nil {
+						fmt.Println(err)
 					}
 				}
 			case "publish":
 				{
-					modPost(cmd, cl, "publish", format)
+					modPost(cmd, cl, "publish", out)
 				}
 			case "unpublish":
 				{
-					modPost(cmd, cl, "unpublish", format)
+					modPost(cmd, cl, "unpublish", out)
 				}
 			case "deliver":
 				{
-					modPost(cmd, cl, "deliver", format)
+					modPost(cmd, cl, "deliver", out)
 				}
 			default:
 				cmd.Help()
@@ -145,6 +186,7 @@ func NewCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&listSlug, "list", "l", "", "Channel slug")
 	cmd.Flags().StringVarP(&postSlug, "post", "p", "", "Post slug")
 	cmd.Flags().BoolVar(&doPublish, "publish", false, "Publish the post")
+	cmd.Flags().StringVar(&preset, "preset", "", "Apply a preset from "+config.ProjectFileName)
 
 	return cmd
 }
@@ -0,0 +1,81 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/thread"
+	"github.com/quailyquaily/quail-cli/util"
+	"golang.org/x/term"
+)
+
+// commentThreads prints comments as indented reply threads, with their
+// content wrapped to the terminal.
+type commentThreads []client.Comment
+
+func (c commentThreads) PrintTable(w io.Writer) error {
+	if len(c) == 0 {
+		_, err := fmt.Fprintln(w, "no comments")
+		return err
+	}
+	return thread.Render(w, thread.Build(c), thread.Options{
+		Width: min(util.TerminalWidth(80), maxReadWidth),
+		Color: util.UseColor(),
+	})
+}
+
+// renderComments renders comments as threads in table output, unless
+// columns are chosen.
+func renderComments(out *output.Renderer, comments []client.Comment) {
+	if out.Format.Kind == output.Table && len(out.Columns) == 0 {
+		render(out, commentThreads(comments))
+		return
+	}
+	render(out, comments)
+}
+
+// commentContent returns the text of a new comment: the --content flag,
+// stdin, or what is written in $EDITOR.
+func commentContent(cl *client.Client, content string, set bool, postID, replyTo uint64) (string, error) {
+	if set && content != "-" {
+		return strings.TrimSpace(content), nil
+	}
+	if content == "-" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		data, err := io.ReadAll(os.Stdin)
+		return strings.TrimSpace(string(data)), err
+	}
+
+	help := "\n" + util.Scissors + "\n# Write the comment above this line, what is below is ignored.\n"
+	if replyTo != 0 {
+		help += fmt.Sprintf("# Replying to comment #%d", replyTo)
+		if quoted := findComment(cl, postID, replyTo); quoted != nil {
+			help += ":\n#\n"
+			for _, line := range strings.Split(strings.TrimSpace(quoted.Content), "\n") {
+				help += "# > " + line + "\n"
+			}
+		} else {
+			help += "\n"
+		}
+	}
+	text, err := util.EditText(help)
+	return strings.TrimSpace(text), err
+}
+
+// findComment looks for a comment among the latest comments of a post, to
+// quote it while replying.
+func findComment(cl *client.Client, postID, commentID uint64) *client.Comment {
+	resp, err := cl.GetCommentsByPost(postID, 0, syncCommentLimit)
+	if err != nil {
+		return nil
+	}
+	for _, n := range thread.Build(resp.Data.Items) {
+		if c := n.Find(commentID); c != nil {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/digest"
+	"github.com/spf13/cobra"
+)
+
+func newDigestCmd() *cobra.Command {
+	var since, until string
+	var file string
+	var format string
+	var paragraphs int
+	var templatePath string
+	var title string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Write a digest of the posts of your subscriptions, grouped by list",
+		Long: `Write a digest of the posts of your subscriptions published in a window,
+grouped by list, with their summaries and links, as Markdown or HTML.
+
+The format follows the extension of -o, or --format. The digest comes from a
+Go template, which --template replaces; see the README for its data.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+
+			var filter postFilter
+			var err error
+			if filter.since, err = config.ParseSince(since); err != nil {
+				slog.Error("invalid --since", "error", err)
+				return
+			}
+			if filter.until, err = config.ParseDate(until, true); err != nil {
+				slog.Error("invalid --until", "error", err)
+				return
+			}
+			f := digest.FormatOf(file)
+			switch format {
+			case "":
+			case string(digest.Markdown), "md":
+				f = digest.Markdown
+			case string(digest.HTML):
+				f = digest.HTML
+			default:
+				slog.Error("invalid --format", "error", fmt.Sprintf("%q is not markdown or html", format))
+				return
+			}
+
+			posts, err := selectPosts(cl.GetSubscribedPosts, filter, limit)
+			if err != nil {
+				slog.Error("failed to get subscribed posts", "error", err)
+				return
+			}
+			if len(posts) == limit {
+				slog.Warn("the digest has the latest posts only, raise --limit to include more", "limit", limit)
+			}
+
+			end := filter.until
+			if end.IsZero() {
+				end = time.Now()
+			}
+			if title == "" {
+				title = fmt.Sprintf("Quaily digest %s", end.Local().Format("2006-01-02"))
+			}
+			d := digest.New(title, filter.since, end, posts, paragraphs, func(p client.Post) string {
+				resp, err := cl.GetPostContent(strconv.FormatUint(p.ListID, 10), strconv.FormatUint(p.ID, 10))
+				if err != nil {
+					slog.Warn("failed to get post content for the excerpt", "post", p.ID, "reason", readableContentError(err))
+					return ""
+				}
+				return resp.Data.FreeContent
+			})
+
+			var buf bytes.Buffer
+			if err := digest.Render(&buf, d, f, templatePath); err != nil {
+				slog.Error("failed to render digest", "error", err)
+				return
+			}
+			if file == "" || file == "-" {
+				os.Stdout.Write(buf.Bytes())
+				return
+			}
+			if err := writeFileAtomic(file, buf.Bytes()); err != nil {
+				slog.Error("failed to write digest", "error", err)
+				return
+			}
+			fmt.Printf("digest of %d post(s) written to %s\n", d.Count, file)
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "7d", "Posts published in this long, like 24h or 7d, or since a date like 2026-01-31")
+	cmd.Flags().StringVar(&until, "until", "", "Only posts published on or before this date")
+	cmd.Flags().StringVarP(&file, "out", "o", "", "Write to this file instead of stdout, a .html file is written as HTML")
+	cmd.Flags().StringVar(&format, "format", "", "Digest format: markdown|html, by default from the -o extension")
+	cmd.Flags().IntVar(&paragraphs, "paragraphs", 0, "Include the first N paragraphs of each post")
+	cmd.Flags().StringVar(&templatePath, "template", "", "Go template file to render the digest with")
+	cmd.Flags().StringVar(&title, "title", "", "Digest title")
+	cmd.Flags().IntVar(&limit, "limit", 200, "Maximum number of posts")
+	return cmd
+}
@@ -0,0 +1,289 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/epub"
+	"github.com/quailyquaily/quail-cli/quailyurl"
+	"github.com/quailyquaily/quail-cli/readstate"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exportPageSize = 20
+	// maxExportPages bounds the pages read looking for posts in a date range.
+	maxExportPages = 50
+)
+
+// postFilter selects the posts to export.
+type postFilter struct {
+	since, until time.Time
+	unread       *readstate.State
+}
+
+func (f postFilter) keep(p client.Post) bool {
+	if !f.since.IsZero() && p.PublishedAt.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && p.PublishedAt.After(f.until) {
+		return false
+	}
+	return f.unread == nil || !f.unread.IsRead(p.ID)
+}
+
+func newExportCmd() *cobra.Command {
+	var file string
+	var list string
+	var since, until string
+	var unread bool
+	var limit int
+	var title string
+	var lang string
+	var noImages bool
+
+	cmd := &cobra.Command{
+		Use:   "export --epub <file> [post-url...]",
+		Short: "Bundle posts into an EPUB book for e-readers",
+		Long: `Bundle posts into an EPUB 3 book, with a table of contents and the images
+of the posts, for e-readers like Kindle and Kobo.
+
+Posts are the ones given as URLs, or else the latest posts of --list, or else
+of your subscriptions, filtered by --since, --until and --unread. --list takes
+a list you subscribe to or one of your own lists, to export it as a book.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			if file == "" {
+				slog.Error("--epub is required")
+				return
+			}
+
+			var filter postFilter
+			var err error
+			if filter.since, err = config.ParseDate(since, false); err != nil {
+				slog.Error("invalid --since", "error", err)
+				return
+			}
+			if filter.until, err = config.ParseDate(until, true); err != nil {
+				slog.Error("invalid --until", "error", err)
+				return
+			}
+			if unread {
+				if filter.unread, err = readstate.Default().Load(); err != nil {
+					slog.Error("failed to load read state", "error", err)
+					return
+				}
+			}
+
+			book := &epub.Book{Title: title, Language: lang, Modified: time.Now()}
+			var posts []client.Post
+			switch {
+			case len(args) > 0:
+				posts, err = postsByURL(cl, args, filter)
+			case list != "":
+				var l *client.List
+				if l, err = resolveList(cl, list); err == nil {
+					posts, err = selectPosts(func(offset, limit int) (*client.SearchResponse, error) {
+						return cl.GetListPosts(l.ID, offset, limit)
+					}, filter, limit)
+					book.Author, book.Description = l.Title, l.Description
+					if book.Title == "" {
+						book.Title = l.Title
+					}
+				}
+			default:
+				posts, err = selectPosts(cl.GetSubscribedPosts, filter, limit)
+			}
+			if err != nil {
+				slog.Error("failed to get posts", "error", err)
+				return
+			}
+			if len(posts) == 0 {
+				slog.Error("no posts to export")
+				return
+			}
+			if len(args) == 0 {
+				sort.SliceStable(posts, func(i, j int) bool { return posts[i].PublishedAt.Before(posts[j].PublishedAt) })
+			}
+
+			if book.Title == "" {
+				book.Title = fmt.Sprintf("Quaily posts %s", time.Now().Format("2006-01-02"))
+			}
+			if book.Author == "" {
+				book.Author = commonListTitle(posts)
+			}
+			ids := make([]string, len(posts))
+			for i, p := range posts {
+				ids[i] = strconv.FormatUint(p.ID, 10)
+			}
+			book.ID = epub.NameUUID("quaily:" + strings.Join(ids, ","))
+			if !noImages {
+				book.Fetch = epub.HTTPFetcher()
+			}
+			book.OnImageError = func(url string, err error) {
+				slog.Warn("failed to embed image, using its alt text", "url", url, "error", err)
+			}
+
+			for _, p := range posts {
+				src, err := exportContent(cl, p)
+				if err != nil {
+					slog.Error("failed to get post content", "post", p.ID, "error", err)
+					return
+				}
+				book.AddChapter(p.Title, byline(p), src)
+			}
+
+			var buf bytes.Buffer
+			if err := book.Write(&buf); err != nil {
+				slog.Error("failed to write epub", "error", err)
+				return
+			}
+			if err := writeFileAtomic(file, buf.Bytes()); err != nil {
+				slog.Error("failed to write epub", "error", err)
+				return
+			}
+			fmt.Printf("exported %d post(s) to %s\n", book.Len(), file)
+		},
+	}
+	cmd.Flags().StringVar(&file, "epub", "", "Write an EPUB book to this file")
+	cmd.Flags().StringVar(&list, "list", "", "Export posts of this list id or slug, instead of all subscriptions")
+	cmd.Flags().StringVar(&since, "since", "", "Only posts published on or after this date, like 2026-01-31")
+	cmd.Flags().StringVar(&until, "until", "", "Only posts published on or before this date")
+	cmd.Flags().BoolVar(&unread, "unread", false, "Only posts you haven't read")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of posts")
+	cmd.Flags().StringVar(&title, "title", "", "Book title, the list title by default")
+	cmd.Flags().StringVar(&lang, "lang", "en", "Book language, like en or zh")
+	cmd.Flags().BoolVar(&noImages, "no-images", false, "Don't download and embed images")
+	return cmd
+}
+
+// selectPosts reads pages of posts, newest first, until limit posts are
+// kept or the posts are older than the filter.
+func selectPosts(page func(offset, limit int) (*client.SearchResponse, error), filter postFilter, limit int) ([]client.Post, error) {
+	var ret []client.Post
+	for i := 0; i < maxExportPages && len(ret) < limit; i++ {
+		resp, err := page(i*exportPageSize, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Data.Items {
+			if !filter.since.IsZero() && p.PublishedAt.Before(filter.since) {
+				return ret, nil
+			}
+			if filter.keep(p) && len(ret) < limit {
+				ret = append(ret, p)
+			}
+		}
+		if len(resp.Data.Items) < exportPageSize {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func postsByURL(cl *client.Client, urls []string, filter postFilter) ([]client.Post, error) {
+	var ret []client.Post
+	resolver := quailyurl.Default()
+	for _, u := range urls {
+		ref, err := resolver.ParsePost(u)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := cl.GetPost(ref.List, ref.Post)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", u, err)
+		}
+		if filter.keep(resp.Data) {
+			ret = append(ret, resp.Data)
+		}
+	}
+	return ret, nil
+}
+
+// resolveList finds a list by ID, or by slug among the lists of the user and
+// their subscriptions.
+func resolveList(cl *client.Client, idOrSlug string) (*client.List, error) {
+	if id, err := strconv.ParseUint(idOrSlug, 10, 64); err == nil {
+		resp, err := cl.GetList(id)
+		if err != nil {
+			return nil, err
+		}
+		return &resp.Data, nil
+	}
+
+	me, err := cl.GetMe()
+	if err != nil {
+		return nil, err
+	}
+	lists, err := cl.GetUserLists(me.Data.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		if lists[i].Slug == idOrSlug {
+			return &lists[i], nil
+		}
+	}
+	subs, err := cl.GetSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range subs.Data {
+		if s.List != nil && s.List.Slug == idOrSlug {
+			return s.List, nil
+		}
+	}
+	return nil, fmt.Errorf("no list %q among your lists and subscriptions", idOrSlug)
+}
+
+// exportContent returns the Markdown of a post, paid part included. Posts
+// the user can't read have their summary instead.
+func exportContent(cl *client.Client, p client.Post) (string, error) {
+	resp, err := cl.GetPostContent(strconv.FormatUint(p.ListID, 10), strconv.FormatUint(p.ID, 10))
+	if client.IsNetworkError(err) {
+		return "", err
+	}
+	if err != nil {
+		slog.Warn("the book has the summary of a post only", "post", p.ID, "reason", readableContentError(err))
+		return fmt.Sprintf("%s\n\n*%s. Read it on %s.*\n", p.Summary, readableContentError(err), p.URL()), nil
+	}
+	src := resp.Data.FreeContent
+	if resp.Data.PaidContent != "" {
+		src += "\n\n---\n\n" + resp.Data.PaidContent
+	}
+	return src, nil
+}
+
+func byline(p client.Post) string {
+	parts := []string{}
+	if p.List.Title != "" {
+		parts = append(parts, p.List.Title)
+	}
+	if !p.PublishedAt.IsZero() {
+		parts = append(parts, p.PublishedAt.Local().Format("2006-01-02"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// commonListTitle is the title of the list of all posts, or Quaily when they
+// come from several lists.
+func commonListTitle(posts []client.Post) string {
+	title := posts[0].List.Title
+	for _, p := range posts {
+		if p.List.Title != title {
+			return "Quaily"
+		}
+	}
+	if title == "" {
+		return "Quaily"
+	}
+	return title
+}
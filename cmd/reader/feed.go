@@ -0,0 +1,223 @@
+package reader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/feed"
+	"github.com/spf13/cobra"
+)
+
+func newFeedCmd() *cobra.Command {
+	var format string
+	var limit int
+	var file string
+	var addr string
+	var refresh time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "feed",
+		Short: "Export subscribed posts, paid ones included, as an Atom, RSS or JSON Feed",
+		Long: `Export subscribed posts, with the content you have access to, as an Atom,
+RSS or JSON Feed for your feed reader.
+
+The feed is written to stdout or --file. With --serve, it is served over HTTP
+instead and rebuilt every --refresh. Paid content is in the feed, so serve it
+on localhost only, the default host.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			f, err := feed.ParseFormat(format)
+			if err != nil {
+				slog.Error("invalid --format", "error", err)
+				return
+			}
+
+			if addr != "" {
+				if refresh <= 0 {
+					slog.Error("invalid --refresh", "error", "must be positive")
+					return
+				}
+				if err := serveFeed(cmd.Context(), cl, f, limit, addr, refresh); err != nil {
+					slog.Error("failed to serve feed", "error", err)
+				}
+				return
+			}
+
+			data, err := buildFeed(cl, f, limit, "")
+			if err != nil {
+				slog.Error("failed to build feed", "error", err)
+				return
+			}
+			if file == "" || file == "-" {
+				os.Stdout.Write(data)
+				return
+			}
+			if err := writeFileAtomic(file, data); err != nil {
+				slog.Error("failed to write feed", "error", err)
+				return
+			}
+			fmt.Printf("feed written to %s\n", file)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", string(feed.Atom), "Feed format: "+strings.Join(feed.Formats, "|"))
+	cmd.Flags().IntVar(&limit, "limit", 50, "Number of latest posts in the feed")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write to this file instead of stdout")
+	cmd.Flags().StringVar(&addr, "serve", "", "Serve the feed over HTTP on this address, like :8080 for localhost:8080")
+	cmd.Flags().DurationVar(&refresh, "refresh", 30*time.Minute, "How often to rebuild a served feed")
+	return cmd
+}
+
+// buildFeed gets the latest subscribed posts and their content, and encodes
+// them in the format. selfURL is where the feed is served, if it is.
+func buildFeed(cl *client.Client, format feed.Format, limit int, selfURL string) ([]byte, error) {
+	me, err := cl.GetMe()
+	if err != nil {
+		return nil, err
+	}
+	posts, err := latestPosts(cl, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &feed.Feed{
+		ID:          "tag:quaily.com,2020:subscriptions/" + strconv.FormatUint(me.Data.ID, 10),
+		Title:       "Quaily subscriptions",
+		Description: fmt.Sprintf("Posts from the subscriptions of %s on Quaily", me.Data.Name),
+		Link:        "https://quaily.com",
+		SelfURL:     selfURL,
+	}
+	for _, p := range posts {
+		var content *client.PostContent
+		resp, err := cl.GetPostContent(strconv.FormatUint(p.ListID, 10), strconv.FormatUint(p.ID, 10))
+		switch {
+		case err == nil:
+			content = &resp.Data
+		case client.IsNetworkError(err):
+			return nil, err
+		default:
+			slog.Warn("the feed has the summary of a post only", "post", p.ID, "reason", readableContentError(err))
+		}
+		item := feed.PostItem(p, content)
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+
+	var buf bytes.Buffer
+	if err := feed.Write(&buf, f, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// feedServer serves the last feed built.
+type feedServer struct {
+	contentType string
+	mu          sync.RWMutex
+	data        []byte
+	built       time.Time
+}
+
+func (s *feedServer) set(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data, s.built = data, time.Now()
+}
+
+func (s *feedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data, built := s.data, s.built
+	s.mu.RUnlock()
+	w.Header().Set("Content-Type", s.contentType)
+	http.ServeContent(w, r, "", built, bytes.NewReader(data))
+}
+
+// serveFeed serves the feed on addr until interrupted, rebuilding it every
+// refresh. A failed rebuild keeps the previous feed.
+func serveFeed(ctx context.Context, cl *client.Client, format feed.Format, limit int, addr string, refresh time.Duration) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	addr = net.JoinHostPort(host, port)
+	selfURL := "http://" + addr + "/"
+
+	data, err := buildFeed(cl, format, limit, selfURL)
+	if err != nil {
+		return err
+	}
+	s := &feedServer{contentType: format.ContentType()}
+	s.set(data)
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				srv.Close()
+				return
+			case <-ticker.C:
+				data, err := buildFeed(cl, format, limit, selfURL)
+				if err != nil {
+					slog.Warn("failed to refresh feed, serving the previous one", "error", err)
+					continue
+				}
+				s.set(data)
+			}
+		}
+	}()
+
+	fmt.Printf("serving the %s feed on %s, press Ctrl-C to stop\n", format, selfURL)
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so that feed readers never see a partial feed. The file is only readable by
+// the user, as it may have paid content.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
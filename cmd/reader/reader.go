@@ -2,17 +2,25 @@ package reader
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
-	"net/url"
 	"os"
-	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/quailyquaily/quail-cli/client"
 	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/markdown"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/quailyurl"
+	"github.com/quailyquaily/quail-cli/readercache"
+	"github.com/quailyquaily/quail-cli/readstate"
+	"github.com/quailyquaily/quail-cli/tui"
+	"github.com/quailyquaily/quail-cli/tui/readerapp"
+	"github.com/quailyquaily/quail-cli/util"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 func NewCmd() *cobra.Command {
@@ -26,111 +34,155 @@ func NewCmd() *cobra.Command {
 	cmd.AddCommand(newReadCmd())
 	cmd.AddCommand(newCommentsCmd())
 	cmd.AddCommand(newCommentCmd())
+	cmd.AddCommand(newTUICmd())
+	cmd.AddCommand(newMarkCmd(true))
+	cmd.AddCommand(newMarkCmd(false))
+	cmd.AddCommand(newStateCmd())
+	cmd.AddCommand(newSyncCmd())
+	cmd.AddCommand(newFeedCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(newDigestCmd())
+
+	cmd.PersistentFlags().Bool("offline", false, "Read posts and comments from the cache of reader sync, without the API")
 
 	return cmd
 }
 
 func newSubscriptionsCmd() *cobra.Command {
-	return &cobra.Command{
+	var unread bool
+
+	cmd := &cobra.Command{
 		Use:   "subscriptions",
 		Short: "List your subscriptions",
 		Run: func(cmd *cobra.Command, args []string) {
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
 
 			resp, err := cl.GetSubscriptions()
 			if err != nil {
 				slog.Error("failed to get subscriptions", "error", err)
 				return
 			}
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(resp)
+			if !unread {
+				render(out, resp.Data)
 				return
 			}
-			printSubscriptions(resp.Data)
+			state, err := readstate.Default().Load()
+			if err != nil {
+				slog.Error("failed to load read state", "error", err)
+				return
+			}
+			subs := make([]subscription, len(resp.Data))
+			for i, s := range resp.Data {
+				subs[i] = subscription{Subscription: s}
+			}
+			countUnread(cl, state, subs)
+			render(out, subs)
 		},
 	}
+	cmd.Flags().BoolVar(&unread, "unread", false, "Count unread posts of each subscription, which takes a request per subscription")
+	return cmd
 }
 
 func newPostsCmd() *cobra.Command {
 	var offset int
 	var limit int
+	var unread bool
 
 	cmd := &cobra.Command{
 		Use:   "posts",
 		Short: "List posts from your subscriptions",
 		Run: func(cmd *cobra.Command, args []string) {
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
 
-			resp, err := cl.GetSubscribedPosts(offset, limit)
+			if limit <= 0 {
+				limit = 20
+			}
+			var posts []client.Post
+			var err error
+			if !offline(cmd, nil) {
+				posts, err = subscribedPosts(cl, offset, limit, unread)
+			}
+			if offline(cmd, err) {
+				posts, err = cachedPosts(offset, limit, unread)
+			}
 			if err != nil {
 				slog.Error("failed to get subscribed posts", "error", err)
 				return
 			}
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(resp)
-				return
-			}
-			printPosts(resp.Data.Items)
+			render(out, posts)
 		},
 	}
 	cmd.Flags().IntVar(&offset, "offset", 0, "Post list offset")
 	cmd.Flags().IntVar(&limit, "limit", 20, "Post list limit")
+	cmd.Flags().BoolVar(&unread, "unread", false, "Only list posts you haven't read")
 	return cmd
 }
 
+// maxReadWidth keeps lines of posts readable on wide terminals.
+const maxReadWidth = 100
+
 func newReadCmd() *cobra.Command {
 	var list string
 	var post string
+	var raw bool
 
 	cmd := &cobra.Command{
 		Use:   "read [url]",
 		Short: "Read a post",
 		Run: func(cmd *cobra.Command, args []string) {
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
 
 			listIDOrSlug := list
 			postIDOrSlug := post
 			if len(args) > 0 {
-				var err error
-				listIDOrSlug, postIDOrSlug, err = parsePostURL(args[0])
+				ref, err := quailyurl.Default().ParsePost(args[0])
 				if err != nil {
 					slog.Error("failed to parse post url", "error", err)
 					return
 				}
+				listIDOrSlug, postIDOrSlug = ref.List, ref.Post
 			}
 			if listIDOrSlug == "" || postIDOrSlug == "" {
 				cmd.Help()
 				return
 			}
 
-			postResp, err := cl.GetPost(listIDOrSlug, postIDOrSlug)
+			ret := readResult{
+				raw:   raw,
+				width: min(util.TerminalWidth(80), maxReadWidth),
+				color: util.UseColor(),
+			}
+			var err error
+			if !offline(cmd, nil) {
+				err = ret.fetch(cl, listIDOrSlug, postIDOrSlug)
+			}
+			if offline(cmd, err) {
+				err = ret.fromCache(listIDOrSlug, postIDOrSlug)
+			}
 			if err != nil {
 				slog.Error("failed to get post", "error", err)
 				return
 			}
+			recordRead(ret.Post.ID)
 
-			contentResp, contentErr := cl.GetPostContent(listIDOrSlug, postIDOrSlug)
-			if format == common.FORMAT_JSON {
-				ret := map[string]any{
-					"post": postResp.Data,
-				}
-				if contentErr != nil {
-					ret["content_error"] = readableContentError(contentErr)
-				} else {
-					ret["content"] = contentResp.Data
-				}
-				client.PrettyPrintJSON(ret)
+			if raw || out.Format.Kind != output.Table {
+				render(out, ret)
 				return
 			}
 
-			printPostWithContent(postResp.Data, contentResp, contentErr)
+			pager, wait := util.StartPager()
+			defer wait()
+			paged := *out
+			paged.Out = pager
+			render(&paged, ret)
 		},
 	}
 	cmd.Flags().StringVar(&list, "list", "", "List id or slug")
 	cmd.Flags().StringVar(&post, "post", "", "Post id or slug")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the Markdown of the post as is, without a pager")
 	return cmd
 }
 
@@ -149,17 +201,26 @@ func newCommentsCmd() *cobra.Command {
 			}
 
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
-			resp, err := cl.GetCommentsByPost(postID, offset, limit)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			if limit <= 0 {
+				limit = 20
+			}
+			var comments []client.Comment
+			var err error
+			if !offline(cmd, nil) {
+				var resp *client.CommentsResponse
+				if resp, err = cl.GetCommentsByPost(postID, offset, limit); err == nil {
+					comments = resp.Data.Items
+				}
+			}
+			if offline(cmd, err) {
+				comments, err = cachedComments(postID, offset, limit)
+			}
 			if err != nil {
 				slog.Error("failed to get comments", "error", err)
 				return
 			}
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(resp)
-				return
-			}
-			printComments(resp.Data.Items)
+			renderComments(out, comments)
 		},
 	}
 	cmd.Flags().Uint64Var(&postID, "post", 0, "Post id")
@@ -171,132 +232,148 @@ func newCommentsCmd() *cobra.Command {
 func newCommentCmd() *cobra.Command {
 	var postID uint64
 	var content string
+	var replyTo uint64
 
 	cmd := &cobra.Command{
 		Use:   "comment",
-		Short: "Create a comment for a post",
+		Short: "Create a comment for a post, or reply to a comment",
+		Long: `Create a comment for a post, or reply to a comment with --reply-to.
+
+The comment is --content, or stdin when it isn't a terminal or with
+--content -. Otherwise $EDITOR opens to write it, over several lines.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			content = strings.TrimSpace(content)
-			if postID == 0 || content == "" {
+			if postID == 0 {
 				cmd.Help()
 				return
 			}
 
 			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
-			format := cmd.Context().Value(common.CTX_FORMAT{}).(string)
-			resp, err := cl.CreateComment(postID, content)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			text, err := commentContent(cl, content, cmd.Flags().Changed("content"), postID, replyTo)
 			if err != nil {
-				slog.Error("failed to create comment", "error", err)
+				slog.Error("failed to read comment", "error", err)
 				return
 			}
-			if format == common.FORMAT_JSON {
-				client.PrettyPrintJSON(resp)
+			if text == "" {
+				slog.Error("empty comment, nothing posted")
+				return
+			}
+
+			resp, err := cl.ReplyComment(postID, replyTo, text)
+			if err != nil {
+				slog.Error("failed to create comment", "error", err)
 				return
 			}
-			printComments([]client.Comment{resp.Data})
+			renderComments(out, []client.Comment{resp.Data})
 		},
 	}
 	cmd.Flags().Uint64Var(&postID, "post", 0, "Post id")
-	cmd.Flags().StringVar(&content, "content", "", "Comment content")
+	cmd.Flags().StringVar(&content, "content", "", "Comment content, - to read it from stdin")
+	cmd.Flags().Uint64Var(&replyTo, "reply-to", 0, "Id of the comment to reply to")
 	return cmd
 }
 
-func parsePostURL(raw string) (string, string, error) {
-	u, err := url.Parse(strings.TrimSpace(raw))
-	if err != nil {
-		return "", "", err
-	}
-	if u.Scheme != "https" || u.Host != "quaily.com" {
-		return "", "", fmt.Errorf("only https://quaily.com/{list}/{post} URLs are supported")
-	}
+func newTUICmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tui",
+		Short: "Browse subscriptions, posts and comments in a full-screen interface",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !util.StdoutIsTerminal() || !term.IsTerminal(int(os.Stdin.Fd())) {
+				slog.Error("reader tui needs a terminal")
+				return
+			}
 
-	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
-	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
-		return "", "", fmt.Errorf("invalid quaily post url")
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			if _, err := tui.NewProgram(os.Stdin, os.Stdout).Run(readerapp.New(cl)); err != nil {
+				slog.Error("failed to run reader tui", "error", err)
+			}
+		},
 	}
-	return parts[0], parts[1], nil
 }
 
-func printSubscriptions(items []client.Subscription) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "ID\tLIST\tSLUG\tTYPE\tEMAIL\tPAID_EXPIRY")
-	for _, item := range items {
-		listTitle := ""
-		listSlug := strconv.FormatUint(item.ListID, 10)
-		if item.List != nil {
-			listTitle = item.List.Title
-			listSlug = item.List.Slug
+// readResult is a post together with its content, or the reason the content
+// couldn't be read.
+type readResult struct {
+	Post         client.Post         `json:"post"`
+	Content      *client.PostContent `json:"content,omitempty"`
+	ContentError string              `json:"content_error,omitempty"`
+	// CachedAt is set when the post was read from the offline cache.
+	CachedAt *time.Time `json:"cached_at,omitempty"`
+
+	// how the content is printed in table output
+	raw   bool
+	width int
+	color bool
+}
+
+func (r readResult) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 1, 1, 1, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%d\n", r.Post.ID)
+	fmt.Fprintf(w, "List:\t%d\n", r.Post.ListID)
+	fmt.Fprintf(w, "Slug:\t%s\n", r.Post.Slug)
+	fmt.Fprintf(w, "Title:\t%s\n", r.Post.Title)
+	fmt.Fprintf(w, "Summary:\t%s\n", r.Post.Summary)
+	fmt.Fprintf(w, "Published At:\t%s\n", output.FormatValue(r.Post.PublishedAt))
+	if r.CachedAt != nil {
+		fmt.Fprintf(w, "Cached At:\t%s (offline copy, may be stale)\n", output.FormatValue(r.CachedAt))
+	}
+	if r.Content == nil {
+		fmt.Fprintf(w, "Content:\t%s\n", r.ContentError)
+		return w.Flush()
+	}
+	if r.raw {
+		fmt.Fprintf(w, "Content:\n%s\n", r.Content.FreeContent)
+		if r.Content.PaidContent != "" {
+			fmt.Fprintf(w, "\nPaid Content:\n%s\n", r.Content.PaidContent)
 		}
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\n",
-			item.ID,
-			listTitle,
-			listSlug,
-			item.Type,
-			item.EmailEnabled,
-			formatTimePtr(item.PaidExpiry),
-		)
+		return w.Flush()
+	}
+	if err := w.Flush(); err != nil {
+		return err
 	}
-	w.Flush()
+
+	opts := markdown.TerminalOptions{Width: r.width, Color: r.color}
+	fmt.Fprintln(out)
+	if err := markdown.RenderTerminal(out, markdown.Parse(r.Content.FreeContent), opts); err != nil {
+		return err
+	}
+	if r.Content.PaidContent != "" {
+		fmt.Fprintf(out, "\n── Paid Content ──\n\n")
+		return markdown.RenderTerminal(out, markdown.Parse(r.Content.PaidContent), opts)
+	}
+	return nil
 }
 
-func printPosts(items []client.Post) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "ID\tLIST\tSLUG\tTITLE\tPUBLISHED_AT\tPAID")
-	for _, item := range items {
-		listSlug := item.List.Slug
-		if listSlug == "" {
-			listSlug = strconv.FormatUint(item.ListID, 10)
-		}
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%t\n",
-			item.ID,
-			listSlug,
-			item.Slug,
-			item.Title,
-			formatTime(item.PublishedAt),
-			item.IsPaidContent,
-		)
+func render(out *output.Renderer, v any) {
+	if err := out.Render(v); err != nil {
+		slog.Error("failed to render output", "error", err)
 	}
-	w.Flush()
 }
 
-func printPostWithContent(post client.Post, content *client.PostContentResponse, contentErr error) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintf(w, "ID:\t%d\n", post.ID)
-	fmt.Fprintf(w, "List:\t%d\n", post.ListID)
-	fmt.Fprintf(w, "Slug:\t%s\n", post.Slug)
-	fmt.Fprintf(w, "Title:\t%s\n", post.Title)
-	fmt.Fprintf(w, "Summary:\t%s\n", post.Summary)
-	fmt.Fprintf(w, "Published At:\t%s\n", formatTime(post.PublishedAt))
-	if contentErr != nil {
-		fmt.Fprintf(w, "Content:\t%s\n", readableContentError(contentErr))
-		w.Flush()
-		return
+func (r *readResult) fetch(cl *client.Client, listIDOrSlug, postIDOrSlug string) error {
+	postResp, err := cl.GetPost(listIDOrSlug, postIDOrSlug)
+	if err != nil {
+		return err
 	}
-	fmt.Fprintf(w, "Content:\n%s\n", content.Data.FreeContent)
-	if content.Data.PaidContent != "" {
-		fmt.Fprintf(w, "\nPaid Content:\n%s\n", content.Data.PaidContent)
+	r.Post = postResp.Data
+	contentResp, err := cl.GetPostContent(listIDOrSlug, postIDOrSlug)
+	if err != nil {
+		r.ContentError = readableContentError(err)
+	} else {
+		r.Content = &contentResp.Data
 	}
-	w.Flush()
+	return nil
 }
 
-func printComments(items []client.Comment) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "ID\tPOST\tAUTHOR\tSTATUS\tCREATED_AT\tCONTENT")
-	for _, item := range items {
-		author := strconv.FormatUint(item.AuthorID, 10)
-		if item.Author != nil && item.Author.Name != "" {
-			author = item.Author.Name
-		}
-		fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%s\t%s\n",
-			item.ID,
-			item.PostID,
-			author,
-			item.Status,
-			formatTime(item.CreatedAt),
-			strings.ReplaceAll(item.Content, "\n", " "),
-		)
+func (r *readResult) fromCache(listIDOrSlug, postIDOrSlug string) error {
+	entry, err := readercache.Default().Find(listIDOrSlug, postIDOrSlug)
+	if err != nil {
+		return err
 	}
-	w.Flush()
+	warnStale(entry.FetchedAt)
+	r.Post, r.Content, r.ContentError = entry.Post, entry.Content, entry.ContentError
+	r.CachedAt = &entry.FetchedAt
+	return nil
 }
 
 func readableContentError(err error) string {
@@ -308,17 +385,3 @@ func readableContentError(err error) string {
 	}
 	return err.Error()
 }
-
-func formatTime(t time.Time) string {
-	if t.IsZero() {
-		return ""
-	}
-	return t.Format(time.RFC3339)
-}
-
-func formatTimePtr(t *time.Time) string {
-	if t == nil || t.IsZero() {
-		return ""
-	}
-	return t.Format(time.RFC3339)
-}
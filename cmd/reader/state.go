@@ -0,0 +1,260 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/quailyurl"
+	"github.com/quailyquaily/quail-cli/readstate"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// unreadWindow is how many of the latest posts of a subscription are
+	// checked for unread counts.
+	unreadWindow = 50
+	// unreadConcurrency is how many subscriptions are counted at once.
+	unreadConcurrency = 4
+	// maxUnreadPages bounds how many pages `posts --unread` goes through to
+	// fill a page of unread posts.
+	maxUnreadPages = 10
+)
+
+// subscription is a subscription with the number of its unread posts.
+type subscription struct {
+	client.Subscription
+	Unread *int `json:"unread,omitempty"`
+}
+
+func init() {
+	base, _ := output.ColumnsOf(reflect.TypeOf(client.Subscription{}), true)
+	var columns []output.Column
+	for _, c := range base {
+		value := c.Value
+		c.Value = func(v any) any { return value(v.(subscription).Subscription) }
+		columns = append(columns, c)
+		if c.Name == "type" {
+			columns = append(columns, output.Col("unread", func(s subscription) any {
+				if s.Unread == nil {
+					return nil
+				}
+				return *s.Unread
+			}))
+		}
+	}
+	output.Register[subscription](columns...)
+}
+
+// countUnread sets the unread counts of the subscriptions, among their
+// latest unreadWindow posts, reading unreadConcurrency lists at a time.
+func countUnread(cl *client.Client, state *readstate.State, subs []subscription) {
+	sem := make(chan struct{}, unreadConcurrency)
+	var wg sync.WaitGroup
+	for i := range subs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := cl.GetListPosts(subs[i].ListID, 0, unreadWindow)
+			if err != nil {
+				slog.Warn("failed to count unread posts", "list", subs[i].ListID, "error", err)
+				return
+			}
+			n := 0
+			for _, p := range resp.Data.Items {
+				if !state.IsRead(p.ID) {
+					n++
+				}
+			}
+			subs[i].Unread = &n
+		}()
+	}
+	wg.Wait()
+}
+
+// subscribedPosts returns a page of subscribed posts, or of the unread ones.
+func subscribedPosts(cl *client.Client, offset, limit int, unread bool) ([]client.Post, error) {
+	if !unread {
+		resp, err := cl.GetSubscribedPosts(offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data.Items, nil
+	}
+	state, err := readstate.Default().Load()
+	if err != nil {
+		return nil, err
+	}
+	return unreadPosts(cl, state, offset, limit)
+}
+
+// unreadPosts returns up to limit unread posts, skipping offset unread
+// posts. The feed is read from its start, more pages while pages have read
+// posts, up to maxUnreadPages for each page of unread posts asked for.
+func unreadPosts(cl *client.Client, state *readstate.State, offset, limit int) ([]client.Post, error) {
+	offset, limit = max(offset, 0), max(limit, 1)
+	pages := maxUnreadPages * (offset/limit + 1)
+	var ret []client.Post
+	for page := 0; page < pages && len(ret) < limit; page++ {
+		resp, err := cl.GetSubscribedPosts(page*limit, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Data.Items {
+			if state.IsRead(p.ID) || len(ret) >= limit {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			ret = append(ret, p)
+		}
+		if len(resp.Data.Items) < limit {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func newMarkCmd(read bool) *cobra.Command {
+	use, short := "mark-read", "Mark posts as read"
+	if !read {
+		use, short = "mark-unread", "Mark posts as unread"
+	}
+
+	return &cobra.Command{
+		Use:   use + " <post-id|url>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+
+			ids := make([]uint64, 0, len(args))
+			for _, arg := range args {
+				id, err := resolvePostID(cl, arg)
+				if err != nil {
+					slog.Error("failed to resolve post", "post", arg, "error", err)
+					return
+				}
+				ids = append(ids, id)
+			}
+
+			store := readstate.Default()
+			var err error
+			if read {
+				err = store.MarkRead(ids...)
+			} else {
+				err = store.MarkUnread(ids...)
+			}
+			if err != nil {
+				slog.Error("failed to update read state", "error", err)
+				return
+			}
+			fmt.Printf("marked %d post(s) as %s\n", len(ids), strings.TrimPrefix(use, "mark-"))
+		},
+	}
+}
+
+// resolvePostID returns the ID of a post given as an ID or a quaily URL.
+func resolvePostID(cl *client.Client, arg string) (uint64, error) {
+	if id, err := strconv.ParseUint(arg, 10, 64); err == nil {
+		return id, nil
+	}
+	ref, err := quailyurl.Default().ParsePost(arg)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := cl.GetPost(ref.List, ref.Post)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Data.ID, nil
+}
+
+// recordRead marks a post opened with `reader read` as read. Failing to do
+// so doesn't fail the command.
+func recordRead(postID uint64) {
+	if err := readstate.Default().MarkRead(postID); err != nil {
+		slog.Warn("failed to record the post as read", "error", err)
+	}
+}
+
+func newStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export or import the read state, to move it between machines",
+	}
+	cmd.AddCommand(newStateExportCmd())
+	cmd.AddCommand(newStateImportCmd())
+	return cmd
+}
+
+func newStateExportCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the read state as JSON",
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := readstate.Default().Load()
+			if err != nil {
+				slog.Error("failed to load read state", "error", err)
+				return
+			}
+
+			var w io.Writer = os.Stdout
+			if file != "" && file != "-" {
+				f, err := os.Create(file)
+				if err != nil {
+					slog.Error("failed to create export file", "error", err)
+					return
+				}
+				defer f.Close()
+				w = f
+			}
+			if err := readstate.Encode(w, state); err != nil {
+				slog.Error("failed to export read state", "error", err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write to this file instead of stdout")
+	return cmd
+}
+
+func newStateImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Merge an exported read state, keeping the newer change of each post",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					slog.Error("failed to open read state", "error", err)
+					return
+				}
+				defer f.Close()
+				r = f
+			}
+
+			n, err := readstate.Default().Import(r)
+			if err != nil {
+				slog.Error("failed to import read state", "error", err)
+				return
+			}
+			fmt.Printf("imported read state, %d post(s) changed\n", n)
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/readercache"
+	"github.com/quailyquaily/quail-cli/readstate"
+	"github.com/spf13/cobra"
+)
+
+const (
+	syncPageSize = 20
+	// syncCommentLimit is how many comments of each post are cached.
+	syncCommentLimit = 100
+)
+
+type syncResult struct {
+	Synced  int    `json:"synced"`
+	Failed  int    `json:"failed"`
+	Evicted int    `json:"evicted"`
+	Dir     string `json:"dir"`
+}
+
+func (r syncResult) PrintTable(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "synced %d post(s), %d failed, %d evicted from %s\n", r.Synced, r.Failed, r.Evicted, r.Dir)
+	return err
+}
+
+func newSyncCmd() *cobra.Command {
+	var limit int
+	var noComments bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Cache the latest subscribed posts, with content and comments, for reading offline",
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			cfg := config.Current()
+			cache := readercache.Default()
+
+			posts, err := latestPosts(cl, limit)
+			if err != nil {
+				slog.Error("failed to get subscribed posts", "error", err)
+				return
+			}
+
+			ret := syncResult{Dir: cache.Dir()}
+			for _, p := range posts {
+				entry, err := fetchEntry(cl, p, !noComments)
+				if err == nil {
+					err = cache.Put(entry)
+				}
+				if err != nil {
+					slog.Warn("failed to sync post", "post", p.ID, "error", err)
+					ret.Failed++
+					continue
+				}
+				ret.Synced++
+			}
+			if err := cache.SetFeed(posts, time.Now()); err != nil {
+				slog.Error("failed to save the post list", "error", err)
+				return
+			}
+
+			ret.Evicted, err = cache.Evict(cfg.Reader.MaxAge(), cfg.Reader.MaxSize())
+			if err != nil {
+				slog.Error("failed to evict cached posts", "error", err)
+				return
+			}
+			render(out, ret)
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 50, "Number of latest posts to cache")
+	cmd.Flags().BoolVar(&noComments, "no-comments", false, "Don't cache comments")
+	return cmd
+}
+
+func latestPosts(cl *client.Client, limit int) ([]client.Post, error) {
+	var posts []client.Post
+	for len(posts) < limit {
+		n := min(syncPageSize, limit-len(posts))
+		resp, err := cl.GetSubscribedPosts(len(posts), n)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, resp.Data.Items...)
+		if len(resp.Data.Items) < n {
+			break
+		}
+	}
+	return posts, nil
+}
+
+// fetchEntry gets a post with its content and comments. Content the user has
+// no access to is recorded like `reader read` shows it.
+func fetchEntry(cl *client.Client, p client.Post, comments bool) (*readercache.Entry, error) {
+	listID, postID := strconv.FormatUint(p.ListID, 10), strconv.FormatUint(p.ID, 10)
+	postResp, err := cl.GetPost(listID, postID)
+	if err != nil {
+		return nil, err
+	}
+	entry := &readercache.Entry{Post: postResp.Data, FetchedAt: time.Now()}
+
+	contentResp, err := cl.GetPostContent(listID, postID)
+	switch {
+	case err == nil:
+		entry.Content = &contentResp.Data
+	case client.IsNetworkError(err):
+		return nil, err
+	default:
+		entry.ContentError = readableContentError(err)
+	}
+
+	if comments {
+		resp, err := cl.GetCommentsByPost(p.ID, 0, syncCommentLimit)
+		if err != nil {
+			return nil, err
+		}
+		entry.Comments = resp.Data.Items
+	}
+	return entry, nil
+}
+
+// offline reports whether to skip the API and read the cache. Without
+// --offline it tells whether err is a network failure to fall back from.
+func offline(cmd *cobra.Command, err error) bool {
+	if on, _ := cmd.Flags().GetBool("offline"); on {
+		return true
+	}
+	if err == nil || !client.IsNetworkError(err) {
+		return false
+	}
+	slog.Warn("the API can't be reached, reading the offline cache", "error", err)
+	return true
+}
+
+// warnStale tells that the output comes from the cache, and how old it is.
+func warnStale(fetchedAt time.Time) {
+	slog.Warn("showing cached data, it may be stale", "cached_at", fetchedAt.Local().Format(time.RFC3339))
+}
+
+// cachedPosts returns a page of the posts of the last sync.
+func cachedPosts(offset, limit int, unread bool) ([]client.Post, error) {
+	feed, err := readercache.Default().Feed()
+	if err != nil {
+		return nil, err
+	}
+	warnStale(feed.SyncedAt)
+
+	posts := feed.Posts
+	if unread {
+		state, err := readstate.Default().Load()
+		if err != nil {
+			return nil, err
+		}
+		posts = posts[:0:0]
+		for _, p := range feed.Posts {
+			if !state.IsRead(p.ID) {
+				posts = append(posts, p)
+			}
+		}
+	}
+	return page(posts, offset, limit), nil
+}
+
+// cachedComments returns a page of the cached comments of a post.
+func cachedComments(postID uint64, offset, limit int) ([]client.Comment, error) {
+	entry, err := readercache.Default().Get(postID)
+	if err != nil {
+		return nil, err
+	}
+	warnStale(entry.FetchedAt)
+	return page(entry.Comments, offset, limit), nil
+}
+
+// page returns up to limit items from offset, clamping both to the items.
+func page[T any](items []T, offset, limit int) []T {
+	offset = min(max(offset, 0), len(items))
+	return items[offset:min(offset+max(limit, 0), len(items))]
+}
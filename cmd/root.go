@@ -10,22 +10,29 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/auth"
 	"github.com/quailyquaily/quail-cli/cmd/comments"
 	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/cmd/configcmd"
 	"github.com/quailyquaily/quail-cli/cmd/initcmd"
+	"github.com/quailyquaily/quail-cli/cmd/listcmd"
 	"github.com/quailyquaily/quail-cli/cmd/login"
+	"github.com/quailyquaily/quail-cli/cmd/logout"
 	"github.com/quailyquaily/quail-cli/cmd/mcp"
 	"github.com/quailyquaily/quail-cli/cmd/me"
 	"github.com/quailyquaily/quail-cli/cmd/post"
 	"github.com/quailyquaily/quail-cli/cmd/reader"
+	"github.com/quailyquaily/quail-cli/cmd/search"
+	"github.com/quailyquaily/quail-cli/cmd/subscribers"
 	"github.com/quailyquaily/quail-cli/cmd/version"
 	"github.com/quailyquaily/quail-cli/oauth"
+	"github.com/quailyquaily/quail-cli/output"
 	"github.com/quailyquaily/quail-cli/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
 )
 
 var (
@@ -34,6 +41,11 @@ var (
 	apiBase     string
 	accessToken string
 	jsonOutput  bool
+	outputFlag  string
+	columnsFlag []string
+	sortFlag    []string
+	filterFlag  string
+	credential  = util.Credential{Source: util.CredentialSourceNone}
 	cl          *client.Client
 )
 
@@ -47,14 +59,32 @@ var rootCmd = &cobra.Command{
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 
+		if cl == nil && needsClient() {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("not logged in, run quail-cli login")
+		}
+
 		ctx = context.WithValue(ctx, common.CTX_CLIENT{}, cl)
 		ctx = context.WithValue(ctx, common.CTX_API_BASE{}, apiBase)
 		ctx = context.WithValue(ctx, common.CTX_AUTH_BASE{}, authBase)
-		format := common.FORMAT_HUMAN
+		ctx = context.WithValue(ctx, common.CTX_CREDENTIAL{}, credential)
+
 		if jsonOutput {
-			format = common.FORMAT_JSON
+			outputFlag = output.JSON
+		}
+		format, err := output.ParseFormat(outputFlag)
+		if err != nil {
+			return err
+		}
+		filter, err := output.ParseFilter(filterFlag)
+		if err != nil {
+			return err
 		}
-		ctx = context.WithValue(ctx, common.CTX_FORMAT{}, format)
+		out := output.New(format, os.Stdout)
+		out.Columns = columnsFlag
+		out.Sort = sortFlag
+		out.Filter = filter
+		ctx = context.WithValue(ctx, common.CTX_OUTPUT{}, out)
 
 		cmd.SetContext(ctx)
 
@@ -73,17 +103,27 @@ func ExecuteContext(ctx context.Context) {
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/quail-cli/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/quail-cli/config.yaml or $HOME/.config/quail-cli/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&apiBase, "api-base", "https://api.quail.ink", "Quail API base URL")
 	rootCmd.PersistentFlags().StringVar(&authBase, "auth-base", "https://quaily.com", "Quail Auth base URL")
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output JSON")
+	rootCmd.PersistentFlags().StringVar(&outputFlag, "output", output.Table, "output format: "+strings.Join(output.Formats, "|"))
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output JSON, same as --output json")
+	rootCmd.PersistentFlags().StringSliceVar(&columnsFlag, "columns", nil, "columns of table and csv output, like id,title,published_at")
+	rootCmd.PersistentFlags().StringSliceVar(&sortFlag, "sort", nil, "sort lists by columns, prefix a column with - for descending order")
+	rootCmd.PersistentFlags().StringVar(&filterFlag, "filter", "", "only output list items matching an expression, like 'status==0 && list==foo'")
 
 	rootCmd.AddCommand(initcmd.NewCmd())
+	rootCmd.AddCommand(configcmd.NewCmd())
 	rootCmd.AddCommand(login.NewCmd())
+	rootCmd.AddCommand(logout.NewCmd())
+	rootCmd.AddCommand(auth.NewCmd())
 	rootCmd.AddCommand(me.NewCmd())
+	rootCmd.AddCommand(listcmd.NewCmd())
 	rootCmd.AddCommand(post.NewCmd())
 	rootCmd.AddCommand(reader.NewCmd())
+	rootCmd.AddCommand(search.NewCmd())
 	rootCmd.AddCommand(comments.NewCmd())
+	rootCmd.AddCommand(subscribers.NewCmd())
 	rootCmd.AddCommand(mcp.NewCmd())
 	rootCmd.AddCommand(version.NewCmd())
 }
@@ -93,11 +133,15 @@ func initConfig() {
 		return
 	}
 
-	envAPIKey := strings.TrimSpace(os.Getenv("QUAIL_API_KEY"))
 	if cfgFile != "" {
 		// Use config file from the flag
 		viper.SetConfigFile(cfgFile)
 	} else {
+		if migrated, err := util.MigrateLegacyConfig(); err != nil {
+			slog.Warn("failed to migrate config", "error", err)
+		} else if migrated != "" {
+			fmt.Fprintf(os.Stderr, "Config file moved to %s\n", migrated)
+		}
 
 		fullpath := util.GetConfigFilePath()
 
@@ -115,8 +159,9 @@ func initConfig() {
 		configFile = cfgFile
 	}
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		if envAPIKey != "" {
-			accessToken = envAPIKey
+		credential = util.ResolveCredential()
+		if credential.Source == util.CredentialSourceEnv {
+			accessToken = credential.Token
 			cl = client.New(accessToken, apiBase)
 			return
 		}
@@ -126,8 +171,8 @@ func initConfig() {
 		// if the config file does not exist, ask the user to login
 		fmt.Println("Config file does not exist. Please login.")
 		util.Login(authBase, apiBase)
-		accessToken = viper.GetString("app.access_token")
-		cl = client.New(accessToken, apiBase)
+		credential = util.ResolveCredential()
+		cl = newOAuthClient(configFile)
 		return
 	}
 
@@ -138,49 +183,54 @@ func initConfig() {
 		fmt.Println("failed to read config", err, "config", viper.ConfigFileUsed())
 		return
 	}
-	if isSetupCommand() {
-		return
-	}
 
-	apiKey := envAPIKey
-	if apiKey == "" {
-		apiKey = strings.TrimSpace(viper.GetString("app.api_key"))
-	}
-	if apiKey != "" {
-		accessToken = apiKey
+	credential = util.ResolveCredential()
+	switch credential.Source {
+	case util.CredentialSourceEnv, util.CredentialSourceAPIKey:
+		accessToken = credential.Token
 		cl = client.New(accessToken, apiBase)
-		return
+	case util.CredentialSourceOAuth:
+		cl = newOAuthClient(configFile)
 	}
+}
 
-	accessToken = viper.GetString("app.access_token")
-	expiry := viper.GetTime("app.expiry")
-
-	if accessToken != "" && !expiry.IsZero() && time.Now().After(expiry) {
-		// if the access token has expired, try to get a new one using the refresh token
-		fmt.Println("Access token has expired. Try to get new one.")
-		refreshToken := viper.GetString("app.refresh_token")
-		token, err := oauth.RefreshToken(apiBase, refreshToken)
-		if err != nil {
-			slog.Error("failed to refresh token", "error", err)
-			return
-		}
-		// update the config file with the new access token
-		viper.Set("app.access_token", token.AccessToken)
-		viper.Set("app.expiry", token.Expiry)
-		viper.Set("app.token_type", token.TokenType)
-		viper.Set("app.refresh_token", token.RefreshToken)
+// newOAuthClient creates a client for the OAuth token in the config file. The
+// token is refreshed on demand, also during long-running commands like mcp.
+func newOAuthClient(configFile string) *client.Client {
+	transport := client.NewAuthTransport(
+		util.TokenFromConfig(),
+		util.NewConfigTokenStore(configFile),
+		func(refreshToken string) (*oauth2.Token, error) {
+			return oauth.RefreshToken(apiBase, refreshToken)
+		},
+	)
+	return client.NewWithAuth(transport, apiBase)
+}
 
-		viper.WriteConfig()
+func isSetupCommand() bool {
+	cmd := commandName()
+	return cmd == "login" || cmd == "init" || cmd == "logout" || cmd == "auth" || cmd == "config"
+}
 
-		accessToken = token.AccessToken
+// needsClient reports whether the command talks to the API, and so can't run
+// without a credential.
+func needsClient() bool {
+	switch commandName() {
+	case "", "version", "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
 	}
-
-	cl = client.New(accessToken, apiBase)
+	return !isSetupCommand()
 }
 
-func isSetupCommand() bool {
-	cmd := commandName()
-	return cmd == "login" || cmd == "init"
+// valueFlags are the global flags that take a separate value.
+var valueFlags = map[string]bool{
+	"--config":    true,
+	"--api-base":  true,
+	"--auth-base": true,
+	"--output":    true,
+	"--columns":   true,
+	"--sort":      true,
+	"--filter":    true,
 }
 
 func commandName() string {
@@ -189,13 +239,10 @@ func commandName() string {
 		switch {
 		case arg == "--":
 			return ""
-		case arg == "--config" || arg == "--api-base" || arg == "--auth-base":
+		case valueFlags[arg]:
 			i++
 			continue
-		case strings.HasPrefix(arg, "--config=") ||
-			strings.HasPrefix(arg, "--api-base=") ||
-			strings.HasPrefix(arg, "--auth-base=") ||
-			strings.HasPrefix(arg, "-"):
+		case strings.HasPrefix(arg, "-"):
 			continue
 		default:
 			return arg
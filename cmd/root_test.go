@@ -31,6 +31,21 @@ func TestIsSetupCommand(t *testing.T) {
 			args: []string{"quail-cli", "--config=./config.yaml", "init"},
 			want: true,
 		},
+		{
+			name: "logout command",
+			args: []string{"quail-cli", "logout"},
+			want: true,
+		},
+		{
+			name: "auth status command",
+			args: []string{"quail-cli", "--json", "auth", "status"},
+			want: true,
+		},
+		{
+			name: "config command after output flag",
+			args: []string{"quail-cli", "--output", "yaml", "config", "list"},
+			want: true,
+		},
 		{
 			name: "version command",
 			args: []string{"quail-cli", "--config", "./missing.yaml", "version"},
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/search"
+	"github.com/quailyquaily/quail-cli/util"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	var local bool
+	var reindex bool
+	var opts search.Options
+	var since string
+	var until string
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search posts on quaily.com, or offline in synced and project posts",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			query := strings.Join(args, " ")
+
+			if !local {
+				cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+				resp, err := cl.Search(query)
+				if err != nil {
+					slog.Error("failed to search", "error", err)
+					return
+				}
+				render(out, resp.Data.Items)
+				return
+			}
+
+			var err error
+			if opts.Since, err = config.ParseDate(since, false); err != nil {
+				slog.Error("invalid --since", "error", err)
+				return
+			}
+			if opts.Until, err = config.ParseDate(until, true); err != nil {
+				slog.Error("invalid --until", "error", err)
+				return
+			}
+			idx, err := search.Update(search.DefaultPath(), reindex, search.DefaultSources(config.Current())...)
+			if err != nil {
+				slog.Error("failed to update the search index", "error", err)
+				return
+			}
+			if len(idx.Docs) == 0 {
+				slog.Error("failed to search", "error", search.ErrEmpty)
+				return
+			}
+
+			color := out.Format.Kind == output.Table && util.UseColor()
+			if color {
+				opts.Open, opts.Close = "\x1b[1;33m", "\x1b[0m"
+			}
+			results := idx.Search(query, opts)
+			if out.Format.Kind == output.Table && len(out.Columns) == 0 {
+				render(out, resultList(results))
+				return
+			}
+			render(out, results)
+		},
+	}
+	cmd.Flags().BoolVar(&local, "local", false, "Search the local index of posts from reader sync and the current project, works offline")
+	cmd.Flags().BoolVar(&reindex, "reindex", false, "Rebuild the local index even if no post changed")
+	cmd.Flags().StringVar(&opts.List, "list", "", "Only posts of this list id or slug (with --local)")
+	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Only posts with this tag (with --local)")
+	cmd.Flags().StringVar(&since, "since", "", "Only posts published on or after this date, like 2026-01-31 (with --local)")
+	cmd.Flags().StringVar(&until, "until", "", "Only posts published on or before this date (with --local)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 20, "Maximum number of results (with --local)")
+	return cmd
+}
+
+func init() {
+	output.Register[search.Result](
+		output.Col("score", func(r search.Result) any { return r.Score }),
+		output.Col("source", func(r search.Result) any { return r.Source }),
+		output.Col("list", func(r search.Result) any { return r.List }),
+		output.Col("title", func(r search.Result) any { return r.Title }),
+		output.Col("published_at", func(r search.Result) any { return r.PublishedAt }),
+		output.Col("location", func(r search.Result) any { return r.Location }),
+		output.Col("tags", func(r search.Result) any { return r.Tags }).AsDetail(),
+		output.Col("snippet", func(r search.Result) any { return r.Snippet }).AsDetail(),
+	)
+}
+
+// resultList prints results with their snippets, which don't fit in table
+// columns.
+type resultList []search.Result
+
+func (l resultList) PrintTable(w io.Writer) error {
+	if len(l) == 0 {
+		_, err := fmt.Fprintln(w, "no results")
+		return err
+	}
+	for i, r := range l {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s  (%s, %.2f)\n", r.Title, r.List, r.Score)
+		if !r.PublishedAt.IsZero() {
+			fmt.Fprintf(w, "%s  ", r.PublishedAt.Local().Format("2006-01-02"))
+		}
+		fmt.Fprintln(w, r.Location)
+		if _, err := fmt.Fprintf(w, "  %s\n", r.Snippet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func render(out *output.Renderer, v any) {
+	if err := out.Render(v); err != nil {
+		slog.Error("failed to render output", "error", err)
+	}
+}
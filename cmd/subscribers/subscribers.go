@@ -0,0 +1,240 @@
+package subscribers
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/cmd/common"
+	"github.com/quailyquaily/quail-cli/output"
+	"github.com/quailyquaily/quail-cli/subscribers"
+	"github.com/spf13/cobra"
+)
+
+// subscriber is a subscription seen by the owner of the list, with columns
+// about the subscriber rather than the list. Its JSON is the subscription's.
+type subscriber struct {
+	client.Subscription
+}
+
+func init() {
+	output.Register[subscriber](
+		output.Col("id", func(s subscriber) any { return s.ID }),
+		output.Col("user_id", func(s subscriber) any { return s.UserID }),
+		output.Col("name", func(s subscriber) any {
+			if s.User != nil {
+				return s.User.Name
+			}
+			return ""
+		}),
+		output.Col("email", func(s subscriber) any {
+			if s.User != nil {
+				return s.User.Email
+			}
+			return ""
+		}),
+		output.Col("type", func(s subscriber) any { return s.Type }),
+		output.Col("paid", func(s subscriber) any { return subscribers.IsPaid(s.Subscription, time.Now()) }),
+		output.Col("email_enabled", func(s subscriber) any { return s.EmailEnabled }),
+		output.Col("paid_expiry", func(s subscriber) any { return s.PaidExpiry }),
+		output.Col("created_at", func(s subscriber) any { return s.CreatedAt }),
+	)
+
+	output.Register[subscribers.Counts](
+		output.Col("list", func(c subscribers.Counts) any { return c.List }),
+		output.Col("total", func(c subscribers.Counts) any { return c.Total }),
+		output.Col("free", func(c subscribers.Counts) any { return c.Free }),
+		output.Col("paid", func(c subscribers.Counts) any { return c.Paid }),
+		output.Col("email_enabled", func(c subscribers.Counts) any { return c.EmailEnabled }),
+	)
+}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "subscribers",
+		Short: "List, count and export the subscribers of your lists",
+	}
+
+	cmd.AddCommand(newLsCmd())
+	cmd.AddCommand(newCountCmd())
+	cmd.AddCommand(newExportCmd())
+
+	return cmd
+}
+
+// filterFlags are the flags selecting subscribers, shared by the commands.
+type filterFlags struct {
+	list         string
+	paid         bool
+	free         bool
+	emailEnabled bool
+}
+
+func (f *filterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.list, "list", "", "List ID or slug")
+	cmd.Flags().BoolVar(&f.paid, "paid", false, "Only paid subscribers")
+	cmd.Flags().BoolVar(&f.free, "free", false, "Only free subscribers, including expired paid ones")
+	cmd.Flags().BoolVar(&f.emailEnabled, "email-enabled", false, "Only subscribers with email enabled, or with it disabled if set to false")
+	cmd.MarkFlagsMutuallyExclusive("paid", "free")
+}
+
+func (f *filterFlags) parse(cmd *cobra.Command) (subscribers.Filter, error) {
+	var ret subscribers.Filter
+	if f.list == "" {
+		return ret, fmt.Errorf("missing --list")
+	}
+	if f.paid || f.free {
+		ret.Paid = &f.paid
+	}
+	if cmd.Flags().Changed("email-enabled") {
+		ret.EmailEnabled = &f.emailEnabled
+	}
+	return ret, nil
+}
+
+func wrap(subs []client.Subscription) []subscriber {
+	ret := make([]subscriber, len(subs))
+	for i, s := range subs {
+		ret[i] = subscriber{Subscription: s}
+	}
+	return ret
+}
+
+func newLsCmd() *cobra.Command {
+	var flags filterFlags
+	var offset int
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List subscribers of a list",
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			f, err := flags.parse(cmd)
+			if err != nil {
+				slog.Error("invalid flags", "error", err)
+				return
+			}
+			if limit <= 0 {
+				limit = 50
+			}
+
+			subs, err := subscribers.List(cl, flags.list, f, offset, limit)
+			if err != nil {
+				slog.Error("failed to get subscribers", "error", err)
+				return
+			}
+			render(out, wrap(subs))
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&offset, "offset", 0, "Subscriber offset")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Subscriber limit")
+	return cmd
+}
+
+func newCountCmd() *cobra.Command {
+	var flags filterFlags
+
+	cmd := &cobra.Command{
+		Use:   "count",
+		Short: "Count subscribers of a list, free, paid and with email enabled",
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			out := cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			f, err := flags.parse(cmd)
+			if err != nil {
+				slog.Error("invalid flags", "error", err)
+				return
+			}
+
+			counts, err := subscribers.Count(cl, flags.list, f)
+			if err != nil {
+				slog.Error("failed to count subscribers", "error", err)
+				return
+			}
+			render(out, counts)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func newExportCmd() *cobra.Command {
+	var flags filterFlags
+	var format string
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all subscribers of a list, as CSV by default",
+		Long: `Export all subscribers of a list matching the filters, as CSV by default.
+The columns are id, user_id, name, email, type, paid, email_enabled,
+paid_expiry and created_at, unless --columns picks others. --format takes
+the formats of --output, like ndjson.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cl := cmd.Context().Value(common.CTX_CLIENT{}).(*client.Client)
+			f, err := flags.parse(cmd)
+			if err != nil {
+				slog.Error("invalid flags", "error", err)
+				return
+			}
+			out := *cmd.Context().Value(common.CTX_OUTPUT{}).(*output.Renderer)
+			out.Format, err = output.ParseFormat(format)
+			if err != nil {
+				slog.Error("invalid --format", "error", err)
+				return
+			}
+
+			subs, err := subscribers.All(cl, flags.list, f)
+			if err != nil {
+				slog.Error("failed to get subscribers", "error", err)
+				return
+			}
+			if file == "" || file == "-" {
+				out.Out = os.Stdout
+				render(&out, wrap(subs))
+				return
+			}
+			if err := exportFile(&out, file, wrap(subs)); err != nil {
+				slog.Error("failed to export subscribers", "error", err)
+				return
+			}
+			fmt.Printf("%d subscribers written to %s\n", len(subs), file)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().StringVar(&format, "format", output.CSV, "Export format, like csv or ndjson")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write to this file instead of stdout")
+	return cmd
+}
+
+// exportFile renders to a temporary file that is renamed over path once
+// complete. The file is only readable by the user, as it has the emails of
+// subscribers.
+func exportFile(out *output.Renderer, path string, subs []subscriber) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	out.Out = tmp
+	if err := out.Render(subs); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func render(out *output.Renderer, v any) {
+	if err := out.Render(v); err != nil {
+		slog.Error("failed to render output", "error", err)
+	}
+}
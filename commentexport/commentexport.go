@@ -0,0 +1,153 @@
+// Package commentexport writes comments as flat rows, in CSV or JSON lines,
+// for spreadsheets and analytics.
+//
+// The schema is stable: columns are only ever added at the end, and never
+// renamed, reordered or removed.
+package commentexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return CSV, nil
+	case "jsonl", "ndjson":
+		return JSONL, nil
+	}
+	return "", fmt.Errorf("invalid format %q, use csv or jsonl", s)
+}
+
+// Row is a comment flattened. Times are RFC 3339 in UTC.
+type Row struct {
+	ID             uint64 `json:"id"`
+	ListID         uint64 `json:"list_id"`
+	PostID         uint64 `json:"post_id"`
+	PostTitle      string `json:"post_title"`
+	AuthorID       uint64 `json:"author_id"`
+	AuthorName     string `json:"author_name"`
+	Status         int    `json:"status"`
+	StatusName     string `json:"status_name"`
+	QuoteCommentID uint64 `json:"quote_comment_id"`
+	QuoteAuthorID  uint64 `json:"quote_author_id"`
+	ReplyCount     int    `json:"reply_count"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+	Content        string `json:"content"`
+}
+
+// Columns are the names of the columns, in order.
+var Columns = []string{
+	"id", "list_id", "post_id", "post_title", "author_id", "author_name", "status", "status_name",
+	"quote_comment_id", "quote_author_id", "reply_count", "created_at", "updated_at", "content",
+}
+
+func (r Row) values() []string {
+	u := func(n uint64) string { return strconv.FormatUint(n, 10) }
+	return []string{
+		u(r.ID), u(r.ListID), u(r.PostID), r.PostTitle, u(r.AuthorID), r.AuthorName,
+		strconv.Itoa(r.Status), r.StatusName, u(r.QuoteCommentID), u(r.QuoteAuthorID),
+		strconv.Itoa(r.ReplyCount), r.CreatedAt, r.UpdatedAt, r.Content,
+	}
+}
+
+// RowOf flattens a comment. postTitle is used when the comment doesn't come
+// with its post.
+func RowOf(c client.Comment, postTitle string) Row {
+	r := Row{
+		ID:             c.ID,
+		ListID:         c.ListID,
+		PostID:         c.PostID,
+		PostTitle:      postTitle,
+		AuthorID:       c.AuthorID,
+		Status:         c.Status,
+		StatusName:     client.CommentStatusName(c.Status),
+		QuoteCommentID: c.QuoteCommentID,
+		ReplyCount:     len(c.RepliedComments),
+		CreatedAt:      formatTime(c.CreatedAt),
+		UpdatedAt:      formatTime(c.UpdatedAt),
+		Content:        c.Content,
+	}
+	if c.Post != nil && c.Post.Title != "" {
+		r.PostTitle = c.Post.Title
+	}
+	if c.Author != nil {
+		r.AuthorName = c.Author.Name
+	}
+	if c.QuoteComment != nil {
+		r.QuoteAuthorID = c.QuoteComment.AuthorID
+	}
+	return r
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Writer writes rows in a format.
+type Writer struct {
+	format Format
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+func NewWriter(w io.Writer, format Format) *Writer {
+	ret := &Writer{format: format}
+	if format == CSV {
+		ret.csv = csv.NewWriter(w)
+	} else {
+		ret.json = json.NewEncoder(w)
+		ret.json.SetEscapeHTML(false)
+	}
+	return ret
+}
+
+// Write writes a row, after the CSV header for the first one.
+func (w *Writer) Write(r Row) error {
+	if w.format != CSV {
+		return w.json.Encode(r)
+	}
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(Columns); err != nil {
+			return err
+		}
+	}
+	return w.csv.Write(r.values())
+}
+
+// Flush writes what is buffered, and the CSV header when no rows were
+// written.
+func (w *Writer) Flush() error {
+	if w.format != CSV {
+		return nil
+	}
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(Columns); err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
@@ -0,0 +1,93 @@
+package commentexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+func testComment() client.Comment {
+	return client.Comment{
+		ID: 9, ListID: 2, PostID: 5, AuthorID: 7, Status: client.CommentStatusApproved,
+		QuoteCommentID: 8,
+		Content:        "Nice, \"quoted\"\nand <b>bold</b>",
+		CreatedAt:      time.Date(2026, 3, 1, 10, 0, 0, 0, time.FixedZone("", 8*3600)),
+		Author:         &client.User{Name: "ann"},
+		QuoteComment:   &client.Comment{ID: 8, AuthorID: 3},
+		RepliedComments: []*client.Comment{
+			{ID: 10}, {ID: 11},
+		},
+	}
+}
+
+func TestRowOf(t *testing.T) {
+	r := RowOf(testComment(), "Gophers")
+	want := Row{
+		ID: 9, ListID: 2, PostID: 5, PostTitle: "Gophers", AuthorID: 7, AuthorName: "ann",
+		Status: client.CommentStatusApproved, StatusName: "approved", QuoteCommentID: 8, QuoteAuthorID: 3,
+		ReplyCount: 2, CreatedAt: "2026-03-01T02:00:00Z", Content: "Nice, \"quoted\"\nand <b>bold</b>",
+	}
+	if r != want {
+		t.Fatalf("RowOf() = %+v\nwant %+v", r, want)
+	}
+}
+
+func TestColumnsMatchRow(t *testing.T) {
+	typ := reflect.TypeOf(Row{})
+	if typ.NumField() != len(Columns) {
+		t.Fatalf("Row has %d fields, Columns %d", typ.NumField(), len(Columns))
+	}
+	for i, name := range Columns {
+		if tag := typ.Field(i).Tag.Get("json"); tag != name {
+			t.Errorf("column %d is %q, the JSON of the field is %q", i, name, tag)
+		}
+	}
+	if got := len(Row{}.values()); got != len(Columns) {
+		t.Errorf("a CSV row has %d values, want %d", got, len(Columns))
+	}
+}
+
+func TestWriter(t *testing.T) {
+	row := RowOf(testComment(), "Gophers")
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, CSV)
+	if err := w.Write(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || strings.Join(records[0], ",") != strings.Join(Columns, ",") || records[1][len(Columns)-1] != row.Content {
+		t.Fatalf("csv = %q", records)
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, JSONL)
+	w.Write(row)
+	w.Flush()
+	if !strings.Contains(buf.String(), "<b>bold</b>") {
+		t.Fatalf("jsonl escapes HTML: %s", buf.String())
+	}
+	var got Row
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || got != row {
+		t.Fatalf("jsonl = %s, %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, CSV)
+	w.Flush()
+	if strings.TrimSpace(buf.String()) != strings.Join(Columns, ",") {
+		t.Fatalf("empty csv = %q, want the header", buf.String())
+	}
+}
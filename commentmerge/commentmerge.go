@@ -0,0 +1,159 @@
+// Package commentmerge reads the latest comments across lists.
+//
+// The first page of every list is fetched at once, a few lists at a time.
+// Then the lists are merged newest first, like the lines of sorted files,
+// and a list is paged further only when the merge has taken all of its
+// comments read so far. Getting the latest 50 comments of a dozen lists
+// reads a page of each list and seldom more, rather than 50 comments of
+// each.
+package commentmerge
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+// Source is the part of the API the merge uses, implemented by
+// *client.Client.
+type Source interface {
+	GetCommentsByList(listIDOrSlug string, offset, limit int) (*client.CommentsResponse, error)
+}
+
+const (
+	DefaultConcurrency = 4
+	DefaultPageSize    = 20
+)
+
+type Options struct {
+	// Concurrency is how many lists are read at once.
+	Concurrency int
+	// PageSize is the most comments read from a list at once.
+	PageSize int
+}
+
+// ListError is the failure to read the comments of a list. The other lists
+// are merged without it.
+type ListError struct {
+	ListID uint64
+	Err    error
+}
+
+func (e *ListError) Error() string {
+	return fmt.Sprintf("list %d: %v", e.ListID, e.Err)
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+// cursor reads the comments of a list, a page at a time.
+type cursor struct {
+	listID uint64
+	buf    []client.Comment
+	offset int
+	done   bool
+	err    error
+}
+
+func (c *cursor) fetch(src Source, size int) {
+	resp, err := src.GetCommentsByList(strconv.FormatUint(c.listID, 10), c.offset, size)
+	if err != nil {
+		c.err, c.done = err, true
+		return
+	}
+	items := resp.Data.Items
+	// the merge relies on each list being newest first
+	sort.SliceStable(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+	c.buf = append(c.buf, items...)
+	c.offset += len(items)
+	c.done = len(items) < size
+}
+
+// cursors is a heap of cursors by their newest comment.
+type cursors []*cursor
+
+func (h cursors) Len() int { return len(h) }
+func (h cursors) Less(i, j int) bool {
+	a, b := h[i].buf[0], h[j].buf[0]
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+func (h cursors) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *cursors) Push(x any)   { *h = append(*h, x.(*cursor)) }
+func (h *cursors) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// Latest returns the limit newest comments of the lists, newest first, and
+// the errors of the lists that failed.
+func Latest(src Source, listIDs []uint64, limit int, opts Options) ([]client.Comment, []*ListError) {
+	if limit <= 0 || len(listIDs) == 0 {
+		return nil, nil
+	}
+	size := opts.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	size = min(size, limit)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	all := make([]*cursor, len(listIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range listIDs {
+		all[i] = &cursor{listID: id}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			all[i].fetch(src, size)
+		}()
+	}
+	wg.Wait()
+
+	var errs []*ListError
+	h := make(cursors, 0, len(all))
+	for _, c := range all {
+		if c.err != nil {
+			errs = append(errs, &ListError{ListID: c.listID, Err: c.err})
+			continue
+		}
+		if len(c.buf) > 0 {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+
+	ret := make([]client.Comment, 0, limit)
+	for len(ret) < limit && h.Len() > 0 {
+		c := h[0]
+		ret = append(ret, c.buf[0])
+		c.buf = c.buf[1:]
+		if len(c.buf) == 0 && !c.done && len(ret) < limit {
+			c.fetch(src, min(size, limit-len(ret)))
+			if c.err != nil {
+				errs = append(errs, &ListError{ListID: c.listID, Err: c.err})
+			}
+		}
+		if len(c.buf) == 0 {
+			heap.Pop(&h)
+			continue
+		}
+		heap.Fix(&h, 0)
+	}
+	return ret, errs
+}
@@ -0,0 +1,133 @@
+package commentmerge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/client/fakeapi"
+)
+
+// newServer serves lists of comments posted at interleaved times, so the
+// newest comments are spread across the lists.
+func newServer(lists, perList int) (*fakeapi.Server, []uint64) {
+	s := fakeapi.New(1)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []uint64
+	id := uint64(0)
+	for l := 1; l <= lists; l++ {
+		listID := uint64(l)
+		ids = append(ids, listID)
+		s.AddList(client.List{ID: listID, Slug: fmt.Sprintf("list-%d", l)})
+		var comments []client.Comment
+		for i := 0; i < perList; i++ {
+			id++
+			// lists get comments at different rates
+			at := start.Add(time.Duration(i*l) * time.Minute)
+			comments = append(comments, client.Comment{ID: id, CreatedAt: at, Content: "comment " + strconv.FormatUint(id, 10)})
+		}
+		s.AddComments(listID, comments...)
+	}
+	return s, ids
+}
+
+func TestLatest(t *testing.T) {
+	s, ids := newServer(5, 60)
+	defer s.Close()
+	cl := s.Client()
+
+	var want []client.Comment
+	for _, id := range ids {
+		resp, err := cl.GetCommentsByList(strconv.FormatUint(id, 10), 0, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, resp.Data.Items...)
+	}
+	sort.SliceStable(want, func(i, j int) bool {
+		if !want[i].CreatedAt.Equal(want[j].CreatedAt) {
+			return want[i].CreatedAt.After(want[j].CreatedAt)
+		}
+		return want[i].ID > want[j].ID
+	})
+
+	for _, limit := range []int{1, 7, 50, 300, 400} {
+		s.ResetRequests()
+		got, errs := Latest(cl, ids, limit, Options{PageSize: 20})
+		if len(errs) > 0 {
+			t.Fatalf("limit %d: errors %v", limit, errs)
+		}
+		n := min(limit, len(want))
+		if len(got) != n {
+			t.Fatalf("limit %d: got %d comments, want %d", limit, len(got), n)
+		}
+		for i := range got {
+			if got[i].ID != want[i].ID {
+				t.Fatalf("limit %d: comment %d is %d, want %d", limit, i, got[i].ID, want[i].ID)
+			}
+		}
+		if limit == 50 && s.Requests() > int64(len(ids)+3) {
+			t.Errorf("limit 50 took %d requests, the merge should page lazily", s.Requests())
+		}
+	}
+}
+
+func TestLatestListError(t *testing.T) {
+	s, ids := newServer(3, 10)
+	defer s.Close()
+	s.FailList(2)
+
+	got, errs := Latest(s.Client(), ids, 100, Options{})
+	if len(errs) != 1 || errs[0].ListID != 2 {
+		t.Fatalf("errors = %v, want list 2 failing", errs)
+	}
+	var le *ListError
+	if !errors.As(error(errs[0]), &le) || le.Err == nil {
+		t.Fatalf("error %v doesn't wrap the request error", errs[0])
+	}
+	if len(got) != 20 {
+		t.Fatalf("got %d comments, want the 20 of the other lists", len(got))
+	}
+	for _, c := range got {
+		if c.ListID == 2 {
+			t.Fatalf("comment %d of the failing list", c.ID)
+		}
+	}
+}
+
+// sequential is how comments latest used to read comments: limit comments
+// of each list, one list after the other, then sorted.
+func sequential(cl *client.Client, ids []uint64, limit int) []client.Comment {
+	var items []client.Comment
+	for _, id := range ids {
+		resp, err := cl.GetCommentsByList(strconv.FormatUint(id, 10), 0, limit)
+		if err != nil {
+			continue
+		}
+		items = append(items, resp.Data.Items...)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+	return items[:min(limit, len(items))]
+}
+
+func BenchmarkLatest(b *testing.B) {
+	s, ids := newServer(12, 200)
+	defer s.Close()
+	s.Latency = 2 * time.Millisecond
+	cl := s.Client()
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sequential(cl, ids, 50)
+		}
+	})
+	b.Run("merge", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Latest(cl, ids, 50, Options{})
+		}
+	})
+}
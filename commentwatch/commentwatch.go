@@ -0,0 +1,205 @@
+// Package commentwatch polls the lists of a user for new comments.
+//
+// A Watcher remembers the highest comment ID it has seen on each list, its
+// high-water mark, and reports the comments above it. Comments already on
+// the lists when watching starts aren't reported, but all the comments of a
+// list created while watching are.
+package commentwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+// Source is the part of the API the watcher uses, implemented by
+// *client.Client.
+type Source interface {
+	GetMe() (*client.UserResponse, error)
+	GetUserLists(userID uint64) ([]client.List, error)
+	GetCommentsByList(listIDOrSlug string, offset, limit int) (*client.CommentsResponse, error)
+}
+
+const (
+	DefaultInterval    = time.Minute
+	DefaultMaxInterval = 15 * time.Minute
+	DefaultPageSize    = 50
+	// maxPages bounds the pages read on a list in one poll, when more
+	// comments than a page came since the last one.
+	maxPages = 10
+)
+
+type Watcher struct {
+	Source Source
+	// Interval is the time between polls, and MaxInterval the longest it
+	// grows to while polls fail.
+	Interval    time.Duration
+	MaxInterval time.Duration
+	// PageSize is how many comments are requested at once.
+	PageSize int
+
+	userID  uint64
+	started bool
+	// down is set when the last poll read no list, so the API is likely
+	// down rather than a list broken.
+	down  bool
+	marks map[uint64]uint64
+	// baseline holds the lists found on the first poll, whose comments are
+	// old, until their comments are read once.
+	baseline map[uint64]bool
+}
+
+func New(src Source) *Watcher {
+	return &Watcher{Source: src}
+}
+
+// ListError is the failure to read the comments of a list. A poll goes on
+// with the other lists.
+type ListError struct {
+	List client.List
+	Err  error
+}
+
+func (e *ListError) Error() string {
+	return fmt.Sprintf("list %d: %v", e.List.ID, e.Err)
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+// Poll reads the lists of the user and returns the comments that came since
+// the previous poll, oldest first. Lists failing to be read are ListErrors
+// in the returned error, along with the comments of the other lists.
+func (w *Watcher) Poll() ([]client.Comment, error) {
+	if w.marks == nil {
+		w.marks = map[uint64]uint64{}
+		w.baseline = map[uint64]bool{}
+	}
+	w.down = true
+	if w.userID == 0 {
+		me, err := w.Source.GetMe()
+		if err != nil {
+			return nil, err
+		}
+		w.userID = me.Data.ID
+	}
+	lists, err := w.Source.GetUserLists(w.userID)
+	if err != nil {
+		return nil, err
+	}
+	if !w.started {
+		for _, l := range lists {
+			w.baseline[l.ID] = true
+		}
+		w.started = true
+	}
+
+	var ret []client.Comment
+	var errs []error
+	for _, l := range lists {
+		comments, err := w.pollList(l.ID)
+		if err != nil {
+			errs = append(errs, &ListError{List: l, Err: err})
+			continue
+		}
+		ret = append(ret, comments...)
+	}
+	w.down = len(lists) > 0 && len(errs) == len(lists)
+	sort.SliceStable(ret, func(i, j int) bool {
+		if !ret[i].CreatedAt.Equal(ret[j].CreatedAt) {
+			return ret[i].CreatedAt.Before(ret[j].CreatedAt)
+		}
+		return ret[i].ID < ret[j].ID
+	})
+	return ret, errors.Join(errs...)
+}
+
+// pollList returns the comments of a list above its mark, and raises the
+// mark. Comment IDs grow, so the newest comments have the highest IDs.
+func (w *Watcher) pollList(listID uint64) ([]client.Comment, error) {
+	mark := w.marks[listID]
+	size := w.pageSize()
+	pages := maxPages
+	if w.baseline[listID] {
+		pages = 1
+	}
+
+	var fresh []client.Comment
+	top := mark
+	for page := 0; page < pages; page++ {
+		resp, err := w.Source.GetCommentsByList(strconv.FormatUint(listID, 10), page*size, size)
+		if err != nil {
+			return nil, err
+		}
+		reached := false
+		for _, c := range resp.Data.Items {
+			if c.ID <= mark {
+				reached = true
+				continue
+			}
+			fresh = append(fresh, c)
+			top = max(top, c.ID)
+		}
+		if reached || len(resp.Data.Items) < size {
+			break
+		}
+	}
+
+	w.marks[listID] = top
+	if w.baseline[listID] {
+		delete(w.baseline, listID)
+		return nil, nil
+	}
+	return fresh, nil
+}
+
+func (w *Watcher) pageSize() int {
+	if w.PageSize > 0 {
+		return w.PageSize
+	}
+	return DefaultPageSize
+}
+
+// Run polls until ctx is done, and passes new comments to fn. Failed polls
+// are passed to onError. Polls reading no list double the wait before the
+// next poll, up to MaxInterval.
+func (w *Watcher) Run(ctx context.Context, fn func([]client.Comment), onError func(error)) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	maxInterval := max(w.MaxInterval, interval)
+	if w.MaxInterval <= 0 {
+		maxInterval = max(DefaultMaxInterval, interval)
+	}
+
+	wait := interval
+	for {
+		comments, err := w.Poll()
+		if len(comments) > 0 {
+			fn(comments)
+		}
+		if err != nil {
+			onError(err)
+		}
+		if w.down {
+			wait = min(wait*2, maxInterval)
+		} else {
+			wait = interval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package commentwatch
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+type fakeSource struct {
+	lists    []client.List
+	comments map[uint64][]client.Comment
+	failList map[uint64]bool
+}
+
+func (f *fakeSource) GetMe() (*client.UserResponse, error) {
+	resp := &client.UserResponse{}
+	resp.Data.ID = 1
+	return resp, nil
+}
+
+func (f *fakeSource) GetUserLists(userID uint64) ([]client.List, error) {
+	return f.lists, nil
+}
+
+func (f *fakeSource) GetCommentsByList(listIDOrSlug string, offset, limit int) (*client.CommentsResponse, error) {
+	id, _ := strconv.ParseUint(listIDOrSlug, 10, 64)
+	if f.failList[id] {
+		return nil, errors.New("unavailable")
+	}
+	// newest first, like the API
+	all := f.comments[id]
+	var items []client.Comment
+	for i := len(all) - 1 - offset; i >= 0 && len(items) < limit; i-- {
+		items = append(items, all[i])
+	}
+	resp := &client.CommentsResponse{}
+	resp.Data.Items = items
+	return resp, nil
+}
+
+func (f *fakeSource) add(listID, id uint64) {
+	f.comments[listID] = append(f.comments[listID], client.Comment{
+		ID: id, ListID: listID, CreatedAt: time.Unix(int64(id), 0),
+	})
+}
+
+func ids(comments []client.Comment) []uint64 {
+	ret := []uint64{}
+	for _, c := range comments {
+		ret = append(ret, c.ID)
+	}
+	return ret
+}
+
+func TestPoll(t *testing.T) {
+	src := &fakeSource{lists: []client.List{{ID: 1}}, comments: map[uint64][]client.Comment{}, failList: map[uint64]bool{}}
+	src.add(1, 1)
+	src.add(1, 2)
+	w := New(src)
+	w.PageSize = 2
+
+	if got, err := w.Poll(); err != nil || len(got) != 0 {
+		t.Fatalf("first poll = %v, %v, want no comments", ids(got), err)
+	}
+
+	// more comments than a page, and a list created while watching
+	for id := uint64(3); id <= 7; id++ {
+		src.add(1, id)
+	}
+	src.lists = append(src.lists, client.List{ID: 2})
+	src.add(2, 8)
+	got, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{3, 4, 5, 6, 7, 8}; !slices.Equal(ids(got), want) {
+		t.Fatalf("second poll = %v, want %v", ids(got), want)
+	}
+
+	if got, _ := w.Poll(); len(got) != 0 {
+		t.Fatalf("third poll = %v, want no comments", ids(got))
+	}
+}
+
+func TestPollListError(t *testing.T) {
+	src := &fakeSource{lists: []client.List{{ID: 1}, {ID: 2}}, comments: map[uint64][]client.Comment{}, failList: map[uint64]bool{}}
+	w := New(src)
+	w.Poll()
+
+	src.add(1, 1)
+	src.add(2, 2)
+	src.failList[2] = true
+	got, err := w.Poll()
+	var le *ListError
+	if !errors.As(err, &le) || le.List.ID != 2 {
+		t.Fatalf("poll error = %v, want a ListError of list 2", err)
+	}
+	if !slices.Equal(ids(got), []uint64{1}) || w.down {
+		t.Fatalf("poll = %v, down %v, want [1] from the other list", ids(got), w.down)
+	}
+
+	src.failList[2] = false
+	if got, err := w.Poll(); err != nil || !slices.Equal(ids(got), []uint64{2}) {
+		t.Fatalf("poll after recovery = %v, %v, want [2]", ids(got), err)
+	}
+
+	src.failList[1], src.failList[2] = true, true
+	if w.Poll(); !w.down {
+		t.Fatalf("down = false after every list failed")
+	}
+}
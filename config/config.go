@@ -0,0 +1,97 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+// Config is the typed form of config.yaml. Keys are described in Keys.
+type Config struct {
+	App    App    `mapstructure:"app"`
+	Post   Post   `mapstructure:"post"`
+	Reader Reader `mapstructure:"reader"`
+
+	// Project is the .quail.yaml of the working directory, nil outside of a
+	// project. Its settings are already layered over App and Post.
+	Project *Project `mapstructure:"-"`
+}
+
+type App struct {
+	APIKey       string    `mapstructure:"api_key"`
+	AccessToken  string    `mapstructure:"access_token"`
+	RefreshToken string    `mapstructure:"refresh_token"`
+	TokenType    string    `mapstructure:"token_type"`
+	Expiry       time.Time `mapstructure:"expiry"`
+	Scope        string    `mapstructure:"scope"`
+	User         User      `mapstructure:"user"`
+}
+
+type User struct {
+	ID   uint64 `mapstructure:"id"`
+	Name string `mapstructure:"name"`
+	Bio  string `mapstructure:"bio"`
+}
+
+type Post struct {
+	List               string            `mapstructure:"list"`
+	FrontmatterMapping map[string]string `mapstructure:"frontmatter_mapping"`
+}
+
+// Current returns the config loaded by the root command, with the project
+// config of the working directory layered over it. Decode errors are logged,
+// `quail-cli config validate` reports them in detail.
+func Current() *Config {
+	c, err := FromViper(viper.GetViper())
+	if err != nil {
+		slog.Warn("invalid config, run quail-cli config validate", "error", err)
+	}
+	if p := CurrentProject(); p != nil {
+		p.apply(c)
+	}
+	return c
+}
+
+func FromViper(v *viper.Viper) (*Config, error) {
+	c := &Config{}
+	err := v.Unmarshal(c, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		timeHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+	)))
+	return c, err
+}
+
+// Token returns the OAuth token, with the granted scope as extra field.
+func (a App) Token() *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  a.AccessToken,
+		RefreshToken: a.RefreshToken,
+		TokenType:    a.TokenType,
+		Expiry:       a.Expiry,
+	}
+	return token.WithExtra(map[string]any{"scope": a.Scope})
+}
+
+func timeHook(from, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(time.Time{}) {
+		return data, nil
+	}
+	s, ok := data.(string)
+	if !ok {
+		return data, nil
+	}
+	return parseTime(s)
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ProjectFileName is the per-project config, found by walking up from the
+// working directory like .git.
+const ProjectFileName = ".quail.yaml"
+
+// projectKeys are the keys a project file may set. Credentials are never
+// read from a project file, it is usually committed to the repository.
+var projectKeys = []string{"list", "frontmatter_mapping", "presets", "content_root", "assets", "sync"}
+
+var credentialNames = []string{"app", "api_key", "access_token", "refresh_token", "token"}
+
+type Project struct {
+	// Path is the location of the .quail.yaml file.
+	Path string `yaml:"-"`
+
+	List               string            `yaml:"list"`
+	FrontmatterMapping map[string]string `yaml:"frontmatter_mapping"`
+	Presets            map[string]Preset `yaml:"presets"`
+	ContentRoot        string            `yaml:"content_root"`
+	Assets             []AssetRule       `yaml:"assets"`
+	Sync               ProjectSync       `yaml:"sync"`
+}
+
+// Preset holds post defaults selected with `post upsert --preset`.
+type Preset struct {
+	List    string `yaml:"list"`
+	Theme   string `yaml:"theme"`
+	Tags    string `yaml:"tags"`
+	Publish bool   `yaml:"publish"`
+}
+
+// AssetRule rewrites asset URLs starting with Prefix to BaseURL, e.g. local
+// image paths to the CDN they are uploaded to.
+type AssetRule struct {
+	Prefix  string `yaml:"prefix"`
+	BaseURL string `yaml:"base_url"`
+}
+
+type ProjectSync struct {
+	Ignore []string `yaml:"ignore"`
+}
+
+var (
+	projectOnce sync.Once
+	project     *Project
+)
+
+// CurrentProject returns the project config of the working directory, or nil
+// outside of a project. An invalid project file is reported once and ignored.
+func CurrentProject() *Project {
+	projectOnce.Do(func() {
+		dir, err := os.Getwd()
+		if err == nil {
+			project, err = FindProject(dir)
+		}
+		if err != nil {
+			slog.Warn("ignoring project config", "error", err)
+		}
+	})
+	return project
+}
+
+// FindProject looks for .quail.yaml in dir and its parents.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return LoadProject(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]any)
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for key := range raw {
+		if containsCredential(key, raw[key]) {
+			return nil, fmt.Errorf("%s: credentials are not allowed in %s, use quail-cli login or QUAIL_API_KEY", path, ProjectFileName)
+		}
+		if !contains(projectKeys, key) {
+			return nil, fmt.Errorf("%s: unknown key %q", path, key)
+		}
+	}
+
+	p := &Project{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
+
+// Dir is the project root, the directory of .quail.yaml.
+func (p *Project) Dir() string {
+	return filepath.Dir(p.Path)
+}
+
+// ContentDir is the directory posts are kept in.
+func (p *Project) ContentDir() string {
+	if p.ContentRoot == "" {
+		return p.Dir()
+	}
+	if filepath.IsAbs(p.ContentRoot) {
+		return p.ContentRoot
+	}
+	return filepath.Join(p.Dir(), p.ContentRoot)
+}
+
+func (p *Project) Preset(name string) (Preset, error) {
+	if preset, ok := p.Presets[name]; ok {
+		return preset, nil
+	}
+	names := make([]string, 0, len(p.Presets))
+	for name := range p.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return Preset{}, fmt.Errorf("unknown preset %q, %s has: %s", name, p.Path, strings.Join(names, ", "))
+}
+
+// RewriteAssetURL applies the first asset rule matching url.
+func (p *Project) RewriteAssetURL(url string) string {
+	for _, rule := range p.Assets {
+		if rule.Prefix != "" && strings.HasPrefix(url, rule.Prefix) {
+			return rule.BaseURL + strings.TrimPrefix(url, rule.Prefix)
+		}
+	}
+	return url
+}
+
+// Ignored reports whether path matches one of the sync ignore patterns.
+// Patterns use filepath.Match syntax and are relative to the project root.
+// A pattern without a slash matches a file or directory name at any depth.
+func (p *Project) Ignored(path string) bool {
+	rel, err := filepath.Rel(p.Dir(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range p.Sync.Ignore {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if !strings.Contains(pattern, "/") {
+			for _, part := range strings.Split(rel, "/") {
+				if ok, _ := filepath.Match(pattern, part); ok {
+					return true
+				}
+			}
+			continue
+		}
+		if ok, _ := filepath.Match(strings.TrimPrefix(pattern, "/"), rel); ok {
+			return true
+		}
+		if strings.HasPrefix(rel, strings.TrimPrefix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// apply layers the project settings over the user config.
+func (p *Project) apply(c *Config) {
+	if p.List != "" {
+		c.Post.List = p.List
+	}
+	if len(p.FrontmatterMapping) > 0 {
+		mapping := make(map[string]string, len(c.Post.FrontmatterMapping)+len(p.FrontmatterMapping))
+		for k, v := range c.Post.FrontmatterMapping {
+			mapping[k] = v
+		}
+		for k, v := range p.FrontmatterMapping {
+			mapping[k] = v
+		}
+		c.Post.FrontmatterMapping = mapping
+	}
+	c.Project = p
+}
+
+func containsCredential(key string, value any) bool {
+	if contains(credentialNames, strings.ToLower(key)) {
+		return true
+	}
+	switch v := value.(type) {
+	case map[any]any:
+		for k, val := range v {
+			if s, ok := k.(string); ok && containsCredential(s, val) {
+				return true
+			}
+		}
+	case []any:
+		for _, val := range v {
+			if containsCredential("", val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(items []string, item string) bool {
+	for _, it := range items {
+		if it == item {
+			return true
+		}
+	}
+	return false
+}
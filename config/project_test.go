@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeProject(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, ProjectFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestFindProjectWalksUp(t *testing.T) {
+	root := t.TempDir()
+	path := writeProject(t, root, "list: my-list\ncontent_root: posts\n")
+	nested := filepath.Join(root, "posts", "2026")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	p, err := FindProject(nested)
+	if err != nil {
+		t.Fatalf("FindProject() error = %v", err)
+	}
+	if p == nil || p.Path != path || p.List != "my-list" {
+		t.Fatalf("FindProject() = %+v, want %s", p, path)
+	}
+	if p.ContentDir() != filepath.Join(root, "posts") {
+		t.Fatalf("ContentDir() = %q", p.ContentDir())
+	}
+
+	if p, err := FindProject(t.TempDir()); err != nil || p != nil {
+		t.Fatalf("FindProject() outside a project = %+v, %v; want nil, nil", p, err)
+	}
+}
+
+func TestLoadProjectRejectsCredentials(t *testing.T) {
+	for _, content := range []string{
+		"app:\n  api_key: QK-secret\n",
+		"list: foo\npresets:\n  weekly:\n    api_key: QK-secret\n",
+	} {
+		path := writeProject(t, t.TempDir(), content)
+		_, err := LoadProject(path)
+		if err == nil || !strings.Contains(err.Error(), "credentials") {
+			t.Fatalf("LoadProject(%q) error = %v, want credentials error", content, err)
+		}
+	}
+
+	path := writeProject(t, t.TempDir(), "lsit: foo\n")
+	if _, err := LoadProject(path); err == nil {
+		t.Fatal("LoadProject() error = nil, want unknown key error")
+	}
+}
+
+func TestProjectLayersOverUserConfig(t *testing.T) {
+	path := writeProject(t, t.TempDir(), `list: project-list
+frontmatter_mapping:
+  title: name
+presets:
+  weekly:
+    list: weekly
+    tags: digest
+assets:
+  - prefix: /images/
+    base_url: https://cdn.example.com/images/
+sync:
+  ignore:
+    - drafts
+    - "*.tmp.md"
+`)
+	p, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+
+	c := &Config{Post: Post{List: "user-list", FrontmatterMapping: map[string]string{"cover_image_url": "featureImage", "title": "heading"}}}
+	p.apply(c)
+	if c.Post.List != "project-list" {
+		t.Fatalf("List = %q, want project-list", c.Post.List)
+	}
+	if c.Post.FrontmatterMapping["title"] != "name" || c.Post.FrontmatterMapping["cover_image_url"] != "featureImage" {
+		t.Fatalf("FrontmatterMapping = %v", c.Post.FrontmatterMapping)
+	}
+
+	if preset, err := p.Preset("weekly"); err != nil || preset.List != "weekly" {
+		t.Fatalf("Preset() = %+v, %v", preset, err)
+	}
+	if _, err := p.Preset("daily"); err == nil {
+		t.Fatal("Preset() error = nil for unknown preset")
+	}
+
+	if got := p.RewriteAssetURL("/images/a.png"); got != "https://cdn.example.com/images/a.png" {
+		t.Fatalf("RewriteAssetURL() = %q", got)
+	}
+	if got := p.RewriteAssetURL("https://example.com/a.png"); got != "https://example.com/a.png" {
+		t.Fatalf("RewriteAssetURL() = %q, want unchanged", got)
+	}
+
+	dir := p.Dir()
+	if !p.Ignored(filepath.Join(dir, "posts", "drafts", "a.md")) || !p.Ignored(filepath.Join(dir, "b.tmp.md")) {
+		t.Fatal("Ignored() = false for ignored paths")
+	}
+	if p.Ignored(filepath.Join(dir, "posts", "a.md")) {
+		t.Fatal("Ignored() = true for a published post")
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCacheMaxAge  = 30 * 24 * time.Hour
+	DefaultCacheMaxSize = 200 << 20
+)
+
+type Reader struct {
+	// CacheMaxAge and CacheMaxSize bound the offline cache of `reader sync`,
+	// like "30d" and "200MB". Empty means the default.
+	CacheMaxAge  string `mapstructure:"cache_max_age"`
+	CacheMaxSize string `mapstructure:"cache_max_size"`
+	// CustomDomains maps the custom domains of lists to list IDs or slugs,
+	// like "blog.example.com=weekly,news.example.org=42", to read URLs on
+	// these domains.
+	CustomDomains string `mapstructure:"custom_domains"`
+}
+
+// Domains returns the custom domains of lists, by lower case domain.
+func (r Reader) Domains() map[string]string {
+	domains, _ := parseDomains(r.CustomDomains)
+	return domains
+}
+
+func parseDomains(s string) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		domain, list, ok := strings.Cut(pair, "=")
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		list = strings.TrimSpace(list)
+		if !ok || domain == "" || list == "" || strings.ContainsAny(domain, "/: ") {
+			return nil, fmt.Errorf("invalid custom domain %q, use domain=list like blog.example.com=weekly", strings.TrimSpace(pair))
+		}
+		ret[domain] = list
+	}
+	return ret, nil
+}
+
+func checkDomains(s string) error {
+	_, err := parseDomains(s)
+	return err
+}
+
+// MaxAge returns the age after which cached posts are evicted.
+func (r Reader) MaxAge() time.Duration {
+	if d, err := ParseAge(r.CacheMaxAge); err == nil && r.CacheMaxAge != "" {
+		return d
+	}
+	return DefaultCacheMaxAge
+}
+
+// MaxSize returns the size in bytes the cache is kept under.
+func (r Reader) MaxSize() int64 {
+	if n, err := ParseSize(r.CacheMaxSize); err == nil && r.CacheMaxSize != "" {
+		return n
+	}
+	return DefaultCacheMaxSize
+}
+
+// ParseAge parses a duration like "12h" or "30d". Days aren't Go duration
+// units, but cache ages are mostly counted in days.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q, use a duration like 12h or 30d", s)
+	}
+	return d, nil
+}
+
+// ParseDate parses a date like 2006-01-02, or an RFC 3339 time. A date used
+// as the upper bound of a window, end, includes the whole day.
+func ParseDate(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date like 2006-01-02", s)
+	}
+	if end {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// ParseSince parses the start of a window, an age like 7d or 12h that ends
+// now, or a date for ParseDate.
+func ParseSince(s string) (time.Time, error) {
+	if age, err := ParseAge(s); err == nil && s != "" {
+		return time.Now().Add(-age), nil
+	}
+	return ParseDate(s, false)
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10}, {"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}, {"b", 1},
+}
+
+// ParseSize parses a size like "200MB" or "1g". Units are powers of 1024.
+func ParseSize(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if n, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, unit = strings.TrimSpace(n), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, use a size like 200MB", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+func checkAge(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	_, err := ParseAge(value)
+	return err
+}
+
+func checkSize(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	_, err := ParseSize(value)
+	return err
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindString    Kind = "string"
+	KindInt       Kind = "int"
+	KindBool      Kind = "bool"
+	KindTime      Kind = "time"
+	KindStringMap Kind = "map"
+)
+
+// Key describes a config key. Keys of KindStringMap accept any sub key, like
+// post.frontmatter_mapping.cover_image_url.
+type Key struct {
+	Name        string
+	Kind        Kind
+	Secret      bool
+	Description string
+	Check       func(value string) error
+}
+
+var Keys = []Key{
+	{Name: "app.api_key", Kind: KindString, Secret: true, Description: "Quaily API key", Check: checkAPIKey},
+	{Name: "app.access_token", Kind: KindString, Secret: true, Description: "OAuth access token"},
+	{Name: "app.refresh_token", Kind: KindString, Secret: true, Description: "OAuth refresh token"},
+	{Name: "app.token_type", Kind: KindString, Description: "OAuth token type"},
+	{Name: "app.expiry", Kind: KindTime, Description: "OAuth access token expiry"},
+	{Name: "app.scope", Kind: KindString, Description: "OAuth scopes granted at login"},
+	{Name: "app.user.id", Kind: KindInt, Description: "ID of the logged in user"},
+	{Name: "app.user.name", Kind: KindString, Description: "name of the logged in user"},
+	{Name: "app.user.bio", Kind: KindString, Description: "bio of the logged in user"},
+	{Name: "post.list", Kind: KindString, Description: "default list id or slug for post commands"},
+	{Name: "post.frontmatter_mapping", Kind: KindStringMap, Description: "map of Quaily post fields to frontmatter keys"},
+	{Name: "reader.cache_max_age", Kind: KindString, Description: "evict posts cached by reader sync after this age, like 30d", Check: checkAge},
+	{Name: "reader.cache_max_size", Kind: KindString, Description: "keep the reader cache under this size, like 200MB", Check: checkSize},
+	{Name: "reader.custom_domains", Kind: KindString, Description: "custom domains of lists, like blog.example.com=weekly,news.example.org=42", Check: checkDomains},
+}
+
+// Lookup finds the key for name. For sub keys of a map, it returns the map
+// key and the sub key.
+func Lookup(name string) (Key, string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, "", true
+		}
+		if key.Kind == KindStringMap && strings.HasPrefix(name, key.Name+".") {
+			sub := strings.TrimPrefix(name, key.Name+".")
+			if sub != "" && !strings.Contains(sub, ".") {
+				return key, sub, true
+			}
+		}
+	}
+	return Key{}, "", false
+}
+
+// Parse converts a command line value to the type of the key.
+func (k Key) Parse(raw string) (any, error) {
+	if k.Check != nil {
+		if err := k.Check(raw); err != nil {
+			return nil, err
+		}
+	}
+	switch k.Kind {
+	case KindInt:
+		n, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a non-negative integer", k.Name)
+		}
+		return n, nil
+	case KindBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", k.Name)
+		}
+		return b, nil
+	case KindTime:
+		t, err := parseTime(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 time", k.Name)
+		}
+		if t.IsZero() {
+			return "", nil
+		}
+		return t, nil
+	}
+	return raw, nil
+}
+
+// Validate checks a value read from the config file.
+func (k Key) Validate(value any) error {
+	switch k.Kind {
+	case KindString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string, got %v", k.Name, value)
+		}
+		if k.Check != nil {
+			return k.Check(s)
+		}
+	case KindInt:
+		switch v := value.(type) {
+		case int, int64, uint64:
+		case float64:
+			if v != float64(int64(v)) || v < 0 {
+				return fmt.Errorf("%s must be a non-negative integer, got %v", k.Name, value)
+			}
+		case string:
+			if _, err := k.Parse(v); err != nil && v != "" {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s must be an integer, got %v", k.Name, value)
+		}
+	case KindBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be true or false, got %v", k.Name, value)
+		}
+	case KindTime:
+		switch v := value.(type) {
+		case time.Time:
+		case string:
+			if _, err := parseTime(v); err != nil {
+				return fmt.Errorf("%s must be an RFC 3339 time, got %q", k.Name, v)
+			}
+		default:
+			return fmt.Errorf("%s must be an RFC 3339 time, got %v", k.Name, value)
+		}
+	case KindStringMap:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s must be a map of strings", k.Name)
+		}
+		for sub, v := range m {
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("%s.%s must be a string, got %v", k.Name, sub, v)
+			}
+		}
+	}
+	return nil
+}
+
+// Issue is a problem found by Validate.
+type Issue struct {
+	Key        string `json:"key"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// ValidateSettings checks the nested settings of a config file for unknown
+// keys and values of the wrong type.
+func ValidateSettings(settings map[string]any) []Issue {
+	issues := make([]Issue, 0)
+	for _, name := range sortedKeys(settings) {
+		issues = append(issues, validateNode(name, settings[name])...)
+	}
+	return issues
+}
+
+func validateNode(name string, value any) []Issue {
+	if key, sub, ok := Lookup(name); ok {
+		if sub != "" {
+			if _, ok := value.(string); !ok {
+				return []Issue{{Key: name, Message: fmt.Sprintf("%s must be a string, got %v", name, value)}}
+			}
+			return nil
+		}
+		if err := key.Validate(value); err != nil {
+			return []Issue{{Key: name, Message: err.Error()}}
+		}
+		return nil
+	}
+
+	if m, ok := value.(map[string]any); ok && isPrefix(name) {
+		issues := make([]Issue, 0)
+		for _, sub := range sortedKeys(m) {
+			issues = append(issues, validateNode(name+"."+sub, m[sub])...)
+		}
+		return issues
+	}
+
+	return []Issue{{Key: name, Message: "unknown key", Suggestion: Suggest(name)}}
+}
+
+// isPrefix tells whether name is a section that holds known keys.
+func isPrefix(name string) bool {
+	for _, key := range Keys {
+		if strings.HasPrefix(key.Name, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest returns the known key closest to name, if any is close enough to
+// be a typo.
+func Suggest(name string) string {
+	best := ""
+	bestDistance := 0
+	for _, key := range Keys {
+		if d := levenshtein(name, key.Name); best == "" || d < bestDistance {
+			best, bestDistance = key.Name, d
+		}
+	}
+	if bestDistance > 3 || bestDistance > len(name)/3 {
+		return ""
+	}
+	return best
+}
+
+func checkAPIKey(value string) error {
+	value = strings.TrimSpace(value)
+	if value != "" && !strings.HasPrefix(value, "QK-") {
+		return fmt.Errorf("app.api_key must start with QK-")
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
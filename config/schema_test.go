@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidateSettingsSuggestsKnownKey(t *testing.T) {
+	issues := ValidateSettings(map[string]any{
+		"app": map[string]any{
+			"api_key": "QK-test",
+			"user":    map[string]any{"id": 1, "name": "lyric"},
+		},
+		"post": map[string]any{
+			"frontmatter_maping": map[string]any{"cover_image_url": "featureImage"},
+		},
+	})
+	if len(issues) != 1 {
+		t.Fatalf("ValidateSettings() = %+v, want 1 issue", issues)
+	}
+	if issues[0].Key != "post.frontmatter_maping" || issues[0].Suggestion != "post.frontmatter_mapping" {
+		t.Fatalf("issue = %+v, want suggestion for frontmatter_mapping", issues[0])
+	}
+}
+
+func TestValidateSettingsChecksTypes(t *testing.T) {
+	issues := ValidateSettings(map[string]any{
+		"app": map[string]any{
+			"api_key": "not-a-key",
+			"expiry":  "tomorrow",
+			"user":    map[string]any{"id": "abc"},
+		},
+		"post": map[string]any{
+			"frontmatter_mapping": map[string]any{"title": 1},
+		},
+	})
+	want := map[string]bool{
+		"app.api_key":              true,
+		"app.expiry":               true,
+		"app.user.id":              true,
+		"post.frontmatter_mapping": true,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("ValidateSettings() = %+v, want %d issues", issues, len(want))
+	}
+	for _, issue := range issues {
+		if !want[issue.Key] {
+			t.Fatalf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLookupMapSubKey(t *testing.T) {
+	key, sub, ok := Lookup("post.frontmatter_mapping.cover_image_url")
+	if !ok || key.Name != "post.frontmatter_mapping" || sub != "cover_image_url" {
+		t.Fatalf("Lookup() = %v, %q, %v", key.Name, sub, ok)
+	}
+	if _, _, ok := Lookup("post.frontmatter_mapping.a.b"); ok {
+		t.Fatal("Lookup() accepted a nested sub key")
+	}
+}
+
+func TestFromViper(t *testing.T) {
+	v := viper.New()
+	v.Set("app.expiry", "2030-01-02T03:04:05Z")
+	v.Set("app.user.id", 42)
+	v.Set("post.frontmatter_mapping", map[string]any{"cover_image_url": "featureImage"})
+
+	c, err := FromViper(v)
+	if err != nil {
+		t.Fatalf("FromViper() error = %v", err)
+	}
+	if !c.App.Expiry.Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("Expiry = %v", c.App.Expiry)
+	}
+	if c.App.User.ID != 42 || c.Post.FrontmatterMapping["cover_image_url"] != "featureImage" {
+		t.Fatalf("FromViper() = %+v", c)
+	}
+
+	v.Set("app.expiry", "")
+	if c, err := FromViper(v); err != nil || !c.App.Expiry.IsZero() {
+		t.Fatalf("FromViper() with empty expiry = %v, %v", c.App.Expiry, err)
+	}
+}
+
+func TestReaderDomains(t *testing.T) {
+	got := Reader{CustomDomains: "Blog.Example.com=weekly, www.news.example.org = 42"}.Domains()
+	if len(got) != 2 || got["blog.example.com"] != "weekly" || got["news.example.org"] != "42" {
+		t.Errorf("Domains() = %v", got)
+	}
+	if err := checkDomains("https://blog.example.com=weekly"); err == nil {
+		t.Error("checkDomains() accepted a URL")
+	}
+}
@@ -0,0 +1,179 @@
+// Package digest renders digests of posts, grouped by list, from Markdown or
+// HTML templates.
+package digest
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/markdown"
+)
+
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+)
+
+// FormatOf tells the format of a file from its extension, Markdown unless it
+// is .html or .htm.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return HTML
+	}
+	return Markdown
+}
+
+// Digest is the data of digest templates.
+type Digest struct {
+	Title     string
+	Since     time.Time
+	Until     time.Time
+	Generated time.Time
+	// Count is the number of posts.
+	Count int
+	Lists []List
+}
+
+// List is the posts of a list, the newest first.
+type List struct {
+	client.List
+	Posts []Post
+}
+
+type Post struct {
+	client.Post
+	URL string
+	// Excerpt is the first paragraphs of the post, as plain text.
+	Excerpt []string
+	// ExcerptHTML is the same paragraphs as HTML.
+	ExcerptHTML htmltemplate.HTML
+}
+
+// New groups posts by list. Lists are ordered by their latest post, the
+// newest first. content returns the Markdown of a post to take paragraphs
+// of an excerpt from, or "" for no excerpt.
+func New(title string, since, until time.Time, posts []client.Post, paragraphs int, content func(client.Post) string) *Digest {
+	d := &Digest{Title: title, Since: since, Until: until, Generated: time.Now(), Count: len(posts)}
+	sorted := append([]client.Post(nil), posts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].PublishedAt.After(sorted[j].PublishedAt) })
+
+	index := map[uint64]int{}
+	for _, p := range sorted {
+		i, ok := index[p.ListID]
+		if !ok {
+			i = len(d.Lists)
+			index[p.ListID] = i
+			l := p.List
+			if l.Title == "" {
+				l.Title = p.List.Slug
+			}
+			d.Lists = append(d.Lists, List{List: l})
+		}
+		post := Post{Post: p, URL: p.URL()}
+		if paragraphs > 0 && content != nil {
+			post.Excerpt, post.ExcerptHTML = excerpt(content(p), paragraphs)
+		}
+		d.Lists[i].Posts = append(d.Lists[i].Posts, post)
+	}
+	return d
+}
+
+// excerpt returns the first n paragraphs of the Markdown src.
+func excerpt(src string, n int) ([]string, htmltemplate.HTML) {
+	doc := &markdown.Document{}
+	var text []string
+	for _, b := range markdown.Parse(src).Blocks {
+		if len(text) == n {
+			break
+		}
+		if b.Kind == markdown.Paragraph {
+			doc.Blocks = append(doc.Blocks, b)
+			text = append(text, markdown.PlainText(b.Inlines))
+		}
+	}
+	var buf strings.Builder
+	markdown.RenderHTML(&buf, doc, markdown.HTMLOptions{SkipHTML: true})
+	return text, htmltemplate.HTML(buf.String())
+}
+
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("2006-01-02")
+	},
+}
+
+const markdownTemplate = `# {{.Title}}
+
+{{.Count}} post(s) from {{date .Since}} to {{date .Until}}.
+{{range .Lists}}
+## {{.Title}}
+{{range .Posts}}
+### [{{.Title}}]({{.URL}})
+
+{{date .PublishedAt}}{{if .Summary}} · {{.Summary}}{{end}}
+{{range .Excerpt}}
+> {{.}}
+{{end}}{{end}}{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Count}} post(s) from {{date .Since}} to {{date .Until}}.</p>
+{{range .Lists}}<h2>{{.Title}}</h2>
+{{range .Posts}}<article>
+<h3><a href="{{.URL}}">{{.Title}}</a></h3>
+<p><small>{{date .PublishedAt}}</small>{{if .Summary}} {{.Summary}}{{end}}</p>
+{{if .ExcerptHTML}}<blockquote>
+{{.ExcerptHTML}}</blockquote>
+{{end}}</article>
+{{end}}{{end}}</body>
+</html>
+`
+
+// Render writes d in the format, with the template in the file at
+// templatePath, or the default template when it is empty. HTML templates
+// escape what they print like html/template.
+func Render(w io.Writer, d *Digest, format Format, templatePath string) error {
+	text := markdownTemplate
+	if format == HTML {
+		text = htmlTemplate
+	}
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+
+	if format == HTML {
+		t, err := htmltemplate.New("digest").Funcs(htmltemplate.FuncMap(funcs)).Parse(text)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, d)
+	}
+	t, err := template.New("digest").Funcs(funcs).Parse(text)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, d)
+}
@@ -0,0 +1,73 @@
+package digest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+func testDigest(paragraphs int) *Digest {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	weekly := client.List{ID: 1, Slug: "weekly", Title: "Weekly"}
+	posts := []client.Post{
+		{ID: 1, Slug: "a", Title: "Old news", ListID: 1, List: weekly, PublishedAt: day(2)},
+		{ID: 2, Slug: "b", Title: "Soup & bread", Summary: "A recipe", ListID: 2, List: client.List{ID: 2, Slug: "kitchen", Title: "Kitchen"}, PublishedAt: day(3)},
+		{ID: 3, Slug: "c", Title: "New news", ListID: 1, List: weekly, PublishedAt: day(4)},
+	}
+	content := func(p client.Post) string {
+		return "# Heading\n\nFirst *paragraph*.\n\nSecond one.\n\nThird one.\n"
+	}
+	return New("Digest", day(1), day(7), posts, paragraphs, content)
+}
+
+func TestNewGroupsByList(t *testing.T) {
+	d := testDigest(2)
+	if len(d.Lists) != 2 || d.Lists[0].Title != "Weekly" || d.Lists[1].Title != "Kitchen" {
+		t.Fatalf("lists = %+v, want Weekly then Kitchen", d.Lists)
+	}
+	if p := d.Lists[0].Posts; len(p) != 2 || p[0].Title != "New news" || p[0].URL != "https://quaily.com/weekly/p/c" {
+		t.Errorf("weekly posts = %+v, want the newest first", p)
+	}
+	if got := d.Lists[1].Posts[0].Excerpt; len(got) != 2 || got[0] != "First paragraph." {
+		t.Errorf("excerpt = %q, want the first 2 paragraphs", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	var md strings.Builder
+	if err := Render(&md, testDigest(1), Markdown, ""); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"# Digest\n", "## Weekly\n", "### [Soup & bread](https://quaily.com/kitchen/p/b)", "2026-03-03 · A recipe", "> First paragraph.\n"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown doesn't contain %q:\n%s", want, md.String())
+		}
+	}
+
+	var html strings.Builder
+	if err := Render(&html, testDigest(1), HTML, ""); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{`<a href="https://quaily.com/kitchen/p/b">Soup &amp; bread</a>`, "<p>First <em>paragraph</em>.</p>"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("html doesn't contain %q:\n%s", want, html.String())
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "custom.tmpl")
+	os.WriteFile(path, []byte(`{{range .Lists}}{{.Title}}:{{range .Posts}} {{.ID}}{{end}};{{end}}`), 0600)
+	var custom strings.Builder
+	if err := Render(&custom, testDigest(0), Markdown, path); err != nil || custom.String() != "Weekly: 3 1;Kitchen: 2;" {
+		t.Errorf("Render() with a template = %q, %v", custom.String(), err)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	if FormatOf("digest.HTML") != HTML || FormatOf("digest.md") != Markdown || FormatOf("") != Markdown {
+		t.Error("FormatOf() is wrong")
+	}
+}
@@ -0,0 +1,314 @@
+// Package epub writes EPUB 3 books of Markdown chapters, for e-readers.
+//
+// Books have a table of contents for EPUB 3 readers and an NCX one for older
+// readers, and embed the images of their chapters, as EPUB readers don't load
+// remote images.
+package epub
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/markdown"
+)
+
+// Fetcher downloads the image at url and tells its media type.
+type Fetcher func(url string) (data []byte, mediaType string, err error)
+
+type Book struct {
+	// ID is the unique identifier of the book, like a urn:uuid: URI. A book
+	// exported again should keep its ID, so that readers replace it.
+	ID          string
+	Title       string
+	Author      string
+	Language    string
+	Description string
+	Modified    time.Time
+	// Fetch downloads images. Images that are not fetched, or when Fetch is
+	// nil, are replaced by their alt text.
+	Fetch Fetcher
+	// OnImageError is called with the images that couldn't be fetched.
+	OnImageError func(url string, err error)
+
+	chapters []chapter
+	images   []image
+	imageMap map[string]string
+}
+
+type chapter struct {
+	title  string
+	byline string
+	body   string
+}
+
+type image struct {
+	href      string
+	mediaType string
+	data      []byte
+}
+
+// mediaTypes are the image types of EPUB 3, and their file extensions.
+var mediaTypes = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+// AddChapter adds a chapter of Markdown content. byline is shown under the
+// title, like the author and date of a post. HTML in src is left out, as it
+// may not be valid XHTML.
+func (b *Book) AddChapter(title, byline, src string) {
+	doc := markdown.Parse(src)
+	b.embedImages(doc.Blocks)
+	var body strings.Builder
+	markdown.RenderHTML(&body, doc, markdown.HTMLOptions{SkipHTML: true})
+	b.chapters = append(b.chapters, chapter{title: title, byline: byline, body: body.String()})
+}
+
+// embedImages points images to fetched copies in the book.
+func (b *Book) embedImages(blocks []*markdown.Block) {
+	for _, bl := range blocks {
+		b.embedInlineImages(bl.Inlines)
+		b.embedImages(bl.Children)
+	}
+}
+
+func (b *Book) embedInlineImages(inlines []markdown.Inline) {
+	for i := range inlines {
+		in := &inlines[i]
+		if in.Kind != markdown.Image {
+			b.embedInlineImages(in.Children)
+			continue
+		}
+		href, ok := b.image(in.URL)
+		if !ok {
+			in.Kind, in.Text = markdown.Text, imageAlt(in.Text)
+			continue
+		}
+		in.URL = href
+	}
+}
+
+func imageAlt(alt string) string {
+	if alt == "" {
+		return "[image]"
+	}
+	return "[image: " + alt + "]"
+}
+
+// image returns the path in the book of the image at url, fetching it the
+// first time.
+func (b *Book) image(url string) (string, bool) {
+	if href, ok := b.imageMap[url]; ok {
+		return href, href != ""
+	}
+	if b.imageMap == nil {
+		b.imageMap = map[string]string{}
+	}
+	if b.Fetch == nil {
+		b.imageMap[url] = ""
+		return "", false
+	}
+
+	data, mediaType, err := b.Fetch(url)
+	if err == nil && mediaTypes[mediaType] == "" {
+		err = fmt.Errorf("unsupported image type %q", mediaType)
+	}
+	if err != nil {
+		if b.OnImageError != nil {
+			b.OnImageError(url, err)
+		}
+		b.imageMap[url] = ""
+		return "", false
+	}
+	href := fmt.Sprintf("images/image-%03d%s", len(b.images)+1, mediaTypes[mediaType])
+	b.images = append(b.images, image{href: href, mediaType: mediaType, data: data})
+	b.imageMap[url] = href
+	return href, true
+}
+
+// Len is the number of chapters.
+func (b *Book) Len() int {
+	return len(b.chapters)
+}
+
+// Write writes the book as an EPUB file.
+func (b *Book) Write(w io.Writer) error {
+	z := zip.NewWriter(w)
+	// The mimetype file comes first and uncompressed, so that the type of
+	// the file can be told from its first bytes.
+	mt, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mt, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := []file{
+		{"META-INF/container.xml", []byte(containerXML)},
+		{"EPUB/package.opf", []byte(b.packageOPF())},
+		{"EPUB/nav.xhtml", []byte(b.nav())},
+		{"EPUB/toc.ncx", []byte(b.ncx())},
+		{"EPUB/style.css", []byte(styleCSS)},
+	}
+	for i, c := range b.chapters {
+		files = append(files, file{"EPUB/" + chapterHref(i), []byte(b.chapterXHTML(c))})
+	}
+	for _, img := range b.images {
+		files = append(files, file{"EPUB/" + img.href, img.data})
+	}
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+type file struct {
+	name string
+	data []byte
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="EPUB/package.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const styleCSS = `body { line-height: 1.5; }
+h1 { margin-bottom: 0.2em; }
+p.byline { color: #666; font-size: 0.9em; margin-top: 0; }
+img { max-width: 100%; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+blockquote { margin-left: 1em; padding-left: 1em; border-left: 3px solid #ccc; }
+`
+
+func chapterHref(i int) string {
+	return fmt.Sprintf("chapter-%03d.xhtml", i+1)
+}
+
+func (b *Book) language() string {
+	if b.Language == "" {
+		return "en"
+	}
+	return b.Language
+}
+
+func (b *Book) packageOPF() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="%s">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">%s</dc:identifier>
+    <dc:title>%s</dc:title>
+    <dc:language>%s</dc:language>
+`, esc(b.language()), esc(b.ID), esc(b.Title), esc(b.language()))
+	if b.Author != "" {
+		fmt.Fprintf(&s, "    <dc:creator>%s</dc:creator>\n", esc(b.Author))
+	}
+	if b.Description != "" {
+		fmt.Fprintf(&s, "    <dc:description>%s</dc:description>\n", esc(b.Description))
+	}
+	fmt.Fprintf(&s, "    <meta property=\"dcterms:modified\">%s</meta>\n", b.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	s.WriteString(`  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+`)
+	for i := range b.chapters {
+		fmt.Fprintf(&s, "    <item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, chapterHref(i))
+	}
+	for i, img := range b.images {
+		fmt.Fprintf(&s, "    <item id=\"image-%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, img.href, img.mediaType)
+	}
+	s.WriteString("  </manifest>\n  <spine toc=\"ncx\">\n")
+	for i := range b.chapters {
+		fmt.Fprintf(&s, "    <itemref idref=\"chapter-%d\"/>\n", i+1)
+	}
+	s.WriteString("  </spine>\n</package>\n")
+	return s.String()
+}
+
+func (b *Book) nav() string {
+	var s strings.Builder
+	s.WriteString(b.xhtmlHead(b.Title))
+	s.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n<h1>Contents</h1>\n<ol>\n")
+	for i, c := range b.chapters {
+		fmt.Fprintf(&s, "<li><a href=\"%s\">%s</a></li>\n", chapterHref(i), esc(c.title))
+	}
+	s.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return s.String()
+}
+
+// ncx is the table of contents of EPUB 2, which some e-readers still use.
+func (b *Book) ncx() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="%s"/>
+  </head>
+  <docTitle><text>%s</text></docTitle>
+  <navMap>
+`, esc(b.ID), esc(b.Title))
+	for i, c := range b.chapters {
+		fmt.Fprintf(&s, "    <navPoint id=\"nav-%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/></navPoint>\n",
+			i+1, i+1, esc(c.title), chapterHref(i))
+	}
+	s.WriteString("  </navMap>\n</ncx>\n")
+	return s.String()
+}
+
+func (b *Book) chapterXHTML(c chapter) string {
+	var s strings.Builder
+	s.WriteString(b.xhtmlHead(c.title))
+	fmt.Fprintf(&s, "<section epub:type=\"chapter\">\n<h1>%s</h1>\n", esc(c.title))
+	if c.byline != "" {
+		fmt.Fprintf(&s, "<p class=\"byline\">%s</p>\n", esc(c.byline))
+	}
+	s.WriteString(c.body)
+	s.WriteString("</section>\n</body>\n</html>\n")
+	return s.String()
+}
+
+func (b *Book) xhtmlHead(title string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%[1]s" lang="%[1]s">
+<head>
+<meta charset="utf-8"/>
+<title>%[2]s</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+`, esc(b.language()), esc(title))
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
+
+// NameUUID returns a urn:uuid: URI derived from name, the same for the same
+// name, for the ID of books.
+func NameUUID(name string) string {
+	h := sha1.Sum([]byte(name))
+	h[6] = h[6]&0x0f | 0x50 // version 5
+	h[8] = h[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
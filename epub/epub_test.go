@@ -0,0 +1,97 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	var failed []string
+	b := &Book{
+		ID:       NameUUID("test"),
+		Title:    "Weekly & more",
+		Author:   "Weekly",
+		Modified: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Fetch: func(url string) ([]byte, string, error) {
+			if url == "https://img.example/a.png" {
+				return []byte("png"), "image/png", nil
+			}
+			return nil, "", errors.New("not found")
+		},
+		OnImageError: func(url string, err error) { failed = append(failed, url) },
+	}
+	b.AddChapter("First", "Weekly · 2026-03-01", "Hello ![a](https://img.example/a.png) and <b>raw</b>\n\n<div>html</div>\n")
+	b.AddChapter("Second", "", "![chart](https://img.example/b.png) ![a](https://img.example/a.png)")
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip file: %v", err)
+	}
+	if f := z.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first file = %s, method %d, want mimetype stored", f.Name, f.Method)
+	}
+
+	files := map[string]string{}
+	for _, f := range z.File {
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		files[f.Name] = string(data)
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".ncx") || strings.HasSuffix(f.Name, ".xml") {
+			d := xml.NewDecoder(strings.NewReader(string(data)))
+			for err == nil {
+				_, err = d.Token()
+			}
+			if err != io.EOF {
+				t.Errorf("%s is not well-formed: %v", f.Name, err)
+			}
+			err = nil
+		}
+	}
+
+	if files["EPUB/images/image-001.png"] != "png" {
+		t.Errorf("image not embedded, files: %v", len(files))
+	}
+	if ch := files["EPUB/chapter-001.xhtml"]; !strings.Contains(ch, `<img src="images/image-001.png" alt="a" />`) || strings.Contains(ch, "<div>") {
+		t.Errorf("chapter 1 =\n%s", ch)
+	}
+	if ch := files["EPUB/chapter-002.xhtml"]; !strings.Contains(ch, "[image: chart]") || !strings.Contains(ch, "images/image-001.png") {
+		t.Errorf("chapter 2 =\n%s", ch)
+	}
+	if len(failed) != 1 {
+		t.Errorf("failed images = %v, want b.png once", failed)
+	}
+	opf := files["EPUB/package.opf"]
+	for _, want := range []string{
+		`<dc:title>Weekly &amp; more</dc:title>`,
+		`<meta property="dcterms:modified">2026-03-01T00:00:00Z</meta>`,
+		`properties="nav"`,
+		`<item id="image-1" href="images/image-001.png" media-type="image/png"/>`,
+		`<itemref idref="chapter-2"/>`,
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("package.opf doesn't contain %s:\n%s", want, opf)
+		}
+	}
+	if !strings.Contains(files["EPUB/nav.xhtml"], `<a href="chapter-002.xhtml">Second</a>`) {
+		t.Errorf("nav.xhtml =\n%s", files["EPUB/nav.xhtml"])
+	}
+}
+
+func TestNameUUID(t *testing.T) {
+	if NameUUID("a") != NameUUID("a") || NameUUID("a") == NameUUID("b") {
+		t.Error("NameUUID() is not stable per name")
+	}
+	if got := NameUUID("a"); len(got) != len("urn:uuid:")+36 || got[len("urn:uuid:")+14] != '5' {
+		t.Errorf("NameUUID() = %s", got)
+	}
+}
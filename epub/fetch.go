@@ -0,0 +1,51 @@
+package epub
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxImageSize bounds the images embedded in books.
+const maxImageSize = 20 << 20
+
+// HTTPFetcher downloads images over HTTP. The media type is the one the
+// server sends, or else told from the content and the file extension.
+func HTTPFetcher() Fetcher {
+	hc := &http.Client{Timeout: 30 * time.Second}
+	return func(url string) ([]byte, string, error) {
+		resp, err := hc.Get(url)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+		if err != nil {
+			return nil, "", err
+		}
+		if len(data) > maxImageSize {
+			return nil, "", fmt.Errorf("image is larger than %d MB", maxImageSize>>20)
+		}
+		return data, mediaType(resp.Header.Get("Content-Type"), url, data), nil
+	}
+}
+
+func mediaType(header, url string, data []byte) string {
+	if t, _, err := mime.ParseMediaType(header); err == nil && strings.HasPrefix(t, "image/") {
+		return t
+	}
+	if t := http.DetectContentType(data); strings.HasPrefix(t, "image/") {
+		return t
+	}
+	if strings.EqualFold(path.Ext(strings.SplitN(url, "?", 2)[0]), ".svg") {
+		return "image/svg+xml"
+	}
+	return ""
+}
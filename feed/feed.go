@@ -0,0 +1,352 @@
+// Package feed writes posts as Atom, RSS 2.0 or JSON Feed documents, for feed
+// readers.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/markdown"
+)
+
+type Format string
+
+const (
+	Atom     Format = "atom"
+	RSS      Format = "rss"
+	JSONFeed Format = "jsonfeed"
+)
+
+var Formats = []string{string(Atom), string(RSS), string(JSONFeed)}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Atom, RSS, JSONFeed:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown feed format %q, want one of %s", s, strings.Join(Formats, ", "))
+}
+
+// ContentType is the media type of documents in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case RSS:
+		return "application/rss+xml; charset=utf-8"
+	case JSONFeed:
+		return "application/feed+json; charset=utf-8"
+	}
+	return "application/atom+xml; charset=utf-8"
+}
+
+type Feed struct {
+	// ID identifies the feed, it must not change between builds.
+	ID          string
+	Title       string
+	Description string
+	// Link is the web page of the feed, and SelfURL where the feed itself
+	// is served, when it is.
+	Link    string
+	SelfURL string
+	Updated time.Time
+	Items   []Item
+}
+
+type Item struct {
+	// ID identifies the item, it must not change when the post is edited.
+	ID      string
+	URL     string
+	Title   string
+	Author  string
+	Summary string
+	// Content is HTML.
+	Content   string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+// PostID is the ID of the item of a post. It is a tag URI, RFC 4151, which
+// stays the same when the post is renamed or moved.
+func PostID(postID uint64) string {
+	return "tag:quaily.com,2020:post/" + strconv.FormatUint(postID, 10)
+}
+
+// PostItem makes the item of a post. content is nil when the user has no
+// access to the post, and the item has the summary only.
+func PostItem(p client.Post, content *client.PostContent) Item {
+	item := Item{
+		ID:        PostID(p.ID),
+		URL:       p.URL(),
+		Title:     p.Title,
+		Author:    p.List.Title,
+		Summary:   p.Summary,
+		Published: p.FirstPublishedAt,
+		Updated:   p.PublishedAt,
+	}
+	if item.Published.IsZero() {
+		item.Published = p.PublishedAt
+	}
+	if item.Updated.Before(item.Published) {
+		item.Updated = item.Published
+	}
+	for _, tag := range strings.Split(p.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+	if content != nil {
+		item.Content = markdown.HTML(content.FreeContent)
+		if content.PaidContent != "" {
+			item.Content += "<hr />\n" + markdown.HTML(content.PaidContent)
+		}
+	}
+	return item
+}
+
+// Write writes f in the format.
+func Write(w io.Writer, f *Feed, format Format) error {
+	switch format {
+	case RSS:
+		return writeXML(w, rssOf(f))
+	case JSONFeed:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(jsonFeedOf(f))
+	}
+	return writeXML(w, atomOf(f))
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Atom, RFC 4287.
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomContent   `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func atomOf(f *Feed) *atomFeed {
+	ret := &atomFeed{ID: f.ID, Title: f.Title, Updated: atomTime(f.Updated)}
+	if f.Link != "" {
+		ret.Links = append(ret.Links, atomLink{Rel: "alternate", Href: f.Link})
+	}
+	if f.SelfURL != "" {
+		ret.Links = append(ret.Links, atomLink{Rel: "self", Href: f.SelfURL})
+	}
+	for _, it := range f.Items {
+		e := atomEntry{
+			ID:      it.ID,
+			Title:   it.Title,
+			Links:   []atomLink{{Rel: "alternate", Href: it.URL}},
+			Updated: atomTime(it.Updated),
+			Summary: it.Summary,
+		}
+		if !it.Published.IsZero() {
+			e.Published = atomTime(it.Published)
+		}
+		// Atom requires an author, in the entry or the feed.
+		e.Author = &atomAuthor{Name: it.Author}
+		if it.Author == "" {
+			e.Author.Name = "Quaily"
+		}
+		for _, tag := range it.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: tag})
+		}
+		if it.Content != "" {
+			e.Content = &atomContent{Type: "html", Body: it.Content}
+		}
+		ret.Entries = append(ret.Entries, e)
+	}
+	return ret
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// RSS 2.0.
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          *atomLink `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Author      string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func rssOf(f *Feed) *rssFeed {
+	ch := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+	}
+	if f.SelfURL != "" {
+		ch.Self = &atomLink{Rel: "self", Href: f.SelfURL}
+	}
+	for _, it := range f.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        rssGUID{IsPermaLink: "false", Value: it.ID},
+			Author:      it.Author,
+			Categories:  it.Tags,
+			Description: it.Summary,
+			Content:     it.Content,
+		}
+		if !it.Published.IsZero() {
+			item.PubDate = it.Published.UTC().Format(time.RFC1123Z)
+		}
+		ch.Items = append(ch.Items, item)
+	}
+	return &rssFeed{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel:   ch,
+	}
+}
+
+// JSON Feed 1.1, https://www.jsonfeed.org/version/1.1/.
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func jsonFeedOf(f *Feed) *jsonFeed {
+	ret := &jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.SelfURL,
+		Description: f.Description,
+		Items:       []jsonFeedItem{},
+	}
+	for _, it := range f.Items {
+		item := jsonFeedItem{
+			ID:          it.ID,
+			URL:         it.URL,
+			Title:       it.Title,
+			ContentHTML: it.Content,
+			Summary:     it.Summary,
+			Tags:        it.Tags,
+		}
+		// An item needs content, the summary stands in for posts the user
+		// can't read.
+		if item.ContentHTML == "" {
+			item.ContentText = it.Summary
+		}
+		if !it.Published.IsZero() {
+			item.DatePublished = atomTime(it.Published)
+		}
+		if !it.Updated.IsZero() {
+			item.DateModified = atomTime(it.Updated)
+		}
+		if it.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: it.Author}}
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return ret
+}
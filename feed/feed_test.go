@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+func testFeed() *Feed {
+	p := client.Post{
+		ID: 42, Slug: "hello", Title: "Hello & bye", Summary: "A summary", Tags: "go, notes",
+		ListID: 3, List: client.List{Slug: "weekly", Title: "Weekly"},
+		FirstPublishedAt: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+		PublishedAt:      time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+	}
+	locked := p
+	locked.ID, locked.Slug, locked.FirstPublishedAt = 43, "", time.Time{}
+	return &Feed{
+		ID: "tag:quaily.com,2020:reader/1", Title: "Subscriptions", Link: "https://quaily.com",
+		Updated: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		Items: []Item{
+			PostItem(p, &client.PostContent{FreeContent: "Hi *there*", PaidContent: "Paid part"}),
+			PostItem(locked, nil),
+		},
+	}
+}
+
+func TestPostItem(t *testing.T) {
+	items := testFeed().Items
+	if items[0].ID != "tag:quaily.com,2020:post/42" || items[0].URL != "https://quaily.com/weekly/p/hello" {
+		t.Errorf("item = %+v", items[0])
+	}
+	if want := "<p>Hi <em>there</em></p>\n<hr />\n<p>Paid part</p>\n"; items[0].Content != want {
+		t.Errorf("content = %q, want %q", items[0].Content, want)
+	}
+	if !items[0].Published.Equal(time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)) || !items[0].Updated.Equal(time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("dates = %v, %v, want the first publishing time and the last", items[0].Published, items[0].Updated)
+	}
+	if items[1].URL != "https://quaily.com/weekly/p/43" || items[1].Content != "" || !items[1].Published.Equal(items[1].Updated) {
+		t.Errorf("locked item = %+v", items[1])
+	}
+}
+
+func TestWrite(t *testing.T) {
+	for _, tc := range []struct {
+		format Format
+		want   []string
+	}{
+		{Atom, []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`<id>tag:quaily.com,2020:post/42</id>`,
+			`<published>2026-03-01T08:00:00Z</published>`,
+			`<updated>2026-03-02T08:00:00Z</updated>`,
+			`<content type="html">&lt;p&gt;Hi`,
+		}},
+		{RSS, []string{
+			`<guid isPermaLink="false">tag:quaily.com,2020:post/42</guid>`,
+			`<pubDate>Sun, 01 Mar 2026 08:00:00 +0000</pubDate>`,
+			`<category>notes</category>`,
+		}},
+		{JSONFeed, []string{
+			`"version": "https://jsonfeed.org/version/1.1"`,
+			`"date_published": "2026-03-01T08:00:00Z"`,
+			`"content_html": "<p>Hi <em>there</em></p>`,
+			`"content_text": "A summary"`,
+		}},
+	} {
+		var buf strings.Builder
+		if err := Write(&buf, testFeed(), tc.format); err != nil {
+			t.Fatalf("Write(%s) error = %v", tc.format, err)
+		}
+		got := buf.String()
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Write(%s) doesn't contain %s:\n%s", tc.format, want, got)
+			}
+		}
+		var err error
+		if tc.format == JSONFeed {
+			err = json.Unmarshal([]byte(got), &map[string]any{})
+		} else {
+			err = xml.Unmarshal([]byte(got), &struct{}{})
+		}
+		if err != nil {
+			t.Errorf("Write(%s) is not well-formed: %v", tc.format, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package markdown parses the Markdown of Quaily posts into a small AST and
+// renders it for the terminal.
+//
+// It covers what posts use in practice: ATX and setext headings, paragraphs,
+// fenced and indented code, block quotes, nested lists, thematic breaks and
+// HTML blocks, with emphasis, code spans, links, images and line breaks
+// inside them. Reference links and tables are kept as text.
+package markdown
+
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	Heading
+	CodeBlock
+	Quote
+	List
+	ListItem
+	ThematicBreak
+	HTMLBlock
+)
+
+type Block struct {
+	Kind BlockKind
+	// Level of a heading, 1 to 6.
+	Level int
+	// Ordered lists start at Start. Loose lists have blank lines between
+	// their items.
+	Ordered bool
+	Start   int
+	Loose   bool
+	// Lang is the info string of a fenced code block.
+	Lang string
+	// Text is the content of code and HTML blocks.
+	Text string
+	// Inlines are the content of paragraphs and headings.
+	Inlines []Inline
+	// Children are the blocks of quotes, lists and list items.
+	Children []*Block
+}
+
+type InlineKind int
+
+const (
+	Text InlineKind = iota
+	SoftBreak
+	HardBreak
+	Code
+	Emphasis
+	Strong
+	Strikethrough
+	Link
+	Image
+)
+
+type Inline struct {
+	Kind InlineKind
+	// Text of text and code spans, and the alt text of images.
+	Text string
+	// URL and Title of links and images.
+	URL   string
+	Title string
+	// Children of emphasis and links.
+	Children []Inline
+}
+
+type Document struct {
+	Blocks []*Block
+}
+
+// PlainText returns the text of inlines without formatting.
+func PlainText(inlines []Inline) string {
+	var buf []byte
+	for _, in := range inlines {
+		switch in.Kind {
+		case Text, Code, Image:
+			buf = append(buf, in.Text...)
+		case SoftBreak, HardBreak:
+			buf = append(buf, ' ')
+		default:
+			buf = append(buf, PlainText(in.Children)...)
+		}
+	}
+	return string(buf)
+}
+
+// Text returns the text of the document without formatting, a line per
+// block. HTML blocks are left out.
+func (d *Document) Text() string {
+	var buf []byte
+	var walk func(blocks []*Block)
+	walk = func(blocks []*Block) {
+		for _, b := range blocks {
+			switch b.Kind {
+			case Paragraph, Heading:
+				buf = append(buf, PlainText(b.Inlines)...)
+				buf = append(buf, '\n')
+			case CodeBlock:
+				buf = append(buf, b.Text...)
+				buf = append(buf, '\n')
+			default:
+				walk(b.Children)
+			}
+		}
+	}
+	walk(d.Blocks)
+	return string(buf)
+}
@@ -0,0 +1,154 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// HTMLOptions controls RenderHTML.
+type HTMLOptions struct {
+	// SkipHTML leaves out HTML blocks, which may not be well-formed.
+	SkipHTML bool
+}
+
+// RenderHTML renders doc as HTML. Empty elements are closed, like <br />, so
+// the output is also valid XHTML.
+func RenderHTML(w io.Writer, doc *Document, opts HTMLOptions) error {
+	r := &htmlRenderer{opts: opts}
+	r.blocks(doc.Blocks, false)
+	_, err := io.WriteString(w, r.buf.String())
+	return err
+}
+
+// HTML returns the HTML of the Markdown src.
+func HTML(src string) string {
+	var buf strings.Builder
+	RenderHTML(&buf, Parse(src), HTMLOptions{})
+	return buf.String()
+}
+
+type htmlRenderer struct {
+	opts HTMLOptions
+	buf  strings.Builder
+}
+
+// blocks renders blocks. The paragraphs of tight list items aren't wrapped in
+// <p>.
+func (r *htmlRenderer) blocks(blocks []*Block, tight bool) {
+	for i, b := range blocks {
+		r.block(b, tight)
+		if tight && b.Kind == Paragraph && i < len(blocks)-1 {
+			r.buf.WriteString("\n")
+		}
+	}
+}
+
+func (r *htmlRenderer) block(b *Block, tight bool) {
+	switch b.Kind {
+	case Paragraph:
+		if tight {
+			r.inlines(b.Inlines)
+			return
+		}
+		r.buf.WriteString("<p>")
+		r.inlines(b.Inlines)
+		r.buf.WriteString("</p>\n")
+
+	case Heading:
+		fmt.Fprintf(&r.buf, "<h%d>", b.Level)
+		r.inlines(b.Inlines)
+		fmt.Fprintf(&r.buf, "</h%d>\n", b.Level)
+
+	case CodeBlock:
+		r.buf.WriteString("<pre><code")
+		if lang, _, _ := strings.Cut(b.Lang, " "); lang != "" {
+			fmt.Fprintf(&r.buf, ` class="language-%s"`, html.EscapeString(lang))
+		}
+		r.buf.WriteString(">")
+		r.buf.WriteString(html.EscapeString(b.Text))
+		if b.Text != "" {
+			r.buf.WriteString("\n")
+		}
+		r.buf.WriteString("</code></pre>\n")
+
+	case HTMLBlock:
+		if !r.opts.SkipHTML {
+			r.buf.WriteString(b.Text)
+			r.buf.WriteString("\n")
+		}
+
+	case Quote:
+		r.buf.WriteString("<blockquote>\n")
+		r.blocks(b.Children, false)
+		r.buf.WriteString("</blockquote>\n")
+
+	case List:
+		switch {
+		case !b.Ordered:
+			r.buf.WriteString("<ul>\n")
+		case b.Start != 1:
+			fmt.Fprintf(&r.buf, "<ol start=\"%d\">\n", b.Start)
+		default:
+			r.buf.WriteString("<ol>\n")
+		}
+		for _, item := range b.Children {
+			r.buf.WriteString("<li>")
+			if len(item.Children) > 0 && (b.Loose || item.Children[0].Kind != Paragraph) {
+				r.buf.WriteString("\n")
+			}
+			r.blocks(item.Children, !b.Loose)
+			r.buf.WriteString("</li>\n")
+		}
+		if b.Ordered {
+			r.buf.WriteString("</ol>\n")
+		} else {
+			r.buf.WriteString("</ul>\n")
+		}
+
+	case ThematicBreak:
+		r.buf.WriteString("<hr />\n")
+	}
+}
+
+func (r *htmlRenderer) inlines(inlines []Inline) {
+	for _, in := range inlines {
+		switch in.Kind {
+		case Text:
+			r.buf.WriteString(html.EscapeString(in.Text))
+		case SoftBreak:
+			r.buf.WriteString("\n")
+		case HardBreak:
+			r.buf.WriteString("<br />\n")
+		case Code:
+			r.buf.WriteString("<code>" + html.EscapeString(in.Text) + "</code>")
+		case Emphasis:
+			r.wrap("em", in.Children)
+		case Strong:
+			r.wrap("strong", in.Children)
+		case Strikethrough:
+			r.wrap("del", in.Children)
+		case Link:
+			fmt.Fprintf(&r.buf, `<a href="%s"`, html.EscapeString(in.URL))
+			if in.Title != "" {
+				fmt.Fprintf(&r.buf, ` title="%s"`, html.EscapeString(in.Title))
+			}
+			r.buf.WriteString(">")
+			r.inlines(in.Children)
+			r.buf.WriteString("</a>")
+		case Image:
+			fmt.Fprintf(&r.buf, `<img src="%s" alt="%s"`, html.EscapeString(in.URL), html.EscapeString(in.Text))
+			if in.Title != "" {
+				fmt.Fprintf(&r.buf, ` title="%s"`, html.EscapeString(in.Title))
+			}
+			r.buf.WriteString(" />")
+		}
+	}
+}
+
+func (r *htmlRenderer) wrap(tag string, children []Inline) {
+	r.buf.WriteString("<" + tag + ">")
+	r.inlines(children)
+	r.buf.WriteString("</" + tag + ">")
+}
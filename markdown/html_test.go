@@ -0,0 +1,27 @@
+package markdown
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	src := "## Notes & more\n\nSee [the docs](https://d.example?a=1&b=2 \"Docs\"), `<b>` and **bold**.\n\n> quoted\n\n3. one\n4. two\n   - sub\n\n```go\nx := 1 < 2\n```\n\n<div>raw</div>\n"
+	want := `<h2>Notes &amp; more</h2>
+<p>See <a href="https://d.example?a=1&amp;b=2" title="Docs">the docs</a>, <code>&lt;b&gt;</code> and <strong>bold</strong>.</p>
+<blockquote>
+<p>quoted</p>
+</blockquote>
+<ol start="3">
+<li>one</li>
+<li>two
+<ul>
+<li>sub</li>
+</ul>
+</li>
+</ol>
+<pre><code class="language-go">x := 1 &lt; 2
+</code></pre>
+<div>raw</div>
+`
+	if got := HTML(src); got != want {
+		t.Errorf("HTML() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,290 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseInline parses the inline content of a paragraph or heading.
+func ParseInline(s string) []Inline {
+	var ret []Inline
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			ret = append(ret, Inline{Kind: Text, Text: buf.String()})
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			flush()
+			ret = append(ret, Inline{Kind: HardBreak})
+			i += 2
+
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			buf.WriteByte(s[i+1])
+			i += 2
+
+		case c == '\n':
+			// a line ending in two spaces is a hard break
+			text := buf.String()
+			trimmed := strings.TrimRight(text, " ")
+			kind := SoftBreak
+			if len(text)-len(trimmed) >= 2 {
+				kind = HardBreak
+			}
+			buf.Reset()
+			buf.WriteString(trimmed)
+			flush()
+			ret = append(ret, Inline{Kind: kind})
+			i++
+
+		case c == '`':
+			n := runLength(s, i)
+			end := strings.Index(s[i+n:], s[i:i+n])
+			for end >= 0 && i+n+end+n < len(s) && s[i+n+end+n] == '`' {
+				// a longer run of backticks doesn't close the span
+				next := strings.Index(s[i+n+end+n:], s[i:i+n])
+				if next < 0 {
+					end = -1
+					break
+				}
+				end += n + next
+			}
+			if end < 0 {
+				buf.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			ret = append(ret, Inline{Kind: Code, Text: codeSpan(s[i+n : i+n+end])})
+			i += n + end + n
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			text, url, title, n, ok := parseLink(s[i+1:])
+			if !ok {
+				buf.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			ret = append(ret, Inline{Kind: Image, Text: PlainText(ParseInline(text)), URL: url, Title: title})
+			i += 1 + n
+
+		case c == '[':
+			text, url, title, n, ok := parseLink(s[i:])
+			if !ok {
+				buf.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			ret = append(ret, Inline{Kind: Link, URL: url, Title: title, Children: ParseInline(text)})
+			i += n
+
+		case c == '<':
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 || !isAutolink(s[i+1:i+end]) {
+				buf.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			url := s[i+1 : i+end]
+			ret = append(ret, Inline{Kind: Link, URL: url, Children: []Inline{{Kind: Text, Text: url}}})
+			i += end + 1
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s, i)
+			in, consumed := parseEmphasis(s, i, n)
+			if consumed == 0 {
+				buf.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			ret = append(ret, in)
+			i += consumed
+
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return ret
+}
+
+// parseEmphasis parses emphasis opened by a run of n delimiters at i. It
+// returns the number of bytes consumed, zero if the run isn't an opener.
+func parseEmphasis(s string, i, n int) (Inline, int) {
+	c := s[i]
+	if i+n >= len(s) || isSpace(s[i+n]) {
+		return Inline{}, 0
+	}
+	// intraword underscores are part of words like snake_case
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return Inline{}, 0
+	}
+
+	kind, size := Emphasis, 1
+	switch {
+	case c == '~' && n >= 2:
+		kind, size = Strikethrough, 2
+	case c == '~':
+		return Inline{}, 0
+	case n >= 2:
+		kind, size = Strong, 2
+	}
+
+	end := findClosing(s, i+size, c, size)
+	if end <= i+size {
+		return Inline{}, 0
+	}
+	return Inline{Kind: kind, Children: ParseInline(s[i+size : end])}, end + size - i
+}
+
+// findClosing finds the closing delimiter of size n for an opener ending at
+// start. When the closer is part of a longer run, the last n delimiters of
+// the run close the span, so ***a*** is strong emphasis around emphasis.
+func findClosing(s string, start int, c byte, n int) int {
+	for j := start; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			// don't close inside a code span
+			k := runLength(s, j)
+			if end := strings.Index(s[j+k:], s[j:j+k]); end >= 0 {
+				j += k + end + k - 1
+			}
+			continue
+		case c:
+		default:
+			continue
+		}
+
+		k := runLength(s, j)
+		if j > start && !isSpace(s[j-1]) && (k == n || k >= 3) {
+			end := j + k - n
+			if c == '_' && end+n < len(s) && isWordByte(s[end+n]) {
+				j += k - 1
+				continue
+			}
+			return end
+		}
+		j += k - 1
+	}
+	return -1
+}
+
+// parseLink parses [text](url "title") at the start of s.
+func parseLink(s string) (text, url, title string, n int, ok bool) {
+	depth := 0
+	closeBracket := -1
+	for i := 0; i < len(s) && closeBracket < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeBracket = i
+			}
+		}
+	}
+	if closeBracket < 0 || closeBracket+1 >= len(s) || s[closeBracket+1] != '(' {
+		return "", "", "", 0, false
+	}
+
+	i := closeBracket + 2
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			return "", "", "", 0, false
+		}
+		url = s[i+1 : i+end]
+		i += end + 1
+	} else {
+		start, parens := i, 0
+		for ; i < len(s); i++ {
+			if s[i] == '(' {
+				parens++
+			} else if s[i] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			} else if s[i] == ' ' || s[i] == '\n' {
+				break
+			}
+		}
+		url = s[start:i]
+	}
+
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		end := strings.IndexByte(s[i+1:], s[i])
+		if end < 0 {
+			return "", "", "", 0, false
+		}
+		title = s[i+1 : i+1+end]
+		i += end + 2
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", "", "", 0, false
+	}
+	return s[1:closeBracket], url, title, i + 1, true
+}
+
+func isAutolink(s string) bool {
+	if strings.ContainsAny(s, " \n<") {
+		return false
+	}
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "mailto:")
+}
+
+// codeSpan normalizes the content of a code span: line endings become
+// spaces, and one space is stripped from both sides when both are spaces.
+func codeSpan(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len(s) >= 2 && s[0] == ' ' && s[len(s)-1] == ' ' && strings.Trim(s, " ") != "" {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+func isPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
@@ -0,0 +1,275 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	atxHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceOpen     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	thematicBreak = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+	listMarker    = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	quoteMarker   = regexp.MustCompile(`^ {0,3}> ?`)
+	setextLine    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	htmlOpen      = regexp.MustCompile(`^ {0,3}<(/?[a-zA-Z][a-zA-Z0-9-]*[\s/>]|/?[a-zA-Z][a-zA-Z0-9-]*$|!--)`)
+)
+
+// Parse parses a Markdown document.
+func Parse(src string) *Document {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return &Document{Blocks: parseBlocks(lines)}
+}
+
+func parseBlocks(lines []string) []*Block {
+	var blocks []*Block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+
+		var b *Block
+		var n int
+		switch {
+		case fenceOpen.MatchString(line):
+			b, n = parseFence(lines[i:])
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			b, n = &Block{Kind: Heading, Level: len(m[1]), Inlines: ParseInline(strings.TrimSpace(m[2]))}, 1
+		case thematicBreak.MatchString(line):
+			b, n = &Block{Kind: ThematicBreak}, 1
+		case quoteMarker.MatchString(line):
+			b, n = parseQuote(lines[i:])
+		case listMarker.MatchString(line):
+			b, n = parseList(lines[i:])
+		case indentOf(line) >= 4:
+			b, n = parseIndentedCode(lines[i:])
+		case htmlOpen.MatchString(line):
+			b, n = parseHTML(lines[i:])
+		default:
+			b, n = parseParagraph(lines[i:])
+		}
+		blocks = append(blocks, b)
+		i += n
+	}
+	return blocks
+}
+
+func parseFence(lines []string) (*Block, int) {
+	m := fenceOpen.FindStringSubmatch(lines[0])
+	indent, marker := len(m[1]), m[2]
+	closing := regexp.MustCompile(`^ {0,3}` + regexp.QuoteMeta(marker) + string(marker[0]) + `*[ \t]*$`)
+
+	var code []string
+	i := 1
+	for ; i < len(lines); i++ {
+		if closing.MatchString(lines[i]) {
+			i++
+			break
+		}
+		code = append(code, trimIndent(lines[i], indent))
+	}
+	return &Block{Kind: CodeBlock, Lang: m[3], Text: strings.Join(code, "\n")}, i
+}
+
+func parseIndentedCode(lines []string) (*Block, int) {
+	var code []string
+	i := 0
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) {
+			code = append(code, "")
+			continue
+		}
+		if indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, lines[i][4:])
+	}
+	// blank lines after the code belong to the document
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+		i--
+	}
+	return &Block{Kind: CodeBlock, Text: strings.Join(code, "\n")}, i
+}
+
+func parseQuote(lines []string) (*Block, int) {
+	var inner []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if loc := quoteMarker.FindStringIndex(line); loc != nil {
+			inner = append(inner, line[loc[1]:])
+			continue
+		}
+		// a lazy continuation line of a paragraph in the quote
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || interruptsParagraph(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+	return &Block{Kind: Quote, Children: parseBlocks(inner)}, i
+}
+
+func parseList(lines []string) (*Block, int) {
+	first := listMarker.FindStringSubmatch(lines[0])
+	list := &Block{Kind: List, Start: 1}
+	if n, err := strconv.Atoi(first[2][:len(first[2])-1]); err == nil {
+		list.Ordered = true
+		list.Start = n
+	}
+	delim := first[2][len(first[2])-1]
+
+	i := 0
+	for i < len(lines) {
+		m := listMarker.FindStringSubmatch(lines[i])
+		if m == nil || m[2][len(m[2])-1] != delim {
+			break
+		}
+		contentIndent := len(m[0])
+		if m[3] == "" || len(m[3]) > 4 {
+			contentIndent = len(m[1]) + len(m[2]) + 1
+		}
+		item := []string{lines[i][min(contentIndent, len(lines[i])):]}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if isBlank(line) {
+				j := i
+				for j < len(lines) && isBlank(lines[j]) {
+					j++
+				}
+				if j == len(lines) || indentOf(lines[j]) < contentIndent {
+					break
+				}
+				for ; i < j; i++ {
+					item = append(item, "")
+				}
+				list.Loose = true
+				continue
+			}
+			if indentOf(line) >= contentIndent {
+				item = append(item, line[contentIndent:])
+				i++
+				continue
+			}
+			if isBlank(item[len(item)-1]) || listMarker.MatchString(line) || interruptsParagraph(line) {
+				break
+			}
+			item = append(item, strings.TrimLeft(line, " "))
+			i++
+		}
+		list.Children = append(list.Children, &Block{Kind: ListItem, Children: parseBlocks(item)})
+
+		// blank lines between items make the list loose
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		if j > i && j < len(lines) {
+			if next := listMarker.FindStringSubmatch(lines[j]); next != nil && next[2][len(next[2])-1] == delim {
+				list.Loose = true
+				i = j
+			}
+		}
+	}
+	return list, i
+}
+
+func parseHTML(lines []string) (*Block, int) {
+	i := 0
+	for i < len(lines) && !isBlank(lines[i]) {
+		i++
+	}
+	return &Block{Kind: HTMLBlock, Text: strings.Join(lines[:i], "\n")}, i
+}
+
+func parseParagraph(lines []string) (*Block, int) {
+	var text []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		if i > 0 {
+			if m := setextLine.FindStringSubmatch(line); m != nil {
+				level := 2
+				if m[1][0] == '=' {
+					level = 1
+				}
+				return &Block{Kind: Heading, Level: level, Inlines: ParseInline(strings.TrimSpace(strings.Join(text, "\n")))}, i + 1
+			}
+			if interruptsParagraph(line) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+	return &Block{Kind: Paragraph, Inlines: ParseInline(strings.TrimRight(strings.Join(text, "\n"), " "))}, i
+}
+
+// interruptsParagraph reports whether line starts a block that ends a
+// paragraph without a blank line in between.
+func interruptsParagraph(line string) bool {
+	if fenceOpen.MatchString(line) || atxHeading.MatchString(line) ||
+		thematicBreak.MatchString(line) || quoteMarker.MatchString(line) {
+		return true
+	}
+	m := listMarker.FindStringSubmatch(line)
+	return m != nil && m[3] != "" && (m[2] == "1." || m[2] == "1)" || !isDigit(m[2][0]))
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent removes up to n leading spaces.
+func trimIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+// expandTabs replaces tabs in the indentation of a line with spaces, to the
+// next multiple of four.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var buf strings.Builder
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\t':
+			n := 4 - col%4
+			buf.WriteString(strings.Repeat(" ", n))
+			col += n
+		case ' ':
+			buf.WriteByte(' ')
+			col++
+		default:
+			buf.WriteString(line[i:])
+			return buf.String()
+		}
+	}
+	return buf.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
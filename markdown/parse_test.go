@@ -0,0 +1,108 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func kinds(blocks []*Block) []BlockKind {
+	ret := make([]BlockKind, len(blocks))
+	for i, b := range blocks {
+		ret[i] = b.Kind
+	}
+	return ret
+}
+
+func TestParseBlocks(t *testing.T) {
+	doc := Parse("# Title #\n\npara one\nstill one\n\n> quote\nlazy\n\n- a\n- b\n\n```go\ncode\n\n  more\n```\n\n---\n\n    indented\n\nSetext\n===\n\n<div>\nhtml\n</div>\n")
+	want := []BlockKind{Heading, Paragraph, Quote, List, CodeBlock, ThematicBreak, CodeBlock, Heading, HTMLBlock}
+	if got := kinds(doc.Blocks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("kinds = %v, want %v", got, want)
+	}
+
+	if h := doc.Blocks[0]; h.Level != 1 || PlainText(h.Inlines) != "Title" {
+		t.Errorf("heading = %d %q", h.Level, PlainText(h.Inlines))
+	}
+	if p := doc.Blocks[1]; PlainText(p.Inlines) != "para one still one" {
+		t.Errorf("paragraph = %q", PlainText(p.Inlines))
+	}
+	if q := doc.Blocks[2]; len(q.Children) != 1 || PlainText(q.Children[0].Inlines) != "quote lazy" {
+		t.Errorf("quote = %+v", q.Children)
+	}
+	if l := doc.Blocks[3]; len(l.Children) != 2 || l.Loose || l.Ordered {
+		t.Errorf("list = %+v", l)
+	}
+	if c := doc.Blocks[4]; c.Lang != "go" || c.Text != "code\n\n  more" {
+		t.Errorf("fenced code = %q %q", c.Lang, c.Text)
+	}
+	if c := doc.Blocks[6]; c.Text != "indented" {
+		t.Errorf("indented code = %q", c.Text)
+	}
+	if h := doc.Blocks[7]; h.Level != 1 || PlainText(h.Inlines) != "Setext" {
+		t.Errorf("setext heading = %d %q", h.Level, PlainText(h.Inlines))
+	}
+}
+
+func TestParseNestedList(t *testing.T) {
+	doc := Parse("3. first\n4. second\n   - nested\n     continued\n\n   para in second\n")
+	list := doc.Blocks[0]
+	if !list.Ordered || list.Start != 3 || len(list.Children) != 2 || !list.Loose {
+		t.Fatalf("list = %+v", list)
+	}
+	second := list.Children[1].Children
+	if got := kinds(second); !reflect.DeepEqual(got, []BlockKind{Paragraph, List, Paragraph}) {
+		t.Fatalf("second item = %v", got)
+	}
+	if got := PlainText(second[1].Children[0].Children[0].Inlines); got != "nested continued" {
+		t.Errorf("nested item = %q", got)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []Inline
+	}{
+		{"a **b** *c* ~~d~~", []Inline{
+			{Kind: Text, Text: "a "},
+			{Kind: Strong, Children: []Inline{{Kind: Text, Text: "b"}}},
+			{Kind: Text, Text: " "},
+			{Kind: Emphasis, Children: []Inline{{Kind: Text, Text: "c"}}},
+			{Kind: Text, Text: " "},
+			{Kind: Strikethrough, Children: []Inline{{Kind: Text, Text: "d"}}},
+		}},
+		{"***x***", []Inline{
+			{Kind: Strong, Children: []Inline{{Kind: Emphasis, Children: []Inline{{Kind: Text, Text: "x"}}}}},
+		}},
+		{"snake_case_name and 2 * 3 * 4", []Inline{{Kind: Text, Text: "snake_case_name and 2 * 3 * 4"}}},
+		{"`a *b*` and `` c`d ``", []Inline{
+			{Kind: Code, Text: "a *b*"},
+			{Kind: Text, Text: " and "},
+			{Kind: Code, Text: "c`d"},
+		}},
+		{`[a *b*](https://x.y/(z) "t") ![img](p.png)`, []Inline{
+			{Kind: Link, URL: "https://x.y/(z)", Title: "t", Children: []Inline{
+				{Kind: Text, Text: "a "},
+				{Kind: Emphasis, Children: []Inline{{Kind: Text, Text: "b"}}},
+			}},
+			{Kind: Text, Text: " "},
+			{Kind: Image, Text: "img", URL: "p.png"},
+		}},
+		{"<https://a.b> [no link] \\*x\\*", []Inline{
+			{Kind: Link, URL: "https://a.b", Children: []Inline{{Kind: Text, Text: "https://a.b"}}},
+			{Kind: Text, Text: " [no link] *x*"},
+		}},
+		{"a  \nb\nc", []Inline{
+			{Kind: Text, Text: "a"},
+			{Kind: HardBreak},
+			{Kind: Text, Text: "b"},
+			{Kind: SoftBreak},
+			{Kind: Text, Text: "c"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := ParseInline(tt.src); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseInline(%q) =\n%+v\nwant\n%+v", tt.src, got, tt.want)
+		}
+	}
+}
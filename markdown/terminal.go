@@ -0,0 +1,360 @@
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// TerminalOptions controls RenderTerminal.
+type TerminalOptions struct {
+	// Width wraps paragraphs to this many columns, 0 disables wrapping.
+	Width int
+	// Color styles the output with ANSI escape sequences. Without it,
+	// headings keep their # markers and code spans their backticks.
+	Color bool
+}
+
+// SGR parameters of the styles.
+const (
+	styleBold      = "1"
+	styleDim       = "2"
+	styleItalic    = "3"
+	styleUnderline = "4"
+	styleStrike    = "9"
+	styleHeading   = "1;35"
+	styleCode      = "36"
+)
+
+var bullets = []string{"•", "◦", "▪"}
+
+// RenderTerminal renders doc for a terminal. Links are numbered and their
+// URLs listed as footnotes at the end.
+func RenderTerminal(w io.Writer, doc *Document, opts TerminalOptions) error {
+	r := &termRenderer{opts: opts, footnotes: map[string]int{}}
+	r.blocks(doc.Blocks, "", "", false, 0)
+	if len(r.urls) > 0 {
+		r.buf.WriteString("\n")
+		for i, url := range r.urls {
+			r.line("", r.style(styleDim, fmt.Sprintf("[%d]: %s", i+1, url)))
+		}
+	}
+	_, err := io.WriteString(w, r.buf.String())
+	return err
+}
+
+type termRenderer struct {
+	opts      TerminalOptions
+	buf       strings.Builder
+	urls      []string
+	footnotes map[string]int
+}
+
+// blocks renders blocks, the first line prefixed with first and the others
+// with rest. Blocks of tight list items aren't separated by blank lines.
+func (r *termRenderer) blocks(blocks []*Block, first, rest string, tight bool, depth int) {
+	for i, b := range blocks {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		} else if !tight {
+			r.line(rest, "")
+		}
+		r.block(b, prefix, rest, depth)
+	}
+}
+
+func (r *termRenderer) block(b *Block, first, rest string, depth int) {
+	switch b.Kind {
+	case Paragraph:
+		r.wrapped(r.pieces(b.Inlines, ""), first, rest)
+
+	case Heading:
+		style := styleHeading
+		if b.Level > 1 {
+			style = styleBold
+		}
+		if b.Level == 1 {
+			style += ";" + styleUnderline
+		}
+		pieces := r.pieces(b.Inlines, style)
+		if !r.opts.Color {
+			pieces = append([]piece{{text: strings.Repeat("#", b.Level)}, {text: " ", space: true}}, pieces...)
+		}
+		r.wrapped(pieces, first, rest)
+
+	case CodeBlock:
+		for i, line := range strings.Split(b.Text, "\n") {
+			prefix := rest
+			if i == 0 {
+				prefix = first
+			}
+			r.line(prefix+"    ", r.style(styleCode, line))
+		}
+
+	case HTMLBlock:
+		for i, line := range strings.Split(b.Text, "\n") {
+			prefix := rest
+			if i == 0 {
+				prefix = first
+			}
+			r.line(prefix, line)
+		}
+
+	case Quote:
+		bar := "│ "
+		if r.opts.Color {
+			bar = r.style(styleDim, "│") + " "
+		}
+		r.blocks(b.Children, first+bar, rest+bar, false, depth)
+
+	case List:
+		markers := make([]string, len(b.Children))
+		width := 0
+		for i := range b.Children {
+			markers[i] = bullets[depth%len(bullets)]
+			if b.Ordered {
+				markers[i] = fmt.Sprintf("%d.", b.Start+i)
+			}
+			width = max(width, Width(markers[i]))
+		}
+		for i, item := range b.Children {
+			prefix := rest
+			if i == 0 {
+				prefix = first
+			} else if b.Loose {
+				r.line(rest, "")
+			}
+			marker := markers[i] + strings.Repeat(" ", width-Width(markers[i])+1)
+			indent := strings.Repeat(" ", Width(marker))
+			if len(item.Children) == 0 {
+				r.line(prefix, marker)
+				continue
+			}
+			r.blocks(item.Children, prefix+marker, rest+indent, !b.Loose, depth+1)
+		}
+
+	case ThematicBreak:
+		n := 40
+		if r.opts.Width > 0 {
+			n = min(n, r.opts.Width-Width(rest))
+		}
+		r.line(first, r.style(styleDim, strings.Repeat("─", max(n, 3))))
+	}
+}
+
+// line writes a line, dropping the trailing spaces of the prefix when the
+// line is empty.
+func (r *termRenderer) line(prefix, text string) {
+	if text == "" {
+		prefix = strings.TrimRight(prefix, " ")
+	}
+	r.buf.WriteString(prefix)
+	r.buf.WriteString(text)
+	r.buf.WriteString("\n")
+}
+
+func (r *termRenderer) style(style, text string) string {
+	if !r.opts.Color || style == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + style + "m" + text + "\x1b[0m"
+}
+
+// piece is a run of text in one style. Lines break at spaces, and between
+// wide characters, which are pieces of their own.
+type piece struct {
+	text  string
+	style string
+	space bool
+	wide  bool
+	brk   bool
+}
+
+func (r *termRenderer) pieces(inlines []Inline, style string) []piece {
+	var ret []piece
+	for _, in := range inlines {
+		switch in.Kind {
+		case Text:
+			ret = append(ret, splitText(in.Text, style)...)
+		case SoftBreak:
+			ret = append(ret, piece{text: " ", space: true})
+		case HardBreak:
+			ret = append(ret, piece{brk: true})
+		case Code:
+			text := in.Text
+			if !r.opts.Color {
+				text = "`" + text + "`"
+			}
+			ret = append(ret, splitText(text, join(style, styleCode))...)
+		case Emphasis:
+			ret = append(ret, r.pieces(in.Children, join(style, styleItalic))...)
+		case Strong:
+			ret = append(ret, r.pieces(in.Children, join(style, styleBold))...)
+		case Strikethrough:
+			ret = append(ret, r.pieces(in.Children, join(style, styleStrike))...)
+		case Link:
+			ret = append(ret, r.pieces(in.Children, join(style, styleUnderline))...)
+			if PlainText(in.Children) != in.URL {
+				ret = append(ret, piece{text: r.footnote(in.URL), style: styleDim})
+			}
+		case Image:
+			alt := "image"
+			if in.Text != "" {
+				alt = "image: " + in.Text
+			}
+			ret = append(ret, splitText("["+alt+"]", join(style, styleDim))...)
+			ret = append(ret, piece{text: r.footnote(in.URL), style: styleDim})
+		}
+	}
+	return ret
+}
+
+// footnote returns the reference to url, like [1].
+func (r *termRenderer) footnote(url string) string {
+	n, ok := r.footnotes[url]
+	if !ok {
+		r.urls = append(r.urls, url)
+		n = len(r.urls)
+		r.footnotes[url] = n
+	}
+	return fmt.Sprintf("[%d]", n)
+}
+
+func splitText(text, style string) []piece {
+	var ret []piece
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			ret = append(ret, piece{text: word.String(), style: style})
+			word.Reset()
+		}
+	}
+	for _, c := range text {
+		switch {
+		case unicode.IsSpace(c):
+			flush()
+			ret = append(ret, piece{text: " ", style: style, space: true})
+		case isWide(c):
+			flush()
+			ret = append(ret, piece{text: string(c), style: style, wide: true})
+		default:
+			word.WriteRune(c)
+		}
+	}
+	flush()
+	return ret
+}
+
+// wrapped writes pieces wrapped to the width left after the prefix.
+func (r *termRenderer) wrapped(pieces []piece, first, rest string) {
+	width := 0
+	if r.opts.Width > 0 {
+		width = max(r.opts.Width-Width(rest), 10)
+	}
+	for i, line := range wrap(pieces, width) {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		r.line(prefix, r.join(line))
+	}
+}
+
+// join writes the pieces of a line, merging pieces of the same style into
+// one escape sequence.
+func (r *termRenderer) join(line []piece) string {
+	var buf strings.Builder
+	for i := 0; i < len(line); {
+		j := i
+		var text strings.Builder
+		for ; j < len(line) && line[j].style == line[i].style; j++ {
+			text.WriteString(line[j].text)
+		}
+		buf.WriteString(r.style(line[i].style, text.String()))
+		i = j
+	}
+	return buf.String()
+}
+
+// wrap breaks pieces into lines of at most width columns. Words longer than
+// the width get a line of their own.
+func wrap(pieces []piece, width int) [][]piece {
+	type word struct {
+		pieces []piece
+		width  int
+		space  bool
+		wide   bool
+		brk    bool
+	}
+	var words []word
+	for _, p := range pieces {
+		last := len(words) - 1
+		switch {
+		case p.brk:
+			words = append(words, word{brk: true})
+		case p.space:
+			if last >= 0 && words[last].space {
+				continue
+			}
+			words = append(words, word{pieces: []piece{p}, width: 1, space: true})
+		case last >= 0 && !words[last].space && !words[last].brk && !words[last].wide && !p.wide:
+			words[last].pieces = append(words[last].pieces, p)
+			words[last].width += Width(p.text)
+		default:
+			words = append(words, word{pieces: []piece{p}, width: Width(p.text), wide: p.wide})
+		}
+	}
+
+	var lines [][]piece
+	var line []piece
+	lineWidth := 0
+	closeLine := func() {
+		for len(line) > 0 && line[len(line)-1].space {
+			line = line[:len(line)-1]
+		}
+		lines = append(lines, line)
+		line, lineWidth = nil, 0
+	}
+	for _, w := range words {
+		switch {
+		case w.brk:
+			closeLine()
+			continue
+		case w.space && lineWidth == 0:
+			continue
+		case !w.space && width > 0 && lineWidth > 0 && lineWidth+w.width > width:
+			closeLine()
+		}
+		line = append(line, w.pieces...)
+		lineWidth += w.width
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		closeLine()
+	}
+	return lines
+}
+
+func join(style, add string) string {
+	if style == "" {
+		return add
+	}
+	return style + ";" + add
+}
+
+// WrapText wraps plain text to width columns, keeping its line breaks. Wide
+// characters count as two columns and may break anywhere.
+func WrapText(text string, width int) []string {
+	var ret []string
+	for _, src := range strings.Split(text, "\n") {
+		for _, line := range wrap(splitText(src, ""), width) {
+			var buf strings.Builder
+			for _, p := range line {
+				buf.WriteString(p.text)
+			}
+			ret = append(ret, buf.String())
+		}
+	}
+	return ret
+}
@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func renderTerminal(t *testing.T, src string, opts TerminalOptions) string {
+	t.Helper()
+	var buf strings.Builder
+	if err := RenderTerminal(&buf, Parse(src), opts); err != nil {
+		t.Fatalf("RenderTerminal() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRenderTerminal(t *testing.T) {
+	src := "## Notes\n\nSee the [docs](https://d.example) and the [docs](https://d.example) again, `run` it.\n\n> quoted\n\n1. one\n2. two\n   - sub\n\n```\nx := 1\n```\n"
+	got := renderTerminal(t, src, TerminalOptions{Width: 30})
+	want := `## Notes
+
+See the docs[1] and the
+docs[1] again, ` + "`run`" + ` it.
+
+│ quoted
+
+1. one
+2. two
+   ◦ sub
+
+    x := 1
+
+[1]: https://d.example
+`
+	if got != want {
+		t.Errorf("RenderTerminal() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTerminalWrapsWideText(t *testing.T) {
+	got := renderTerminal(t, "中文段落没有空格也要换行", TerminalOptions{Width: 10})
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if Width(line) > 10 {
+			t.Errorf("line %q is %d columns wide, want at most 10", line, Width(line))
+		}
+	}
+	if strings.ReplaceAll(got, "\n", "") != "中文段落没有空格也要换行" {
+		t.Errorf("text changed: %q", got)
+	}
+}
+
+func TestRenderTerminalColor(t *testing.T) {
+	got := renderTerminal(t, "**bold** *it* `c`", TerminalOptions{Color: true})
+	want := "\x1b[1mbold\x1b[0m \x1b[3mit\x1b[0m \x1b[36mc\x1b[0m\n"
+	if got != want {
+		t.Errorf("RenderTerminal() = %q, want %q", got, want)
+	}
+	if Width(got) != len("bold it c\n") {
+		t.Errorf("Width() = %d, escape sequences must not count", Width(got))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := WrapText("one two three four\n\n中文没有空格", 9)
+	want := []string{"one two", "three", "four", "", "中文没有", "空格"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("WrapText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// wideRanges are the code points that take two columns in a terminal: CJK,
+// Hangul, full-width forms and most emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x3FFFD},
+}
+
+// RuneWidth returns the number of terminal columns of r.
+func RuneWidth(r rune) int {
+	if r < 0x1100 {
+		return 1
+	}
+	for _, rg := range wideRanges {
+		if r >= rg[0] && r <= rg[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// Width returns the number of terminal columns of s, ignoring ANSI escape
+// sequences.
+func Width(s string) int {
+	w := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			if end := strings.IndexByte(s[i:], 'm'); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w += RuneWidth(r)
+		i += size
+	}
+	return w
+}
+
+// isWide reports whether r is a wide character, after which lines may break
+// without a space.
+func isWide(r rune) bool {
+	return RuneWidth(r) == 2
+}
@@ -17,6 +17,13 @@ func AddTools(ctx context.Context, s *mcps.MCPServer, cl *client.Client) error {
 	}
 	s.AddTool(listsTool, listsToolHandler)
 
+	listTool, listToolHandler, err := tools.GetListTool(cl)
+	if err != nil {
+		slog.Error("failed to get list tool", "error", err)
+		return err
+	}
+	s.AddTool(listTool, listToolHandler)
+
 	publishPostTool, publishPostToolHandler, err := tools.GetPublishPostTool(cl)
 	if err != nil {
 		slog.Error("failed to get publish post tool", "error", err)
@@ -45,6 +52,13 @@ func AddTools(ctx context.Context, s *mcps.MCPServer, cl *client.Client) error {
 	}
 	s.AddTool(searchTool, searchToolHandler)
 
+	searchLocalTool, searchLocalToolHandler, err := tools.GetSearchLocalTool()
+	if err != nil {
+		slog.Error("failed to get local search tool", "error", err)
+		return err
+	}
+	s.AddTool(searchLocalTool, searchLocalToolHandler)
+
 	getListPostsTool, getListPostsToolHandler, err := tools.GetListPostsTool(cl)
 	if err != nil {
 		slog.Error("failed to get list posts tool", "error", err)
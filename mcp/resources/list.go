@@ -8,14 +8,15 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	mcps "github.com/mark3labs/mcp-go/server"
 	"github.com/quailyquaily/quail-cli/client"
-	"github.com/spf13/viper"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/quailyurl"
 )
 
 func handleListsResource(cl *client.Client) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		userID := viper.GetInt64("app.user.id")
+		userID := config.Current().App.User.ID
 
-		lists, err := cl.GetUserLists(uint64(userID))
+		lists, err := cl.GetUserLists(userID)
 		if err != nil {
 			slog.Error("failed to get user lists", "error", err)
 			return nil, err
@@ -24,7 +25,7 @@ func handleListsResource(cl *client.Client) func(ctx context.Context, request mc
 		res := make([]mcp.ResourceContents, 0)
 		for _, list := range lists {
 			res = append(res, mcp.TextResourceContents{
-				URI:      fmt.Sprintf("https://quaily.com/%s", list.Slug),
+				URI:      quailyurl.ListURL(list.Slug),
 				MIMEType: "text/html",
 				Text:     fmt.Sprintf("Title: %s, Description: %s, Tagline: %s", list.Title, list.Description, list.Tagline),
 			})
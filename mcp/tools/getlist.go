@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcps "github.com/mark3labs/mcp-go/server"
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+func handleListTool(cl *client.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		channelSlug, ok := request.Params.Arguments["channel_slug"].(string)
+		if !ok {
+			channelSlug = ""
+		}
+
+		var msg string
+
+		if channelSlug == "" {
+			msg = "no channel slug provided"
+		} else {
+			resp, err := cl.GetListSettings(channelSlug)
+			if err != nil {
+				msg = fmt.Sprintf("failed to get channel. error=%v", err)
+			} else {
+				buf, err := json.Marshal(resp.Data)
+				if err != nil {
+					msg = fmt.Sprintf("failed to marshal channel. error=%v", err)
+				} else {
+					msg = string(buf)
+				}
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: msg,
+				},
+			},
+		}, nil
+	}
+}
+
+func GetListTool(cl *client.Client) (mcp.Tool, mcps.ToolHandlerFunc, error) {
+	tool := mcp.NewTool("quaily_get_channel",
+		mcp.WithDescription(`Return all settings of a quaily channel, like its slug, title, description and tagline, in JSON format.
+		The channel is specified by its slug or ID.
+		`),
+		mcp.WithString("channel_slug",
+			mcp.Required(),
+			mcp.Description("The slug or ID of the channel"),
+		),
+	)
+	return tool, handleListTool(cl), nil
+}
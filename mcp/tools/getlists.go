@@ -9,14 +9,14 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	mcps "github.com/mark3labs/mcp-go/server"
 	"github.com/quailyquaily/quail-cli/client"
-	"github.com/spf13/viper"
+	"github.com/quailyquaily/quail-cli/config"
 )
 
 func handleListsTool(cl *client.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		userID := viper.GetInt64("app.user.id")
+		userID := config.Current().App.User.ID
 
-		lists, err := cl.GetUserLists(uint64(userID))
+		lists, err := cl.GetUserLists(userID)
 		if err != nil {
 			slog.Error("failed to get user lists", "error", err)
 			return nil, err
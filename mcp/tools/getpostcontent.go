@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	mcps "github.com/mark3labs/mcp-go/server"
 	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/quailyurl"
 )
 
 func handlePostContentTool(cl *client.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -28,11 +28,9 @@ func handlePostContentTool(cl *client.Client) func(ctx context.Context, request
 			if !ok {
 				url = ""
 			}
-			re := regexp.MustCompile(`https://quaily\.com/([^/]+)/([^/]+)`)
-			matches := re.FindStringSubmatch(url)
-			if len(matches) == 3 {
-				channelSlug = matches[1]
-				postSlug = matches[2]
+			if ref, err := quailyurl.Default().ParsePost(url); err == nil {
+				channelSlug = ref.List
+				postSlug = ref.Post
 			}
 		}
 
@@ -69,7 +67,7 @@ func GetPostContentTool(cl *client.Client) (mcp.Tool, mcps.ToolHandlerFunc, erro
 	tool := mcp.NewTool("quaily_get_post_content",
 		mcp.WithDescription(`Return the post of a given quaily channel and post slug. The tool will return both content and paid content if the user is logged in and paid for the post.
 		The channel is specified by the channel slug.
-		If there is an URL, the tool will accept the URL as well. The URL should be in the format of "https://quaily.com/{channel_slug}/{post_slug}/content".
+		If there is an URL, the tool will accept the URL as well. The URL should be like "https://quaily.com/{channel_slug}/p/{post_slug}", or on the custom domain of the channel.
 		The tool returns the post data in JSON format. The content of the post is included in the field "content" and "paid_content" if the post is paid.
 		`),
 		mcp.WithString("channel_slug",
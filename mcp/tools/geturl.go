@@ -8,6 +8,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	mcps "github.com/mark3labs/mcp-go/server"
 	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/quailyurl"
 )
 
 func handleURLTool(cl *client.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -51,9 +52,9 @@ func handleURLTool(cl *client.Client) func(ctx context.Context, request mcp.Call
 				}
 				postSlug = resp.Data.Slug
 			}
-			url = fmt.Sprintf("https://quaily.com/%s/p/%s", channelSlug, postSlug)
+			url = quailyurl.PostURL(channelSlug, postSlug)
 		} else {
-			url = fmt.Sprintf("https://quaily.com/%s", channelSlug)
+			url = quailyurl.ListURL(channelSlug)
 		}
 
 		result := make(map[string]string)
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcps "github.com/mark3labs/mcp-go/server"
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/search"
+)
+
+func handleSearchLocalTool() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		query, ok := args["q"].(string)
+		if !ok || query == "" {
+			return mcp.NewToolResultError("q is required"), nil
+		}
+
+		opts := search.Options{Limit: 10}
+		opts.List, _ = args["list"].(string)
+		opts.Tag, _ = args["tag"].(string)
+		if limit, ok := args["limit"].(float64); ok && limit > 0 {
+			opts.Limit = int(limit)
+		}
+		var err error
+		since, _ := args["since"].(string)
+		if opts.Since, err = config.ParseDate(since, false); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		until, _ := args["until"].(string)
+		if opts.Until, err = config.ParseDate(until, true); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		idx, err := search.Update(search.DefaultPath(), false, search.DefaultSources(config.Current())...)
+		if err != nil {
+			slog.Error("failed to update the search index", "error", err)
+			return nil, err
+		}
+		if len(idx.Docs) == 0 {
+			return mcp.NewToolResultError(search.ErrEmpty.Error()), nil
+		}
+
+		buf, err := json.Marshal(idx.Search(query, opts))
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(string(buf)), nil
+	}
+}
+
+func GetSearchLocalTool() (mcp.Tool, mcps.ToolHandlerFunc, error) {
+	tool := mcp.NewTool("quaily_search_local",
+		mcp.WithDescription("Search posts offline: the subscribed posts cached by `quail-cli reader sync` and the Markdown posts of the current project. Results are ranked by relevance, with a snippet where matches are marked with **."),
+		mcp.WithString("q",
+			mcp.Description("Words to search for"),
+			mcp.Required(),
+		),
+		mcp.WithString("list",
+			mcp.Description("Only posts of this list id or slug"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("Only posts with this tag"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only posts published on or after this date, like 2026-01-31"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only posts published on or before this date, like 2026-12-31"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results"),
+			mcp.DefaultNumber(10),
+		),
+	)
+
+	return tool, handleSearchLocalTool(), nil
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/lyricat/goutils/uuid"
 	"github.com/pkg/browser"
@@ -23,6 +24,7 @@ import (
 const (
 	authPath     = "/oauth/authorize"
 	tokenPath    = "/oauth/token"
+	revokePath   = "/oauth/revoke"
 	redirectPath = "/oauth/code"
 	clientID     = "e9139b6e-298a-43e4-91f0-fc97960e281a"
 	clientSecret = ""
@@ -152,18 +154,72 @@ func RefreshToken(apiBase, refreshToken string) (*oauth2.Token, error) {
 	}
 	defer resp.Body.Close()
 
+	return decodeToken(resp)
+}
+
+func decodeToken(resp *http.Response) (*oauth2.Token, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d, body: %s", resp.StatusCode, string(body))
+	}
 
 	var token oauth2.Token
 	err = json.Unmarshal(body, &token)
 	if err != nil {
 		return nil, err
 	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+	if token.Expiry.IsZero() && token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
 
-	return &token, nil
+	// keep the raw fields, like scope, available through token.Extra
+	raw := make(map[string]any)
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	return token.WithExtra(raw), nil
+}
+
+// RevokeToken revokes an access or refresh token as described in RFC 7009.
+// hint is "access_token" or "refresh_token".
+func RevokeToken(apiBase, token, hint string) error {
+	data := url.Values{}
+	data.Set("token", token)
+	data.Set("token_type_hint", hint)
+	data.Set("client_id", clientID)
+
+	revokeURL := strings.TrimRight(apiBase, "/") + revokePath
+
+	req, err := http.NewRequest("POST", revokeURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Scopes returns the scopes granted with token, if the server reported them.
+func Scopes(token *oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	return strings.Fields(scope)
 }
 
 func generateCodeVerifier() string {
@@ -195,16 +251,5 @@ func exchangeCodeForToken(apiBase, code, verifier, redirectURL string) (*oauth2.
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var token oauth2.Token
-	err = json.Unmarshal(body, &token)
-	if err != nil {
-		return nil, err
-	}
-
-	return &token, nil
+	return decodeToken(resp)
 }
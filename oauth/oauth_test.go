@@ -1,9 +1,12 @@
 package oauth
 
 import (
+	"io"
+	"net/http"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestOAuthRedirectURL(t *testing.T) {
@@ -60,3 +63,27 @@ func TestShouldOpenBrowserWithLinuxBrowserEnv(t *testing.T) {
 		t.Fatal("expected browser auto-open with BROWSER set")
 	}
 }
+
+func TestDecodeTokenComputesExpiry(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"access_token":"a","refresh_token":"r","expires_in":3600}`)),
+	}
+	token, err := decodeToken(resp)
+	if err != nil {
+		t.Fatalf("decodeToken() error = %v", err)
+	}
+	if token.Expiry.Before(time.Now().Add(59 * time.Minute)) {
+		t.Fatalf("Expiry = %v, want about an hour from now", token.Expiry)
+	}
+}
+
+func TestDecodeTokenRejectsErrorResponse(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       io.NopCloser(strings.NewReader(`{"error":"invalid_grant"}`)),
+	}
+	if _, err := decodeToken(resp); err == nil {
+		t.Fatal("decodeToken() error = nil, want error for failed refresh")
+	}
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Column is a field of a result type in table and CSV output.
+type Column struct {
+	// Name is the lower snake case name of the column, like published_at.
+	Name string
+	// Detail columns are only shown when a single value is rendered.
+	Detail bool
+	Value  func(v any) any
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[reflect.Type][]Column{}
+)
+
+// Col creates a column reading a value of type T.
+func Col[T any](name string, value func(T) any) Column {
+	return Column{
+		Name:  name,
+		Value: func(v any) any { return value(v.(T)) },
+	}
+}
+
+// AsDetail returns the column marked as a detail column.
+func (c Column) AsDetail() Column {
+	c.Detail = true
+	return c
+}
+
+// Header is the column name in a table header.
+func (c Column) Header() string {
+	return strings.ToUpper(c.Name)
+}
+
+// Label is the column name in the single value view, like "Published At".
+func (c Column) Label() string {
+	words := strings.Split(c.Name, "_")
+	for i, word := range words {
+		switch word {
+		case "id", "url":
+			words[i] = strings.ToUpper(word)
+		default:
+			if word != "" {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Register sets the default columns of type T. Values of *T use them too.
+func Register[T any](columns ...Column) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[reflect.TypeOf((*T)(nil)).Elem()] = columns
+}
+
+// ColumnsOf returns the columns registered for t. Detail columns are left out
+// unless detail is set.
+func ColumnsOf(t reflect.Type, detail bool) ([]Column, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	registryMu.RLock()
+	columns, ok := registry[t]
+	registryMu.RUnlock()
+	if !ok || detail {
+		return columns, ok
+	}
+
+	ret := make([]Column, 0, len(columns))
+	for _, c := range columns {
+		if !c.Detail {
+			ret = append(ret, c)
+		}
+	}
+	return ret, true
+}
@@ -0,0 +1,367 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter is a parsed --filter expression, like
+//
+//	status==0 && (list==foo || title=~"^Weekly")
+//
+// Names refer to the columns of the result type. Values are compared by the
+// type of the column: numbers, times, bools or strings. =~ matches a regular
+// expression. A name alone is true when the column isn't empty or zero.
+type Filter struct {
+	source string
+	root   node
+}
+
+// ParseFilter parses a filter expression. An empty expression returns nil.
+func ParseFilter(s string) (*Filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return &Filter{source: s, root: root}, nil
+}
+
+func (f *Filter) String() string {
+	return f.source
+}
+
+// Names returns the column names used by the filter.
+func (f *Filter) Names() []string {
+	var names []string
+	f.root.names(&names)
+	return names
+}
+
+// Match reports whether the item passes the filter. get returns the value of
+// a column of the item.
+func (f *Filter) Match(get func(name string) any) (bool, error) {
+	return f.root.eval(get)
+}
+
+type node interface {
+	eval(get func(string) any) (bool, error)
+	names(*[]string)
+}
+
+type (
+	orNode  struct{ left, right node }
+	andNode struct{ left, right node }
+	notNode struct{ expr node }
+	cmpNode struct {
+		name  string
+		op    string
+		value string
+		re    *regexp.Regexp
+	}
+)
+
+func (n orNode) eval(get func(string) any) (bool, error) {
+	if ok, err := n.left.eval(get); ok || err != nil {
+		return ok, err
+	}
+	return n.right.eval(get)
+}
+
+func (n andNode) eval(get func(string) any) (bool, error) {
+	if ok, err := n.left.eval(get); !ok || err != nil {
+		return ok, err
+	}
+	return n.right.eval(get)
+}
+
+func (n notNode) eval(get func(string) any) (bool, error) {
+	ok, err := n.expr.eval(get)
+	return !ok, err
+}
+
+func (n cmpNode) eval(get func(string) any) (bool, error) {
+	v := get(n.name)
+	switch n.op {
+	case "":
+		return !isZero(v), nil
+	case "=~":
+		return n.re.MatchString(FormatValue(v)), nil
+	}
+
+	c, err := compareLiteral(v, n.value)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", n.name, err)
+	}
+	switch n.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+func (n orNode) names(ret *[]string)  { n.left.names(ret); n.right.names(ret) }
+func (n andNode) names(ret *[]string) { n.left.names(ret); n.right.names(ret) }
+func (n notNode) names(ret *[]string) { n.expr.names(ret) }
+func (n cmpNode) names(ret *[]string) { *ret = append(*ret, n.name) }
+
+type token struct {
+	kind string // "word", "string" or the operator itself
+	text string
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "=~", "&&", "||", "<", ">", "!", "(", ")"}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		r := rune(s[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{kind: "string", text: s[i+1 : i+1+end]})
+			i += end + 2
+		case isWordChar(r):
+			start := i
+			for i < len(s) && isWordChar(rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: "word", text: s[start:i]})
+		default:
+			found := false
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, token{kind: op, text: op})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected %q at %d", s[i], i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || r == ':' || r == '+' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].kind
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var right node
+		if right, err = p.parseAnd(); err == nil {
+			left = orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var right node
+		if right, err = p.parseUnary(); err == nil {
+			left = andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		expr, err := p.parseUnary()
+		return notNode{expr}, err
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return expr, nil
+	case "word":
+		return p.parseComparison()
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+}
+
+func (p *parser) parseComparison() (node, error) {
+	n := cmpNode{name: strings.ToLower(p.tokens[p.pos].text)}
+	p.pos++
+	switch op := p.peek(); op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~":
+		p.pos++
+		if kind := p.peek(); kind != "word" && kind != "string" {
+			return nil, fmt.Errorf("missing value after %s %s", n.name, op)
+		}
+		n.op = op
+		n.value = p.tokens[p.pos].text
+		p.pos++
+		if op == "=~" {
+			re, err := regexp.Compile(n.value)
+			if err != nil {
+				return nil, err
+			}
+			n.re = re
+		}
+	}
+	return n, nil
+}
+
+// timeLayouts are the layouts accepted for time values in filters.
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// compareLiteral compares a column value with a literal of the filter,
+// parsing the literal as the type of the value.
+func compareLiteral(v any, literal string) (int, error) {
+	switch t := normalize(v).(type) {
+	case float64:
+		n, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", literal)
+		}
+		return compareOrdered(t, n), nil
+	case bool:
+		b, err := strconv.ParseBool(literal)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not true or false", literal)
+		}
+		return compareOrdered(boolInt(t), boolInt(b)), nil
+	case time.Time:
+		for _, layout := range timeLayouts {
+			if lt, err := time.ParseInLocation(layout, literal, time.Local); err == nil {
+				return t.Compare(lt), nil
+			}
+		}
+		return 0, fmt.Errorf("%q is not a time like 2006-01-02", literal)
+	case string:
+		return strings.Compare(t, literal), nil
+	}
+	return strings.Compare(FormatValue(v), literal), nil
+}
+
+// compareValues orders two values of the same column.
+func compareValues(a, b any) int {
+	switch x := normalize(a).(type) {
+	case float64:
+		if y, ok := normalize(b).(float64); ok {
+			return compareOrdered(x, y)
+		}
+	case bool:
+		if y, ok := normalize(b).(bool); ok {
+			return compareOrdered(boolInt(x), boolInt(y))
+		}
+	case time.Time:
+		if y, ok := normalize(b).(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(FormatValue(a), FormatValue(b))
+}
+
+// normalize turns column values into float64, bool, time.Time or string.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case time.Time, bool, string:
+		return t
+	case *time.Time:
+		if t == nil {
+			return time.Time{}
+		}
+		return *t
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	return FormatValue(v)
+}
+
+func isZero(v any) bool {
+	switch t := normalize(v).(type) {
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	case time.Time:
+		return t.IsZero()
+	case string:
+		return t == ""
+	}
+	return true
+}
+
+func compareOrdered[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
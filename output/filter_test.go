@@ -0,0 +1,97 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	row := map[string]any{
+		"id":           uint64(12),
+		"status":       0,
+		"list":         "foo",
+		"title":        "Weekly #3",
+		"paid":         false,
+		"published_at": time.Date(2024, 9, 30, 18, 42, 0, 0, time.UTC),
+		"paid_expiry":  (*time.Time)(nil),
+	}
+	get := func(name string) any { return row[name] }
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status==0 && list==foo", true},
+		{"status==0 && list==bar", false},
+		{"status!=0 || list=='foo'", true},
+		{"id>10 && id<=12", true},
+		{"id>=13", false},
+		{`title=~"^Weekly"`, true},
+		{"!(title=~weekly)", true},
+		{"paid", false},
+		{"!paid && paid==false", true},
+		{"published_at>2024-09-01 && published_at<2024-10-01", true},
+		{"published_at>=2024-09-30T18:43:00Z", false},
+		{"paid_expiry", false},
+		{"list==foo || status==x", true},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q) error = %v", tt.expr, err)
+		}
+		got, err := f.Match(get)
+		if err != nil {
+			t.Fatalf("Match(%q) error = %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	for _, expr := range []string{"status==", "(status==0", "status==0 &&", "title=~'['", "a b", "'x", "a # b"} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) error = nil", expr)
+		}
+	}
+
+	f, _ := ParseFilter("id==abc")
+	if _, err := f.Match(func(string) any { return 1 }); err == nil {
+		t.Error("Match() comparing a number with a word error = nil")
+	}
+	if f, err := ParseFilter("  "); f != nil || err != nil {
+		t.Errorf("ParseFilter(blank) = %v, %v; want nil, nil", f, err)
+	}
+}
+
+func TestRenderColumnsSortFilter(t *testing.T) {
+	filter, err := ParseFilter("id>0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := []item{{ID: 1, Title: "b"}, {ID: 3, Title: "a"}, {ID: 2, Title: "a"}, {ID: 0, Title: "z"}}
+	var buf bytes.Buffer
+	r := New(Format{Kind: CSV}, &buf)
+	r.Columns = []string{"title", "id", "note"}
+	r.Sort = []string{"title", "-id"}
+	r.Filter = filter
+	if err := r.Render(list); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := "title,id,note\na,3,\na,2,\nb,1,\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+	if len(list) != 4 || list[0].ID != 1 {
+		t.Error("Render() changed the input slice")
+	}
+
+	r.Columns = []string{"nope"}
+	if err := r.Render(list); err == nil || !strings.Contains(err.Error(), "use one of id, title") {
+		t.Errorf("unknown column error = %v", err)
+	}
+}
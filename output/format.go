@@ -0,0 +1,61 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Kinds of output formats, as given to --output.
+const (
+	Table    = "table"
+	JSON     = "json"
+	NDJSON   = "ndjson"
+	YAML     = "yaml"
+	CSV      = "csv"
+	Template = "template"
+)
+
+// Formats lists the accepted --output values.
+var Formats = []string{Table, JSON, NDJSON, YAML, CSV, Template + "=<go-template>"}
+
+type Format struct {
+	Kind     string
+	Template *template.Template
+}
+
+// ParseFormat parses an --output value. Templates are given inline, like
+// template='{{.ID}} {{.Title}}'.
+func ParseFormat(s string) (Format, error) {
+	name, arg, hasArg := strings.Cut(s, "=")
+	switch name {
+	case "":
+		return Format{Kind: Table}, nil
+	case Table, JSON, NDJSON, YAML, CSV:
+		if hasArg {
+			return Format{}, fmt.Errorf("output format %s takes no argument", name)
+		}
+		return Format{Kind: name}, nil
+	case Template:
+		if arg == "" {
+			return Format{}, fmt.Errorf("output format template needs a template, like template='{{.Title}}'")
+		}
+		t, err := template.New("output").Funcs(templateFuncs).Parse(arg)
+		if err != nil {
+			return Format{}, fmt.Errorf("invalid output template: %w", err)
+		}
+		return Format{Kind: Template, Template: t}, nil
+	}
+	return Format{}, fmt.Errorf("unknown output format %q, use one of %s", s, strings.Join(Formats, ", "))
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		buf, err := json.Marshal(v)
+		return string(buf), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
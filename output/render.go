@@ -0,0 +1,339 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TablePrinter is implemented by results whose table view isn't a list of
+// columns, like a post together with its content.
+type TablePrinter interface {
+	PrintTable(w io.Writer) error
+}
+
+// Renderer formats the results of commands. Commands pass it typed values,
+// either a single value or a slice of them.
+type Renderer struct {
+	Format Format
+	Out    io.Writer
+
+	// Columns selects and orders the columns of table and CSV output.
+	Columns []string
+	// Sort orders lists by columns, descending for names starting with "-".
+	Sort []string
+	// Filter drops list items that don't match.
+	Filter *Filter
+}
+
+func New(format Format, out io.Writer) *Renderer {
+	return &Renderer{Format: format, Out: out}
+}
+
+func (r *Renderer) Render(v any) error {
+	v, err := r.prepare(v)
+	if err != nil {
+		return err
+	}
+
+	switch r.Format.Kind {
+	case JSON:
+		return r.renderJSON(v)
+	case NDJSON:
+		return r.renderNDJSON(v)
+	case YAML:
+		return r.renderYAML(v)
+	case CSV:
+		return r.renderCSV(v)
+	case Template:
+		return r.renderTemplate(v)
+	}
+	return r.renderTable(v)
+}
+
+func (r *Renderer) renderJSON(v any) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(r.Out, string(buf))
+	return err
+}
+
+func (r *Renderer) renderNDJSON(v any) error {
+	items, _ := values(v)
+	enc := json.NewEncoder(r.Out)
+	for _, item := range items {
+		if !indirect(item).IsValid() {
+			continue
+		}
+		if err := enc.Encode(item.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) renderYAML(v any) error {
+	// go through JSON so the json tags and the field order of the types are
+	// kept, yaml.v2 would sort the keys of a map
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	ordered, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(ordered)
+	if err != nil {
+		return err
+	}
+	_, err = r.Out.Write(out)
+	return err
+}
+
+func (r *Renderer) renderCSV(v any) error {
+	items, list := values(v)
+	columns, ok, err := r.columns(elemType(v), !list)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("csv output is not supported for this result, use --output json")
+	}
+
+	w := csv.NewWriter(r.Out)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, item := range items {
+		item = indirect(item)
+		if !item.IsValid() {
+			continue
+		}
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = FormatValue(c.Value(item.Interface()))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (r *Renderer) renderTemplate(v any) error {
+	items, _ := values(v)
+	for _, item := range items {
+		if !indirect(item).IsValid() {
+			continue
+		}
+		if err := r.Format.Template.Execute(r.Out, item.Interface()); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(r.Out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) renderTable(v any) error {
+	if p, ok := v.(TablePrinter); ok {
+		return p.PrintTable(r.Out)
+	}
+
+	items, list := values(v)
+	columns, ok, err := r.columns(elemType(v), !list)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return r.renderPlain(v)
+	}
+
+	w := tabwriter.NewWriter(r.Out, 1, 1, 1, ' ', 0)
+	if !list {
+		item := indirect(items[0])
+		if !item.IsValid() {
+			return nil
+		}
+		for _, c := range columns {
+			fmt.Fprintf(w, "%s:\t%s\n", c.Label(), FormatValue(c.Value(item.Interface())))
+		}
+		return w.Flush()
+	}
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header()
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, item := range items {
+		item = indirect(item)
+		if !item.IsValid() {
+			continue
+		}
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = strings.ReplaceAll(FormatValue(c.Value(item.Interface())), "\n", " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// renderPlain prints values without registered columns, like maps and
+// strings.
+func (r *Renderer) renderPlain(v any) error {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return fmt.Errorf("table output is not supported for %s, use --output json", rv.Type())
+	case reflect.Map:
+		keys := make([]string, 0, rv.Len())
+		byKey := map[string]reflect.Value{}
+		for _, k := range rv.MapKeys() {
+			key := fmt.Sprint(k.Interface())
+			keys = append(keys, key)
+			byKey[key] = rv.MapIndex(k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(r.Out, "%s: %s\n", k, FormatValue(byKey[k].Interface()))
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fmt.Fprintln(r.Out, FormatValue(rv.Index(i).Interface()))
+		}
+		return nil
+	}
+	_, err := fmt.Fprintln(r.Out, FormatValue(rv.Interface()))
+	return err
+}
+
+// FormatValue formats a column value for table and CSV output.
+func FormatValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return FormatValue(*t)
+	case []string:
+		return strings.Join(t, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// values returns the elements of a slice, or v itself with list unset.
+func values(v any) (items []reflect.Value, list bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items = make([]reflect.Value, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i)
+		}
+		return items, true
+	}
+	return []reflect.Value{rv}, false
+}
+
+func elemType(v any) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return reflect.TypeOf(struct{}{})
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return t.Elem()
+	}
+	return t
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// decodeOrdered decodes JSON into yaml.MapSlice values, keeping the order of
+// the object keys.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			m := yaml.MapSlice{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err := dec.Token()
+			return m, err
+		}
+		s := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, value)
+		}
+		_, err := dec.Token()
+		return s, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		return t.Float64()
+	}
+	return tok, nil
+}
@@ -0,0 +1,141 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID        uint64    `json:"id"`
+	Title     string    `json:"title"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type custom struct {
+	Name string `json:"name"`
+}
+
+func (c custom) PrintTable(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "custom %s\n", c.Name)
+	return err
+}
+
+func init() {
+	Register[item](
+		Col("id", func(i item) any { return i.ID }),
+		Col("title", func(i item) any { return i.Title }),
+		Col("created_at", func(i item) any { return i.CreatedAt }),
+		Col("note", func(i item) any { return i.Note }).AsDetail(),
+	)
+}
+
+var items = []item{
+	{ID: 1, Title: "first, post", Note: "a\nb", CreatedAt: time.Date(2024, 9, 30, 18, 42, 0, 0, time.UTC)},
+	{ID: 2, Title: "second"},
+}
+
+func render(t *testing.T, format string, v any) string {
+	t.Helper()
+	f, err := ParseFormat(format)
+	if err != nil {
+		t.Fatalf("ParseFormat(%q) error = %v", format, err)
+	}
+	var buf bytes.Buffer
+	if err := New(f, &buf).Render(v); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"xml", "json=x", "template=", "template={{.ID"} {
+		if _, err := ParseFormat(s); err == nil {
+			t.Errorf("ParseFormat(%q) error = nil", s)
+		}
+	}
+	if f, err := ParseFormat(""); err != nil || f.Kind != Table {
+		t.Errorf("ParseFormat(\"\") = %v, %v; want table", f.Kind, err)
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	got := render(t, "table", items)
+	want := "ID TITLE       CREATED_AT\n" +
+		"1  first, post 2024-09-30T18:42:00Z\n" +
+		"2  second      \n"
+	if got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+
+	got = render(t, "table", &items[0])
+	if !strings.Contains(got, "Created At: 2024-09-30T18:42:00Z") || !strings.Contains(got, "Note:") {
+		t.Errorf("single table = %q, want labels and detail columns", got)
+	}
+
+	if got := render(t, "table", custom{Name: "x"}); got != "custom x\n" {
+		t.Errorf("custom table = %q", got)
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	got := render(t, "csv", items)
+	want := "id,title,created_at\n" +
+		"1,\"first, post\",2024-09-30T18:42:00Z\n" +
+		"2,second,\n"
+	if got != want {
+		t.Errorf("csv =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderNDJSON(t *testing.T) {
+	got := render(t, "ndjson", items)
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"id":1,"title":"first, post"`) {
+		t.Errorf("ndjson = %q", got)
+	}
+}
+
+func TestRenderYAMLKeepsFieldOrder(t *testing.T) {
+	got := render(t, "yaml", items[1])
+	want := "id: 2\ntitle: second\nnote: \"\"\ncreated_at: \"0001-01-01T00:00:00Z\"\n"
+	if got != want {
+		t.Errorf("yaml =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	got := render(t, "template={{.ID}}:{{upper .Title}}", items)
+	if got != "1:FIRST, POST\n2:SECOND\n" {
+		t.Errorf("template = %q", got)
+	}
+}
+
+func TestRenderPlain(t *testing.T) {
+	got := render(t, "table", map[string]any{"b": 2, "a": "x"})
+	if got != "a: x\nb: 2\n" {
+		t.Errorf("map table = %q", got)
+	}
+	f, _ := ParseFormat("csv")
+	if err := New(f, io.Discard).Render(custom{}); err == nil {
+		t.Error("csv of a type without columns error = nil")
+	}
+}
+
+func TestRenderNil(t *testing.T) {
+	for _, format := range []string{"ndjson", "template={{.ID}}"} {
+		if got := render(t, format, nil); got != "" {
+			t.Errorf("%s of nil = %q", format, got)
+		}
+		if got := render(t, format, (*item)(nil)); got != "" {
+			t.Errorf("%s of a nil pointer = %q", format, got)
+		}
+		if got := render(t, format, []*item{nil, &items[1]}); !strings.HasPrefix(got, "2") && !strings.HasPrefix(got, `{"id":2`) {
+			t.Errorf("%s skipping nil items = %q", format, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// columns returns the columns to print for t, the ones selected with
+// --columns or the registered defaults.
+func (r *Renderer) columns(t reflect.Type, detail bool) ([]Column, bool, error) {
+	if len(r.Columns) == 0 {
+		columns, ok := ColumnsOf(t, detail)
+		return columns, ok, nil
+	}
+
+	byName, err := columnsByName(t, r.Columns)
+	if err != nil {
+		return nil, false, err
+	}
+	columns := make([]Column, len(r.Columns))
+	for i, name := range r.Columns {
+		columns[i] = byName[strings.ToLower(name)]
+	}
+	return columns, true, nil
+}
+
+// prepare applies --filter and --sort to a slice of results.
+func (r *Renderer) prepare(v any) (any, error) {
+	rv := reflect.ValueOf(v)
+	if (r.Filter == nil && len(r.Sort) == 0) || rv.Kind() != reflect.Slice {
+		return v, nil
+	}
+
+	names := append([]string{}, r.Sort...)
+	for i, name := range names {
+		names[i] = strings.TrimPrefix(name, "-")
+	}
+	if r.Filter != nil {
+		names = append(names, r.Filter.Names()...)
+	}
+	byName, err := columnsByName(rv.Type().Elem(), names)
+	if err != nil {
+		return nil, err
+	}
+	get := func(item reflect.Value) func(string) any {
+		item = indirect(item)
+		return func(name string) any {
+			if !item.IsValid() {
+				return nil
+			}
+			return byName[strings.ToLower(name)].Value(item.Interface())
+		}
+	}
+
+	ret := reflect.MakeSlice(rv.Type(), 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i)
+		if r.Filter != nil {
+			ok, err := r.Filter.Match(get(item))
+			if err != nil {
+				return nil, fmt.Errorf("filter %s: %w", r.Filter, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		ret = reflect.Append(ret, item)
+	}
+
+	if len(r.Sort) > 0 {
+		sort.SliceStable(ret.Interface(), func(i, j int) bool {
+			a, b := get(ret.Index(i)), get(ret.Index(j))
+			for _, key := range r.Sort {
+				name := strings.TrimPrefix(key, "-")
+				c := compareValues(a(name), b(name))
+				if c == 0 {
+					continue
+				}
+				if strings.HasPrefix(key, "-") {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	return ret.Interface(), nil
+}
+
+// columnsByName looks up the named columns of t, detail columns included.
+func columnsByName(t reflect.Type, names []string) (map[string]Column, error) {
+	all, ok := ColumnsOf(t, true)
+	if !ok {
+		return nil, fmt.Errorf("--columns, --sort and --filter are not supported for this result")
+	}
+	byName := make(map[string]Column, len(all))
+	for _, c := range all {
+		byName[c.Name] = c
+	}
+	for _, name := range names {
+		if _, ok := byName[strings.ToLower(name)]; !ok {
+			available := make([]string, len(all))
+			for i, c := range all {
+				available[i] = c.Name
+			}
+			return nil, fmt.Errorf("unknown column %q, use one of %s", name, strings.Join(available, ", "))
+		}
+	}
+	return byName, nil
+}
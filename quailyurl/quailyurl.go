@@ -0,0 +1,123 @@
+// Package quailyurl parses the URLs of Quaily lists and posts, and makes
+// them.
+//
+// It accepts the shapes URLs come in:
+//
+//	https://quaily.com/{list}
+//	https://quaily.com/{list}/p/{post}
+//	https://quaily.com/{list}/{post}
+//	https://quaily.com/{list}/p/{post}/content
+//	https://blog.example.com/p/{post}
+//
+// Lists and posts are IDs or slugs. The scheme, www., query strings and
+// fragments are optional, and trailing path segments are ignored. Custom
+// domains of lists are resolved with a map of domains to lists.
+package quailyurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/config"
+)
+
+const host = "quaily.com"
+
+// Ref is what a URL points to, a list, or a post of a list when Post is set.
+// Both are IDs or slugs, as the API takes them.
+type Ref struct {
+	List string
+	Post string
+}
+
+func (r Ref) IsPost() bool {
+	return r.Post != ""
+}
+
+// ListURL is the canonical URL of a list.
+func ListURL(list string) string {
+	return "https://" + host + "/" + url.PathEscape(list)
+}
+
+// PostURL is the canonical URL of a post.
+func PostURL(list, post string) string {
+	return ListURL(list) + "/p/" + url.PathEscape(post)
+}
+
+// Resolver parses URLs, with the custom domains of lists.
+type Resolver struct {
+	// Domains maps custom domains, like blog.example.com, to list IDs or
+	// slugs.
+	Domains map[string]string
+}
+
+// Default resolves the custom domains of the reader.custom_domains config.
+func Default() *Resolver {
+	return &Resolver{Domains: config.Current().Reader.Domains()}
+}
+
+// Parse parses the URL of a list or a post.
+func (r *Resolver) Parse(raw string) (Ref, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Ref{}, err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return Ref{}, fmt.Errorf("%q is not a web URL", raw)
+	}
+
+	var segs []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	h := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	if h == host {
+		switch {
+		case len(segs) == 0:
+			return Ref{}, fmt.Errorf("%q is not the URL of a list or post", raw)
+		case len(segs) == 1:
+			return Ref{List: segs[0]}, nil
+		case segs[1] == "p" && len(segs) >= 3:
+			return Ref{List: segs[0], Post: segs[2]}, nil
+		case segs[1] == "p":
+			return Ref{List: segs[0]}, nil
+		}
+		return Ref{List: segs[0], Post: segs[1]}, nil
+	}
+
+	list, ok := r.Domains[h]
+	if !ok {
+		return Ref{}, fmt.Errorf("%s is not quaily.com or a custom domain of a list, map it with quail-cli config set reader.custom_domains %s=<list>", u.Hostname(), h)
+	}
+	switch {
+	case len(segs) == 0:
+		return Ref{List: list}, nil
+	case segs[0] == "p" && len(segs) >= 2:
+		return Ref{List: list, Post: segs[1]}, nil
+	case segs[0] == "p":
+		return Ref{List: list}, nil
+	}
+	return Ref{List: list, Post: segs[0]}, nil
+}
+
+// ParsePost parses the URL of a post.
+func (r *Resolver) ParsePost(raw string) (Ref, error) {
+	ref, err := r.Parse(raw)
+	if err == nil && !ref.IsPost() {
+		err = fmt.Errorf("%q is the URL of a list, not a post", raw)
+	}
+	return ref, err
+}
+
+// Parse parses a URL on quaily.com, without custom domains.
+func Parse(raw string) (Ref, error) {
+	return (&Resolver{}).Parse(raw)
+}
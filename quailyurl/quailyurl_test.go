@@ -0,0 +1,51 @@
+package quailyurl
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	r := &Resolver{Domains: map[string]string{"blog.example.com": "weekly"}}
+	for _, tc := range []struct {
+		url  string
+		want Ref
+	}{
+		{"https://quaily.com/weekly", Ref{List: "weekly"}},
+		{"https://quaily.com/weekly/", Ref{List: "weekly"}},
+		{"https://quaily.com/weekly/p/hello", Ref{List: "weekly", Post: "hello"}},
+		{"https://quaily.com/weekly/hello", Ref{List: "weekly", Post: "hello"}},
+		{"https://quaily.com/weekly/hello/content", Ref{List: "weekly", Post: "hello"}},
+		{"https://quaily.com/weekly/p/hello/content?utm_source=x#top", Ref{List: "weekly", Post: "hello"}},
+		{"http://www.quaily.com/12/p/345", Ref{List: "12", Post: "345"}},
+		{"quaily.com/weekly/p/hello", Ref{List: "weekly", Post: "hello"}},
+		{"  https://QUAILY.com/weekly/p/hello\n", Ref{List: "weekly", Post: "hello"}},
+		{"https://blog.example.com/p/hello?ref=1", Ref{List: "weekly", Post: "hello"}},
+		{"https://blog.example.com/hello", Ref{List: "weekly", Post: "hello"}},
+		{"https://blog.example.com", Ref{List: "weekly"}},
+	} {
+		got, err := r.Parse(tc.url)
+		if err != nil || got != tc.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", tc.url, got, err, tc.want)
+		}
+	}
+
+	for _, url := range []string{"https://quaily.com", "https://example.org/p/hello", "ftp://quaily.com/a/b", "://"} {
+		if got, err := r.Parse(url); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", url, got)
+		}
+	}
+	if _, err := r.ParsePost("https://quaily.com/weekly"); err == nil {
+		t.Error("ParsePost() of a list URL didn't fail")
+	}
+}
+
+func TestURLsRoundTrip(t *testing.T) {
+	u := PostURL("weekly", "hello world")
+	if u != "https://quaily.com/weekly/p/hello%20world" {
+		t.Errorf("PostURL() = %s", u)
+	}
+	if got, err := Parse(u); err != nil || got != (Ref{List: "weekly", Post: "hello world"}) {
+		t.Errorf("Parse(PostURL()) = %+v, %v", got, err)
+	}
+	if got, err := Parse(ListURL("weekly")); err != nil || got != (Ref{List: "weekly"}) {
+		t.Errorf("Parse(ListURL()) = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,236 @@
+// Package readercache keeps subscribed posts, their content and comments on
+// disk, so the reader commands work offline.
+//
+// `reader sync` fills the cache: feed.json holds the latest subscribed posts
+// in order, and posts/<id>.json each post with its content and comments.
+// Files are replaced atomically, so reading commands and concurrent syncs
+// never see a half-written file.
+package readercache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/util"
+)
+
+// ErrNotCached is returned for posts that aren't in the cache.
+var ErrNotCached = errors.New("not in the offline cache, run quail-cli reader sync")
+
+const feedFile = "feed.json"
+
+// Entry is a cached post.
+type Entry struct {
+	Post         client.Post         `json:"post"`
+	Content      *client.PostContent `json:"content,omitempty"`
+	ContentError string              `json:"content_error,omitempty"`
+	Comments     []client.Comment    `json:"comments"`
+	FetchedAt    time.Time           `json:"fetched_at"`
+}
+
+// Feed is the list of subscribed posts at the last sync.
+type Feed struct {
+	Posts    []client.Post `json:"posts"`
+	SyncedAt time.Time     `json:"synced_at"`
+}
+
+type Cache struct {
+	dir string
+}
+
+func Open(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Default returns the cache in the cache directory.
+func Default() *Cache {
+	return Open(filepath.Join(util.GetCacheDir(), "reader"))
+}
+
+func (c *Cache) postPath(postID uint64) string {
+	return filepath.Join(c.dir, "posts", strconv.FormatUint(postID, 10)+".json")
+}
+
+// Put stores a post.
+func (c *Cache) Put(e *Entry) error {
+	return writeJSON(c.postPath(e.Post.ID), e)
+}
+
+// Get returns a cached post, or ErrNotCached.
+func (c *Cache) Get(postID uint64) (*Entry, error) {
+	e := &Entry{}
+	if err := readJSON(c.postPath(postID), e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Find returns a cached post by list and post, each an ID or a slug.
+func (c *Cache) Find(listIDOrSlug, postIDOrSlug string) (*Entry, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		p := e.entry.Post
+		if matches(listIDOrSlug, p.ListID, p.List.Slug) && matches(postIDOrSlug, p.ID, p.Slug) {
+			return c.Get(p.ID)
+		}
+	}
+	return nil, ErrNotCached
+}
+
+func matches(idOrSlug string, id uint64, slug string) bool {
+	return idOrSlug == strconv.FormatUint(id, 10) || (slug != "" && idOrSlug == slug)
+}
+
+// SetFeed stores the subscribed posts of a sync.
+func (c *Cache) SetFeed(posts []client.Post, syncedAt time.Time) error {
+	return writeJSON(filepath.Join(c.dir, feedFile), &Feed{Posts: posts, SyncedAt: syncedAt})
+}
+
+// Feed returns the subscribed posts of the last sync, or ErrNotCached.
+func (c *Cache) Feed() (*Feed, error) {
+	f := &Feed{}
+	if err := readJSON(filepath.Join(c.dir, feedFile), f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Files returns the paths of the cached posts.
+func (c *Cache) Files() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "posts", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ret := paths[:0]
+	for _, path := range paths {
+		// files starting with a dot are being written
+		if !strings.HasPrefix(filepath.Base(path), ".") {
+			ret = append(ret, path)
+		}
+	}
+	return ret, nil
+}
+
+// Entries returns the cached posts, the oldest first.
+func (c *Cache) Entries() ([]*Entry, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Entry, len(entries))
+	for i := range entries {
+		ret[i] = &entries[i].entry
+	}
+	return ret, nil
+}
+
+type cachedFile struct {
+	path  string
+	size  int64
+	entry Entry
+}
+
+// entries returns the cached posts, the oldest first.
+func (c *Cache) entries() ([]cachedFile, error) {
+	paths, err := c.Files()
+	if err != nil {
+		return nil, err
+	}
+	var ret []cachedFile
+	for _, path := range paths {
+		f := cachedFile{path: path}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		f.size = info.Size()
+		if err := readJSON(path, &f.entry); err != nil {
+			// a broken file is evicted like an old one
+			f.entry.FetchedAt = time.Time{}
+		}
+		ret = append(ret, f)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].entry.FetchedAt.Before(ret[j].entry.FetchedAt)
+	})
+	return ret, nil
+}
+
+// Evict removes posts fetched longer than maxAge ago, then the oldest posts
+// until the cache is at most maxSize bytes. Zero disables a bound. It
+// returns how many posts it removed.
+func (c *Cache) Evict(maxAge time.Duration, maxSize int64) (int, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	removed := 0
+	for _, e := range entries {
+		tooOld := maxAge > 0 && time.Since(e.entry.FetchedAt) > maxAge
+		tooBig := maxSize > 0 && total > maxSize
+		if !tooOld && !tooBig {
+			continue
+		}
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		total -= e.size
+		removed++
+	}
+	return removed, nil
+}
+
+func readJSON(path string, v any) error {
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotCached
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
+
+// writeJSON writes v to a temporary file and renames it over path.
+func writeJSON(path string, v any) error {
+	dir := filepath.Dir(path)
+	if err := util.EnsureDir(dir); err != nil {
+		return err
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+strings.TrimSuffix(filepath.Base(path), ".json")+"-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
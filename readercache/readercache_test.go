@@ -0,0 +1,63 @@
+package readercache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+func TestPutFind(t *testing.T) {
+	c := Open(t.TempDir())
+	if _, err := c.Get(1); !errors.Is(err, ErrNotCached) {
+		t.Fatalf("Get() of an empty cache error = %v, want ErrNotCached", err)
+	}
+
+	e := &Entry{
+		Post:      client.Post{ID: 1, ListID: 7, Slug: "hello", List: client.List{Slug: "weekly"}},
+		Content:   &client.PostContent{FreeContent: "Hi"},
+		FetchedAt: time.Now(),
+	}
+	if err := c.Put(e); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	for _, ref := range [][2]string{{"weekly", "hello"}, {"7", "1"}, {"weekly", "1"}} {
+		got, err := c.Find(ref[0], ref[1])
+		if err != nil || got.Content.FreeContent != "Hi" {
+			t.Errorf("Find(%q, %q) = %+v, %v", ref[0], ref[1], got, err)
+		}
+	}
+	if _, err := c.Find("weekly", "other"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Find() of a missing post error = %v, want ErrNotCached", err)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	c := Open(t.TempDir())
+	now := time.Now()
+	for i, age := range []time.Duration{40 * 24 * time.Hour, 2 * time.Hour, time.Hour, 0} {
+		c.Put(&Entry{Post: client.Post{ID: uint64(i + 1)}, FetchedAt: now.Add(-age)})
+	}
+
+	n, err := c.Evict(30*24*time.Hour, 0)
+	if err != nil || n != 1 {
+		t.Fatalf("Evict() by age = %d, %v, want 1", n, err)
+	}
+	if _, err := c.Get(1); !errors.Is(err, ErrNotCached) {
+		t.Error("old post wasn't evicted")
+	}
+
+	entries, _ := c.entries()
+	size := entries[0].size
+	n, err = c.Evict(0, size*2)
+	if err != nil || n != 1 {
+		t.Fatalf("Evict() by size = %d, %v, want 1", n, err)
+	}
+	if _, err := c.Get(2); !errors.Is(err, ErrNotCached) {
+		t.Error("the oldest post wasn't evicted first")
+	}
+	if _, err := c.Get(4); err != nil {
+		t.Errorf("the newest post was evicted: %v", err)
+	}
+}
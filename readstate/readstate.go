@@ -0,0 +1,203 @@
+// Package readstate records which posts were read, in a file in the state
+// directory.
+//
+// Every change takes the lock of the file, reads it, and replaces it with a
+// new file, so concurrent quail-cli processes don't lose each other's
+// changes. Marking a post unread is recorded too, rather than deleting its
+// entry, so importing the state of another machine keeps whichever change is
+// newer.
+package readstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/util"
+)
+
+const (
+	fileName = "read-state.json"
+	version  = 1
+	// lockTimeout is how long a change waits for another process.
+	lockTimeout = 5 * time.Second
+)
+
+// Entry is the read state of a post.
+type Entry struct {
+	Read bool `json:"read"`
+	// UpdatedAt is when the post was last marked read or unread.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// State is the read state of all posts, by post ID.
+type State struct {
+	Version int              `json:"version"`
+	Posts   map[uint64]Entry `json:"posts"`
+}
+
+func newState() *State {
+	return &State{Version: version, Posts: map[uint64]Entry{}}
+}
+
+// IsRead reports whether the post was read.
+func (s *State) IsRead(postID uint64) bool {
+	return s.Posts[postID].Read
+}
+
+// Mark marks a post read or unread at the given time.
+func (s *State) Mark(postID uint64, read bool, at time.Time) {
+	s.Posts[postID] = Entry{Read: read, UpdatedAt: at.UTC()}
+}
+
+// Merge adds the entries of other that are newer than the ones in s, and
+// returns how many it changed.
+func (s *State) Merge(other *State) int {
+	n := 0
+	for id, e := range other.Posts {
+		cur, ok := s.Posts[id]
+		if ok && !e.UpdatedAt.After(cur.UpdatedAt) {
+			continue
+		}
+		if !ok || cur.Read != e.Read {
+			n++
+		}
+		s.Posts[id] = e
+	}
+	return n
+}
+
+// Store is the file the state is kept in.
+type Store struct {
+	path string
+}
+
+// Open returns the store at path. The file is created on the first change.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Default returns the store in the state directory.
+func Default() *Store {
+	return Open(filepath.Join(util.GetStateDir(), fileName))
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the state. A missing file is an empty state.
+func (s *Store) Load() (*State, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return newState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Update runs fn on the current state while holding the lock, and saves the
+// state if fn succeeds.
+func (s *Store) Update(fn func(*State) error) error {
+	unlock, err := util.LockFile(s.path, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return s.save(state)
+}
+
+// save writes the state to a temporary file and renames it over the store,
+// so readers never see a half-written file.
+func (s *Store) save(state *State) error {
+	dir := filepath.Dir(s.path)
+	if err := util.EnsureDir(dir); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".read-state-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Encode(tmp, state); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// MarkRead marks posts read now.
+func (s *Store) MarkRead(postIDs ...uint64) error {
+	return s.mark(true, postIDs)
+}
+
+// MarkUnread marks posts unread now.
+func (s *Store) MarkUnread(postIDs ...uint64) error {
+	return s.mark(false, postIDs)
+}
+
+func (s *Store) mark(read bool, postIDs []uint64) error {
+	now := time.Now()
+	return s.Update(func(state *State) error {
+		for _, id := range postIDs {
+			state.Mark(id, read, now)
+		}
+		return nil
+	})
+}
+
+// Import merges an exported state into the store, and returns how many posts
+// changed.
+func (s *Store) Import(r io.Reader) (int, error) {
+	other, err := Decode(r)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	err = s.Update(func(state *State) error {
+		n = state.Merge(other)
+		return nil
+	})
+	return n, err
+}
+
+// Encode writes state as JSON, the format of the store and of exports.
+func Encode(w io.Writer, state *State) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(state)
+}
+
+func Decode(r io.Reader) (*State, error) {
+	state := newState()
+	if err := json.NewDecoder(r).Decode(state); err != nil {
+		return nil, fmt.Errorf("invalid read state: %w", err)
+	}
+	if state.Version > version {
+		return nil, fmt.Errorf("read state version %d is newer than this quail-cli supports", state.Version)
+	}
+	if state.Posts == nil {
+		state.Posts = map[uint64]Entry{}
+	}
+	state.Version = version
+	return state, nil
+}
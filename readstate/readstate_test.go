@@ -0,0 +1,96 @@
+package readstate
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMarkAndLoad(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "state", fileName))
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+	if state.IsRead(1) {
+		t.Fatal("empty state has post 1 read")
+	}
+
+	if err := store.MarkRead(1, 2); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+	if err := store.MarkUnread(2); err != nil {
+		t.Fatalf("MarkUnread() error = %v", err)
+	}
+	state, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !state.IsRead(1) || state.IsRead(2) || state.IsRead(3) {
+		t.Errorf("read state = %v, want only post 1 read", state.Posts)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), fileName))
+
+	var wg sync.WaitGroup
+	for i := uint64(1); i <= 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.MarkRead(i); err != nil {
+				t.Errorf("MarkRead(%d) error = %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(state.Posts) != 20 {
+		t.Errorf("got %d posts, want 20: a concurrent update was lost", len(state.Posts))
+	}
+}
+
+func TestImportKeepsNewerChanges(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), fileName))
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Update(func(s *State) error {
+		s.Mark(1, true, old)
+		s.Mark(2, true, old.Add(time.Hour))
+		return nil
+	})
+
+	other := newState()
+	other.Mark(1, false, old.Add(time.Minute))
+	other.Mark(2, false, old)
+	other.Mark(3, true, old)
+	var buf bytes.Buffer
+	if err := Encode(&buf, other); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Import() changed %d posts, want 2", n)
+	}
+	state, _ := store.Load()
+	if state.IsRead(1) || !state.IsRead(2) || !state.IsRead(3) {
+		t.Errorf("read state = %v, want posts 2 and 3 read", state.Posts)
+	}
+}
+
+func TestDecodeRejectsNewerVersion(t *testing.T) {
+	if _, err := Decode(bytes.NewBufferString(`{"version": 2, "posts": {}}`)); err == nil {
+		t.Error("Decode() accepted a newer version")
+	}
+}
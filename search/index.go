@@ -0,0 +1,282 @@
+// Package search is a full-text index of posts kept on disk, ranked with
+// BM25.
+//
+// The index covers posts from sources, like the reader cache of `reader
+// sync` and the Markdown files of the current project. It is rebuilt when
+// the files of a source change.
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/util"
+)
+
+const (
+	// BM25 parameters, the usual defaults.
+	k1 = 1.2
+	b  = 0.75
+	// titleWeight counts title terms as if they appeared this many times.
+	titleWeight = 3
+	// indexVersion changes when the file format or tokenizer does.
+	indexVersion = 1
+)
+
+// Doc is an indexed post.
+type Doc struct {
+	// Source is the name of the source the post comes from.
+	Source      string    `json:"source"`
+	PostID      uint64    `json:"post_id,omitempty"`
+	ListID      uint64    `json:"list_id,omitempty"`
+	List        string    `json:"list"`
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	Tags        []string  `json:"tags,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	// Location is the URL of the post, or the path of its file.
+	Location string `json:"location"`
+	Text     string `json:"-"`
+}
+
+type posting struct {
+	Doc int
+	TF  int
+}
+
+type Index struct {
+	Version     int
+	Fingerprint string
+	Docs        []Doc
+	DocLen      []int
+	AvgLen      float64
+	Postings    map[string][]posting
+}
+
+// ErrEmpty is returned when there are no posts to search.
+var ErrEmpty = errors.New("nothing to search, run quail-cli reader sync first or search in a project directory")
+
+// Build indexes docs.
+func Build(docs []Doc) *Index {
+	idx := &Index{Version: indexVersion, Docs: docs, DocLen: make([]int, len(docs)), Postings: map[string][]posting{}}
+	total := 0
+	for i, d := range docs {
+		tf := map[string]int{}
+		for _, t := range tokenize(d.Title) {
+			tf[t.term] += titleWeight
+		}
+		for _, t := range tokenize(d.Text) {
+			tf[t.term]++
+		}
+		for _, tag := range d.Tags {
+			for _, t := range tokenize(tag) {
+				tf[t.term]++
+			}
+		}
+		for term, n := range tf {
+			idx.Postings[term] = append(idx.Postings[term], posting{Doc: i, TF: n})
+			idx.DocLen[i] += n
+		}
+		total += idx.DocLen[i]
+	}
+	if len(docs) > 0 {
+		idx.AvgLen = float64(total) / float64(len(docs))
+	}
+	return idx
+}
+
+// Options filter search results.
+type Options struct {
+	// List matches the list slug or ID of a post.
+	List string
+	// Tag matches one of the tags of a post, ignoring case.
+	Tag string
+	// Since and Until bound the publishing time of posts, when set.
+	Since time.Time
+	Until time.Time
+	Limit int
+	// Open and Close surround matches in snippets.
+	Open  string
+	Close string
+}
+
+type Result struct {
+	Doc
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// Search returns the posts matching any term of query, the best first.
+func (idx *Index) Search(query string, opts Options) []Result {
+	qterms := terms(query)
+	scores := map[int]float64{}
+	n := float64(len(idx.Docs))
+	for _, term := range qterms {
+		postings := idx.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			if !opts.match(idx.Docs[p.Doc]) {
+				continue
+			}
+			tf := float64(p.TF)
+			norm := k1 * (1 - b + b*float64(idx.DocLen[p.Doc])/idx.AvgLen)
+			scores[p.Doc] += idf * tf * (k1 + 1) / (tf + norm)
+		}
+	}
+
+	ret := make([]Result, 0, len(scores))
+	for i, score := range scores {
+		ret = append(ret, Result{Doc: idx.Docs[i], Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Score != ret[j].Score {
+			return ret[i].Score > ret[j].Score
+		}
+		return ret[i].PublishedAt.After(ret[j].PublishedAt)
+	})
+	if opts.Limit > 0 && len(ret) > opts.Limit {
+		ret = ret[:opts.Limit]
+	}
+
+	open, close := opts.Open, opts.Close
+	if open == "" && close == "" {
+		open, close = "**", "**"
+	}
+	for i := range ret {
+		ret[i].Snippet = snippet(ret[i].Text, qterms, open, close)
+	}
+	return ret
+}
+
+func (o Options) match(d Doc) bool {
+	if o.List != "" && !strings.EqualFold(o.List, d.List) && o.List != strconv.FormatUint(d.ListID, 10) {
+		return false
+	}
+	if o.Tag != "" {
+		found := false
+		for _, tag := range d.Tags {
+			found = found || strings.EqualFold(tag, o.Tag)
+		}
+		if !found {
+			return false
+		}
+	}
+	if !o.Since.IsZero() && d.PublishedAt.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && d.PublishedAt.After(o.Until) {
+		return false
+	}
+	return true
+}
+
+// Source provides posts to index.
+type Source interface {
+	Name() string
+	// Files lists the files the posts are read from. The index is rebuilt
+	// when they change.
+	Files() ([]string, error)
+	Docs() ([]Doc, error)
+}
+
+// DefaultPath is where the index is kept.
+func DefaultPath() string {
+	return filepath.Join(util.GetCacheDir(), "search", "index.gob")
+}
+
+// Update returns the index at path, rebuilding and saving it first when the
+// files of the sources changed since it was built, or when force is set.
+func Update(path string, force bool, sources ...Source) (*Index, error) {
+	fp, err := fingerprint(sources)
+	if err != nil {
+		return nil, err
+	}
+	if !force {
+		idx, err := Load(path)
+		if err == nil && idx.Version == indexVersion && idx.Fingerprint == fp {
+			return idx, nil
+		}
+	}
+
+	var docs []Doc
+	for _, s := range sources {
+		d, err := s.Docs()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, d...)
+	}
+	idx := Build(docs)
+	idx.Fingerprint = fp
+	return idx, idx.Save(path)
+}
+
+// fingerprint identifies the files of the sources by name, size and
+// modification time.
+func fingerprint(sources []Source) (string, error) {
+	h := sha256.New()
+	for _, s := range sources {
+		files, err := s.Files()
+		if err != nil {
+			return "", err
+		}
+		sort.Strings(files)
+		h.Write([]byte(s.Name() + "\n"))
+		for _, f := range files {
+			info, err := os.Stat(f)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00%d\n", f, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	idx := &Index{}
+	if err := gob.NewDecoder(f).Decode(idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Save writes the index to a temporary file and renames it over path.
+func (idx *Index) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := util.EnsureDir(dir); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".index-*.gob")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(idx); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/readercache"
+)
+
+func testDocs() []Doc {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	return []Doc{
+		{Title: "Gardening notes", List: "weekly", ListID: 7, Tags: []string{"home"}, PublishedAt: day(1),
+			Text: "Tomatoes need sun. Water the tomatoes in the morning, not at night."},
+		{Title: "Tomato soup", List: "kitchen", Tags: []string{"Food"}, PublishedAt: day(10),
+			Text: "A recipe with tomato, onion and garlic."},
+		{Title: "Go generics", List: "weekly", ListID: 7, PublishedAt: day(20),
+			Text: "Type parameters arrived in Go 1.18. 番茄炒蛋是一道家常菜。"},
+	}
+}
+
+func TestSearchRanksAndFilters(t *testing.T) {
+	idx := Build(testDocs())
+
+	got := idx.Search("tomatoes", Options{})
+	if len(got) != 1 || got[0].Title != "Gardening notes" {
+		t.Fatalf("Search(tomatoes) = %+v, want the gardening post", got)
+	}
+	if want := "**Tomatoes** need sun. Water the **tomatoes** in the morning, not at night."; got[0].Snippet != want {
+		t.Errorf("snippet = %q, want %q", got[0].Snippet, want)
+	}
+
+	got = idx.Search("tomato soup", Options{})
+	if len(got) != 1 || got[0].Title != "Tomato soup" {
+		t.Fatalf("Search(tomato soup) = %+v", got)
+	}
+
+	got = idx.Search("go tomatoes", Options{List: "7"})
+	if len(got) != 2 {
+		t.Errorf("Search() with list 7 returned %d results, want 2", len(got))
+	}
+	if got := idx.Search("tomato", Options{Tag: "food"}); len(got) != 1 {
+		t.Errorf("Search() with tag food returned %d results, want 1", len(got))
+	}
+	if got := idx.Search("tomatoes tomato go", Options{Since: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), Until: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)}); len(got) != 1 || got[0].Title != "Tomato soup" {
+		t.Errorf("Search() in a date range = %+v, want the soup", got)
+	}
+}
+
+func TestSearchCJK(t *testing.T) {
+	got := Build(testDocs()).Search("番茄", Options{Open: "[", Close: "]"})
+	if len(got) != 1 || got[0].Title != "Go generics" {
+		t.Fatalf("Search(番茄) = %+v", got)
+	}
+	if !strings.Contains(got[0].Snippet, "[番茄]炒蛋") {
+		t.Errorf("snippet = %q, want the match highlighted", got[0].Snippet)
+	}
+}
+
+func TestSnippetWindow(t *testing.T) {
+	text := strings.Repeat("filler words here ", 30) + "the needle is here" + strings.Repeat(" more filler", 30)
+	got := snippet(text, []string{"needle"}, "<", ">")
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<needle>") {
+		t.Errorf("snippet() = %q", got)
+	}
+}
+
+type fakeSource struct {
+	file string
+	docs []Doc
+}
+
+func (s *fakeSource) Name() string             { return "fake" }
+func (s *fakeSource) Files() ([]string, error) { return []string{s.file}, nil }
+func (s *fakeSource) Docs() ([]Doc, error)     { return s.docs, nil }
+
+func TestUpdateRebuildsWhenFilesChange(t *testing.T) {
+	dir := t.TempDir()
+	src := &fakeSource{file: filepath.Join(dir, "post.md"), docs: testDocs()[:1]}
+	os.WriteFile(src.file, []byte("a"), 0600)
+	path := filepath.Join(dir, "index.gob")
+
+	if _, err := Update(path, false, src); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	src.docs = testDocs()
+	idx, err := Update(path, false, src)
+	if err != nil || len(idx.Docs) != 1 {
+		t.Fatalf("Update() without changes rebuilt the index: %d docs, %v", len(idx.Docs), err)
+	}
+
+	os.WriteFile(src.file, []byte("ab"), 0600)
+	idx, err = Update(path, false, src)
+	if err != nil || len(idx.Docs) != 3 {
+		t.Fatalf("Update() after a change = %d docs, %v, want 3", len(idx.Docs), err)
+	}
+	if got := idx.Search("tomato", Options{}); len(got) != 1 || got[0].Snippet == "" {
+		t.Errorf("loaded index search = %+v", got)
+	}
+}
+
+func TestReaderCacheKeepsFreeAndPaidWordsApart(t *testing.T) {
+	cache := readercache.Open(t.TempDir())
+	err := cache.Put(&readercache.Entry{
+		Post:    client.Post{ID: 1, Title: "Paid post"},
+		Content: &client.PostContent{FreeContent: "the free teaser", PaidContent: "secret recipe"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := ReaderCache{Cache: cache}.Docs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := Build(docs)
+	for _, q := range []string{"teaser", "secret"} {
+		if got := idx.Search(q, Options{}); len(got) != 1 {
+			t.Errorf("Search(%s) returned %d results, want 1", q, len(got))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetLen is the length of snippets, in runes.
+const snippetLen = 160
+
+// snippet returns the part of text with the most matches of terms, with
+// every match surrounded by open and close.
+func snippet(text string, terms []string, open, close string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	want := map[string]bool{}
+	for _, t := range terms {
+		want[t] = true
+	}
+	var matches []token
+	for _, t := range tokenize(text) {
+		if want[t.term] {
+			matches = append(matches, t)
+		}
+	}
+
+	// the window of snippetLen runes, as bytes from start, with the most
+	// matches
+	start, best := 0, -1
+	for i, m := range matches {
+		n := 0
+		for _, m2 := range matches[i:] {
+			if utf8.RuneCountInString(text[m.start:m2.end]) > snippetLen {
+				break
+			}
+			n++
+		}
+		if n > best {
+			start, best = m.start, n
+		}
+	}
+	// start a little before the first match, at a word boundary
+	if start > 0 {
+		back := start
+		for i := 0; i < snippetLen/4 && back > 0; i++ {
+			_, size := utf8.DecodeLastRuneInString(text[:back])
+			back -= size
+		}
+		if sp := strings.IndexByte(text[back:start], ' '); sp >= 0 && back > 0 {
+			back += sp + 1
+		}
+		start = back
+	}
+	end := start
+	for i := 0; i < snippetLen && end < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range mergeSpans(matches) {
+		if m.end <= start || m.start >= end {
+			continue
+		}
+		s, e := max(m.start, start), min(m.end, end)
+		b.WriteString(text[pos:s])
+		b.WriteString(open + text[s:e] + close)
+		pos = e
+	}
+	b.WriteString(text[pos:end])
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// mergeSpans joins overlapping matches, like the character pairs of CJK
+// text.
+func mergeSpans(matches []token) []token {
+	var ret []token
+	for _, m := range matches {
+		if n := len(ret); n > 0 && m.start <= ret[n-1].end {
+			ret[n-1].end = max(ret[n-1].end, m.end)
+			continue
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/markdown"
+	"github.com/quailyquaily/quail-cli/readercache"
+	"github.com/quailyquaily/quail-cli/util"
+)
+
+// ReaderCache indexes the posts cached by `reader sync`.
+type ReaderCache struct {
+	Cache *readercache.Cache
+}
+
+func (s ReaderCache) Name() string {
+	return "reader"
+}
+
+func (s ReaderCache) Files() ([]string, error) {
+	return s.Cache.Files()
+}
+
+func (s ReaderCache) Docs() ([]Doc, error) {
+	entries, err := s.Cache.Entries()
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Doc, 0, len(entries))
+	for _, e := range entries {
+		p := e.Post
+		list := p.List.Slug
+		if list == "" {
+			list = strconv.FormatUint(p.ListID, 10)
+		}
+		text := p.Summary + "\n"
+		if e.Content != nil {
+			text += plainText(e.Content.FreeContent) + "\n" + plainText(e.Content.PaidContent)
+		}
+		docs = append(docs, Doc{
+			Source:      s.Name(),
+			PostID:      p.ID,
+			ListID:      p.ListID,
+			List:        list,
+			Slug:        p.Slug,
+			Title:       p.Title,
+			Tags:        splitTags(p.Tags),
+			PublishedAt: p.PublishedAt,
+			Location:    p.URL(),
+			Text:        text,
+		})
+	}
+	return docs, nil
+}
+
+// Project indexes the Markdown posts of a project.
+type Project struct {
+	Project *config.Project
+	// List is the list the posts are published to, and FrontmatterMapping
+	// maps their frontmatter keys, like for `post upsert`.
+	List               string
+	FrontmatterMapping map[string]string
+}
+
+func (s Project) Name() string {
+	return "project"
+}
+
+func (s Project) Files() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(s.Project.ContentDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if s.Project.Ignored(path) || (d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != s.Project.ContentDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (s Project) Docs() ([]Doc, error) {
+	files, err := s.Files()
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Doc, 0, len(files))
+	for _, path := range files {
+		fm, content, err := util.ParseMarkdownWithFrontMatter(path, s.FrontmatterMapping)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		d := Doc{
+			Source:   s.Name(),
+			List:     s.List,
+			Slug:     fm.Slug,
+			Title:    fm.Title,
+			Tags:     splitTags(fm.Tags),
+			Location: path,
+			Text:     fm.Summary + "\n" + plainText(content),
+		}
+		if d.Title == "" {
+			d.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if fm.Datetime != nil {
+			d.PublishedAt = *fm.Datetime
+		}
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
+
+func plainText(src string) string {
+	if src == "" {
+		return ""
+	}
+	return markdown.Parse(src).Text()
+}
+
+func splitTags(tags string) []string {
+	var ret []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ret = append(ret, tag)
+		}
+	}
+	return ret
+}
+
+// DefaultSources are the reader cache, and the posts of the current project
+// when there is one.
+func DefaultSources(cfg *config.Config) []Source {
+	sources := []Source{ReaderCache{Cache: readercache.Default()}}
+	if cfg.Project != nil {
+		sources = append(sources, Project{Project: cfg.Project, List: cfg.Post.List, FrontmatterMapping: cfg.Post.FrontmatterMapping})
+	}
+	return sources
+}
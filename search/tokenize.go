@@ -0,0 +1,74 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is a normalized term and where it is in the text.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into lower case words. Chinese, Japanese and Korean
+// text has no spaces between words, so runs of these scripts are split into
+// overlapping pairs of characters instead, which matches words of any length
+// without a dictionary.
+func tokenize(text string) []token {
+	var tokens []token
+	wordStart := -1
+	var cjk []token // the characters of the current CJK run
+
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, cjk[0])
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, token{term: cjk[i].term + cjk[i+1].term, start: cjk[i].start, end: cjk[i+1].end})
+		}
+		cjk = cjk[:0]
+	}
+	flushWord := func(end int) {
+		if wordStart >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[wordStart:end]), start: wordStart, end: end})
+			wordStart = -1
+		}
+	}
+
+	for i, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord(i)
+			cjk = append(cjk, token{term: string(r), start: i, end: i + len(string(r))})
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			flushCJK()
+			flushWord(i)
+		}
+	}
+	flushCJK()
+	flushWord(len(text))
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// terms returns the distinct terms of a query.
+func terms(query string) []string {
+	var ret []string
+	seen := map[string]bool{}
+	for _, t := range tokenize(query) {
+		if !seen[t.term] {
+			seen[t.term] = true
+			ret = append(ret, t.term)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,144 @@
+// Package subscribers reads the subscribers of a list, for its owner, with
+// filters on free or paid subscriptions and on email delivery.
+//
+// The API pages subscribers without filtering them, so filtered reads page
+// through the list and filter here.
+package subscribers
+
+import (
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+// Source is the part of the API subscribers are read from, implemented by
+// *client.Client.
+type Source interface {
+	GetListSubscribers(listIDOrSlug string, offset, limit int) (*client.SubscribersResponse, error)
+}
+
+// PageSize is how many subscribers are read at once when paging.
+const PageSize = 100
+
+// IsPaid tells whether a subscription is paid and not expired at now.
+func IsPaid(s client.Subscription, now time.Time) bool {
+	if s.PaidExpiry != nil {
+		return s.PaidExpiry.After(now)
+	}
+	return s.Type == "paid"
+}
+
+// Filter selects subscribers. Nil fields match all of them.
+type Filter struct {
+	Paid         *bool
+	EmailEnabled *bool
+	// Now is when paid subscriptions are checked for expiry, the current
+	// time if zero.
+	Now time.Time
+}
+
+func (f Filter) Empty() bool {
+	return f.Paid == nil && f.EmailEnabled == nil
+}
+
+func (f Filter) Match(s client.Subscription) bool {
+	now := f.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if f.Paid != nil && IsPaid(s, now) != *f.Paid {
+		return false
+	}
+	if f.EmailEnabled != nil && s.EmailEnabled != *f.EmailEnabled {
+		return false
+	}
+	return true
+}
+
+// List returns up to limit subscribers of a list matching the filter,
+// skipping offset of them.
+func List(src Source, list string, f Filter, offset, limit int) ([]client.Subscription, error) {
+	if f.Empty() {
+		resp, err := src.GetListSubscribers(list, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data.Items, nil
+	}
+
+	ret := []client.Subscription{}
+	if limit <= 0 {
+		return ret, nil
+	}
+	err := Each(src, list, f, func(s client.Subscription) bool {
+		if offset > 0 {
+			offset--
+			return true
+		}
+		ret = append(ret, s)
+		return len(ret) < limit
+	})
+	return ret, err
+}
+
+// All returns all subscribers of a list matching the filter.
+func All(src Source, list string, f Filter) ([]client.Subscription, error) {
+	ret := []client.Subscription{}
+	err := Each(src, list, f, func(s client.Subscription) bool {
+		ret = append(ret, s)
+		return true
+	})
+	return ret, err
+}
+
+// Each pages through the subscribers of a list and calls fn with those
+// matching the filter, until fn returns false.
+func Each(src Source, list string, f Filter, fn func(client.Subscription) bool) error {
+	for offset := 0; ; offset += PageSize {
+		resp, err := src.GetListSubscribers(list, offset, PageSize)
+		if err != nil {
+			return err
+		}
+		items := resp.Data.Items
+		for _, s := range items {
+			if f.Match(s) && !fn(s) {
+				return nil
+			}
+		}
+		if len(items) < PageSize {
+			return nil
+		}
+	}
+}
+
+// Counts are the numbers of subscribers of a list.
+type Counts struct {
+	List         string `json:"list"`
+	Total        int    `json:"total"`
+	Free         int    `json:"free"`
+	Paid         int    `json:"paid"`
+	EmailEnabled int    `json:"email_enabled"`
+}
+
+// Count counts the subscribers of a list matching the filter.
+func Count(src Source, list string, f Filter) (Counts, error) {
+	now := f.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	f.Now = now
+	ret := Counts{List: list}
+	err := Each(src, list, f, func(s client.Subscription) bool {
+		ret.Total++
+		if IsPaid(s, now) {
+			ret.Paid++
+		} else {
+			ret.Free++
+		}
+		if s.EmailEnabled {
+			ret.EmailEnabled++
+		}
+		return true
+	})
+	return ret, err
+}
@@ -0,0 +1,106 @@
+package subscribers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/client/fakeapi"
+)
+
+var now = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// newServer serves a list of n subscribers. Every third one is paid, one
+// in five of those expired, and every other one has email enabled.
+func newServer(n int) *fakeapi.Server {
+	s := fakeapi.New(1)
+	s.AddList(client.List{ID: 7, Slug: "gophers"})
+	var subs []client.Subscription
+	for i := 1; i <= n; i++ {
+		sub := client.Subscription{ID: uint64(i), UserID: uint64(100 + i), Type: "free", EmailEnabled: i%2 == 0}
+		if i%3 == 0 {
+			expiry := now.AddDate(0, 1, 0)
+			if i%15 == 0 {
+				expiry = now.AddDate(0, -1, 0)
+			}
+			sub.Type, sub.PaidExpiry = "paid", &expiry
+		}
+		subs = append(subs, sub)
+	}
+	s.AddSubscribers(7, subs...)
+	return s
+}
+
+func ptr(b bool) *bool { return &b }
+
+func TestList(t *testing.T) {
+	s := newServer(250)
+	defer s.Close()
+	cl := s.Client()
+
+	got, err := List(cl, "gophers", Filter{}, 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 5 || got[0].ID != 11 || got[0].ListID != 7 {
+		t.Fatalf("unfiltered = %+v", got)
+	}
+	if s.Requests() != 1 {
+		t.Errorf("unfiltered took %d requests, want 1", s.Requests())
+	}
+
+	got, err = List(cl, "gophers", Filter{Paid: ptr(true), EmailEnabled: ptr(true), Now: now}, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// paid with email: 6, 12, 18, 24, 36, ... (30 expired)
+	var ids []uint64
+	for _, sub := range got {
+		ids = append(ids, sub.ID)
+	}
+	if len(ids) != 3 || ids[0] != 18 || ids[1] != 24 || ids[2] != 36 {
+		t.Fatalf("filtered IDs = %v, want [18 24 36]", ids)
+	}
+}
+
+func TestAllAndCount(t *testing.T) {
+	s := newServer(250)
+	defer s.Close()
+	cl := s.Client()
+
+	all, err := All(cl, "7", Filter{Paid: ptr(false), Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range all {
+		if IsPaid(sub, now) {
+			t.Fatalf("subscriber %d is paid", sub.ID)
+		}
+	}
+
+	counts, err := Count(cl, "gophers", Filter{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 83 paid, 16 of them expired
+	want := Counts{List: "gophers", Total: 250, Free: 183, Paid: 67, EmailEnabled: 125}
+	if counts != want {
+		t.Fatalf("Count() = %+v, want %+v", counts, want)
+	}
+	if len(all) != counts.Free {
+		t.Errorf("All(free) = %d subscribers, want %d", len(all), counts.Free)
+	}
+}
+
+func TestListError(t *testing.T) {
+	s := newServer(10)
+	defer s.Close()
+	s.FailList(7)
+
+	if _, err := Count(s.Client(), "gophers", Filter{}); err == nil {
+		t.Fatal("Count() of a failing list succeeded")
+	}
+	if _, err := List(s.Client(), "missing", Filter{}, 0, 10); err == nil {
+		t.Fatal("List() of a missing list succeeded")
+	}
+}
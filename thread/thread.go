@@ -0,0 +1,220 @@
+// Package thread arranges comments into reply threads, and renders them as
+// indented trees for the terminal.
+package thread
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/markdown"
+)
+
+// Node is a comment and the replies to it.
+type Node struct {
+	client.Comment
+	Replies []*Node
+	// Orphan is set on replies to comments that aren't in the thread, like
+	// comments on another page.
+	Orphan bool
+}
+
+// Build arranges comments into threads, by the comments they quote. Replies
+// the API embeds in RepliedComments are included. Threads and replies are
+// in the order they were written.
+func Build(comments []client.Comment) []*Node {
+	nodes := map[uint64]*Node{}
+	var order []*Node
+	var add func(c client.Comment)
+	add = func(c client.Comment) {
+		if _, ok := nodes[c.ID]; ok && c.ID != 0 {
+			return
+		}
+		replies := c.RepliedComments
+		c.RepliedComments = nil
+		n := &Node{Comment: c}
+		if c.ID != 0 {
+			nodes[c.ID] = n
+		}
+		order = append(order, n)
+		for _, r := range replies {
+			if r != nil {
+				if r.QuoteCommentID == 0 {
+					r.QuoteCommentID = c.ID
+				}
+				add(*r)
+			}
+		}
+	}
+	for _, c := range comments {
+		add(c)
+	}
+
+	var roots []*Node
+	for _, n := range order {
+		parent, ok := nodes[n.QuoteCommentID]
+		switch {
+		case n.QuoteCommentID == 0:
+			roots = append(roots, n)
+		case ok && parent != n:
+			parent.Replies = append(parent.Replies, n)
+		default:
+			n.Orphan = true
+			roots = append(roots, n)
+		}
+	}
+
+	// comments quoting each other in a cycle aren't reached from any root,
+	// the first written of each cycle becomes an orphan root
+	seen := map[*Node]bool{}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		seen[n] = true
+		for _, r := range n.Replies {
+			visit(r)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	var unseen []*Node
+	for _, n := range order {
+		if !seen[n] {
+			unseen = append(unseen, n)
+		}
+	}
+	// replies still loop here, so only the list itself is sorted
+	sort.SliceStable(unseen, func(i, j int) bool { return unseen[i].CreatedAt.Before(unseen[j].CreatedAt) })
+	for _, n := range unseen {
+		if seen[n] {
+			continue
+		}
+		parent := nodes[n.QuoteCommentID]
+		parent.Replies = slices.DeleteFunc(parent.Replies, func(r *Node) bool { return r == n })
+		n.Orphan = true
+		roots = append(roots, n)
+		visit(n)
+	}
+	sortNodes(roots)
+	return roots
+}
+
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].CreatedAt.Before(nodes[j].CreatedAt) })
+	for _, n := range nodes {
+		sortNodes(n.Replies)
+	}
+}
+
+// Options controls Lines.
+type Options struct {
+	// Width wraps comments to this many columns, 0 disables wrapping.
+	Width int
+	// Color styles authors and dates with ANSI escape sequences.
+	Color bool
+}
+
+const (
+	indent = "    "
+	// maxDepth is the deepest level indented further, so that long threads
+	// keep room for text.
+	maxDepth = 6
+)
+
+// Lines renders threads as lines. Replies are indented under the comment
+// they answer, and threads are separated by a blank line.
+func Lines(roots []*Node, opts Options) []string {
+	var lines []string
+	var walk func(nodes []*Node, depth int)
+	walk = func(nodes []*Node, depth int) {
+		for _, n := range nodes {
+			lines = append(lines, n.lines(min(depth, maxDepth), opts)...)
+			walk(n.Replies, depth+1)
+		}
+	}
+	for i, root := range roots {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		walk([]*Node{root}, 0)
+	}
+	return lines
+}
+
+func (n *Node) lines(depth int, opts Options) []string {
+	prefix := strings.Repeat(indent, depth)
+	marker := ""
+	if depth > 0 {
+		marker = "↳ "
+	}
+	meta := fmt.Sprintf("#%d", n.ID)
+	if !n.CreatedAt.IsZero() {
+		meta = n.CreatedAt.Local().Format("2006-01-02 15:04") + " · " + meta
+	}
+	header := style(opts, "1", author(n.Author, n.AuthorID)) + " " + style(opts, "2", meta)
+	if n.Orphan {
+		to := fmt.Sprintf("#%d", n.QuoteCommentID)
+		if n.QuoteComment != nil && n.QuoteComment.Author != nil {
+			to = n.QuoteComment.Author.Name + " " + to
+		}
+		header += " " + style(opts, "2", "replying to "+to)
+	}
+	lines := []string{prefix + marker + header}
+
+	body := prefix + strings.Repeat(" ", len([]rune(marker)))
+	width := 0
+	if opts.Width > 0 {
+		width = max(opts.Width-markdown.Width(body), 20)
+	}
+	for _, line := range markdown.WrapText(strings.TrimSpace(n.Content), width) {
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, body+line)
+	}
+	return lines
+}
+
+func author(u *client.User, id uint64) string {
+	if u != nil && u.Name != "" {
+		return u.Name
+	}
+	if id == 0 {
+		return "anonymous"
+	}
+	return fmt.Sprintf("user %d", id)
+}
+
+func style(opts Options, sgr, text string) string {
+	if !opts.Color {
+		return text
+	}
+	return "\x1b[" + sgr + "m" + text + "\x1b[0m"
+}
+
+// Render writes threads to w.
+func Render(w io.Writer, roots []*Node, opts Options) error {
+	for _, line := range Lines(roots, opts) {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the comment with the ID among n and its replies.
+func (n *Node) Find(id uint64) *client.Comment {
+	if n.ID == id {
+		return &n.Comment
+	}
+	for _, r := range n.Replies {
+		if c := r.Find(id); c != nil {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package thread
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/client"
+)
+
+func at(min int) time.Time {
+	return time.Date(2026, 3, 1, 10, min, 0, 0, time.Local)
+}
+
+func TestBuild(t *testing.T) {
+	alice := &client.User{Name: "Alice"}
+	comments := []client.Comment{
+		{ID: 3, QuoteCommentID: 1, Content: "reply", CreatedAt: at(3)},
+		{ID: 1, Content: "first", Author: alice, CreatedAt: at(1),
+			RepliedComments: []*client.Comment{{ID: 4, Content: "embedded", CreatedAt: at(4)}}},
+		{ID: 2, Content: "second", CreatedAt: at(2)},
+		{ID: 5, QuoteCommentID: 3, Content: "nested", CreatedAt: at(5)},
+		{ID: 6, QuoteCommentID: 99, Content: "orphan", CreatedAt: at(0), QuoteComment: &client.Comment{Author: &client.User{Name: "Carol"}}},
+	}
+	roots := Build(comments)
+	if len(roots) != 3 || roots[0].ID != 6 || !roots[0].Orphan || roots[1].ID != 1 || roots[2].ID != 2 {
+		t.Fatalf("roots = %v", ids(roots))
+	}
+	if got := ids(roots[1].Replies); got != "3 4" {
+		t.Errorf("replies of 1 = %s, want 3 4", got)
+	}
+	if got := ids(roots[1].Replies[0].Replies); got != "5" {
+		t.Errorf("replies of 3 = %s, want 5", got)
+	}
+}
+
+func TestBuildCycle(t *testing.T) {
+	// 1 and 2 quote each other, 3 replies to 2
+	roots := Build([]client.Comment{
+		{ID: 2, QuoteCommentID: 1, Content: "b", CreatedAt: at(2)},
+		{ID: 3, QuoteCommentID: 2, Content: "c", CreatedAt: at(3)},
+		{ID: 1, QuoteCommentID: 2, Content: "a", CreatedAt: at(1)},
+		{ID: 4, Content: "d", CreatedAt: at(4)},
+	})
+	if got := ids(roots); got != "1 4" || !roots[0].Orphan {
+		t.Fatalf("roots = %s, want the first comment of the cycle as an orphan, then 4", got)
+	}
+	if got := ids(roots[0].Replies); got != "2" {
+		t.Errorf("replies of 1 = %s, want 2", got)
+	}
+	if got := ids(roots[0].Replies[0].Replies); got != "3" {
+		t.Errorf("replies of 2 = %s, want 3", got)
+	}
+	if n := len(Lines(roots, Options{})); n != 9 {
+		t.Errorf("rendered %d lines, want all 4 comments in 9 lines", n)
+	}
+}
+
+func ids(nodes []*Node) string {
+	var s []string
+	for _, n := range nodes {
+		s = append(s, fmt.Sprint(n.ID))
+	}
+	return strings.Join(s, " ")
+}
+
+func TestLines(t *testing.T) {
+	roots := Build([]client.Comment{
+		{ID: 1, AuthorID: 7, Content: "A comment long enough to wrap\n\nover lines", Author: &client.User{Name: "Alice"}, CreatedAt: at(1)},
+		{ID: 2, QuoteCommentID: 1, Content: "Thanks!", Author: &client.User{Name: "Bob"}, CreatedAt: at(2)},
+		{ID: 3, Content: "Another thread", CreatedAt: at(3)},
+	})
+	got := strings.Join(Lines(roots, Options{Width: 20}), "\n")
+	want := `Alice 2026-03-01 10:01 · #1
+A comment long
+enough to wrap
+
+over lines
+    ↳ Bob 2026-03-01 10:02 · #2
+      Thanks!
+
+anonymous 2026-03-01 10:03 · #3
+Another thread`
+	if got != want {
+		t.Errorf("Lines() =\n%s\nwant\n%s", got, want)
+	}
+}
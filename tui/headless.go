@@ -0,0 +1,87 @@
+package tui
+
+// Headless runs a model without a terminal, for tests. Commands run
+// synchronously, so the model has handled all their results when Send
+// returns.
+type Headless struct {
+	model Model
+	quit  bool
+}
+
+// NewHeadless starts m with a screen of the given size.
+func NewHeadless(m Model, width, height int) *Headless {
+	h := &Headless{model: m}
+	h.run(m.Init())
+	h.Send(ResizeMsg{Width: width, Height: height})
+	return h
+}
+
+// Send passes messages to the model, in order.
+func (h *Headless) Send(msgs ...Msg) {
+	for _, msg := range msgs {
+		if h.quit {
+			return
+		}
+		var cmd Cmd
+		h.model, cmd = h.model.Update(msg)
+		h.run(cmd)
+	}
+}
+
+// Type sends the keys of terminal input, like "jj\r" or "\x1b[B" for down.
+func (h *Headless) Type(input string) {
+	for _, k := range ParseKeys([]byte(input)) {
+		h.Send(KeyMsg(k))
+	}
+}
+
+// Press sends keys by name, like "enter" or "ctrl+c".
+func (h *Headless) Press(names ...string) {
+	for _, name := range names {
+		h.Send(KeyMsg(keyByName(name)))
+	}
+}
+
+func (h *Headless) View() string {
+	return h.model.View()
+}
+
+func (h *Headless) Model() Model {
+	return h.model
+}
+
+// Quit reports whether the model quit.
+func (h *Headless) Quit() bool {
+	return h.quit
+}
+
+func (h *Headless) run(cmd Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case QuitMsg:
+		h.quit = true
+	case BatchMsg:
+		for _, c := range msg {
+			h.run(c)
+		}
+	default:
+		h.Send(msg)
+	}
+}
+
+func keyByName(name string) Key {
+	for t, n := range keyNames {
+		if n == name {
+			return Key{Type: t}
+		}
+	}
+	if name == "space" {
+		return Key{Type: KeyRune, Rune: ' '}
+	}
+	if len(name) == len("ctrl+x") && name[:5] == "ctrl+" {
+		return Key{Type: KeyCtrl, Rune: rune(name[5])}
+	}
+	return Key{Type: KeyRune, Rune: []rune(name)[0]}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package tui
+
+import "os"
+
+// cancelableInput returns in as it is: reads of consoles can't be
+// interrupted here, so a read pending when the program quits takes the next
+// input. Run should then be the last reader of in.
+func cancelableInput(in *os.File) (r *os.File, cancel func(), release func()) {
+	return in, nil, func() {}
+}
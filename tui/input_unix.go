@@ -0,0 +1,41 @@
+//go:build unix
+
+package tui
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// cancelableInput returns a reader of in whose pending read cancel
+// interrupts, so that input typed after the program quits is left to the
+// next reader of in. release undoes the changes to in once reading stopped.
+// cancel is nil when reads of in can't be interrupted.
+//
+// The reader is a nonblocking duplicate of in, which the runtime can poll and
+// so time out. Being nonblocking is shared with in, and undone by release.
+func cancelableInput(in *os.File) (r *os.File, cancel func(), release func()) {
+	fd, err := syscall.Dup(int(in.Fd()))
+	if err != nil {
+		return in, nil, func() {}
+	}
+	restore := func() { syscall.SetNonblock(int(in.Fd()), false) }
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return in, nil, func() {}
+	}
+	f := os.NewFile(uintptr(fd), in.Name())
+	// files the runtime can't poll, like some devices, take no deadline
+	if err := f.SetReadDeadline(time.Time{}); err != nil {
+		f.Close()
+		restore()
+		return in, nil, func() {}
+	}
+	cancel = func() { f.SetReadDeadline(time.Now()) }
+	release = func() {
+		f.Close()
+		restore()
+	}
+	return f, cancel, release
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"unicode/utf8"
+)
+
+type KeyType int
+
+const (
+	KeyRune KeyType = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyShiftTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDown
+	KeyDelete
+	// KeyCtrl is a control character, Rune holds the letter.
+	KeyCtrl
+)
+
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+var keyNames = map[KeyType]string{
+	KeyEnter:     "enter",
+	KeyEsc:       "esc",
+	KeyBackspace: "backspace",
+	KeyTab:       "tab",
+	KeyShiftTab:  "shift+tab",
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyLeft:      "left",
+	KeyRight:     "right",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+	KeyPgUp:      "pgup",
+	KeyPgDown:    "pgdown",
+	KeyDelete:    "delete",
+}
+
+// String names the key the way models match them: "j", "enter", "ctrl+c".
+func (k Key) String() string {
+	switch k.Type {
+	case KeyRune:
+		if k.Rune == ' ' {
+			return "space"
+		}
+		return string(k.Rune)
+	case KeyCtrl:
+		return "ctrl+" + string(k.Rune)
+	}
+	return keyNames[k.Type]
+}
+
+// escape sequences of the keys sent by common terminals
+var sequences = map[string]KeyType{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[5~": KeyPgUp, "[6~": KeyPgDown, "[3~": KeyDelete,
+	"[Z": KeyShiftTab,
+}
+
+// ParseKeys parses terminal input into keys. An escape at the end of the
+// input is the escape key.
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		k, n := parseKey(b)
+		keys = append(keys, k)
+		b = b[n:]
+	}
+	return keys
+}
+
+func parseKey(b []byte) (Key, int) {
+	switch c := b[0]; {
+	case c == 0x1b:
+		for seq, t := range sequences {
+			if len(b) > len(seq) && string(b[1:1+len(seq)]) == seq {
+				return Key{Type: t}, 1 + len(seq)
+			}
+		}
+		return Key{Type: KeyEsc}, 1
+	case c == '\r' || c == '\n':
+		return Key{Type: KeyEnter}, 1
+	case c == '\t':
+		return Key{Type: KeyTab}, 1
+	case c == 0x7f || c == 0x08:
+		return Key{Type: KeyBackspace}, 1
+	case c < 0x20:
+		return Key{Type: KeyCtrl, Rune: rune('a' + c - 1)}, 1
+	}
+	r, n := utf8.DecodeRune(b)
+	return Key{Type: KeyRune, Rune: r}, n
+}
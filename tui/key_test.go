@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"jk", "j k"},
+		{"\x1b[A\x1b[B\x1bOC", "up down right"},
+		{"\x1b[5~\x1b[6~\x1b[Z", "pgup pgdown shift+tab"},
+		{"\r\t\x7f \x03", "enter tab backspace space ctrl+c"},
+		{"\x1b", "esc"},
+		{"\x1bq", "esc q"},
+		{"中", "中"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, k := range ParseKeys([]byte(tt.in)) {
+			got = append(got, k.String())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("ParseKeys(%q) = %q, want %q", tt.in, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("\x1b[1mhello\x1b[0m", 3); got != "\x1b[1mhel\x1b[0m" {
+		t.Errorf("Truncate() = %q", got)
+	}
+	if got := Pad("中文", 5); got != "中文 " {
+		t.Errorf("Pad() = %q", got)
+	}
+	if got := Truncate("中文", 3); got != "中" {
+		t.Errorf("Truncate() = %q, wide runes must not be split", got)
+	}
+}
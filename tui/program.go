@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	// resizePoll is how often the terminal size is checked. Polling works on
+	// every platform, unlike SIGWINCH.
+	resizePoll = 250 * time.Millisecond
+)
+
+// Program runs a model in a terminal.
+type Program struct {
+	In  *os.File
+	Out *os.File
+}
+
+func NewProgram(in, out *os.File) *Program {
+	return &Program{In: in, Out: out}
+}
+
+// Run puts the terminal into raw mode and runs m until it quits. It returns
+// the final model. Input is only read while the program runs, so the process
+// can read the terminal again after Run, except on platforms whose console
+// reads can't be interrupted, like Windows, where a read left pending takes
+// the next input.
+func (p *Program) Run(m Model) (Model, error) {
+	if term.IsTerminal(int(p.In.Fd())) {
+		state, err := term.MakeRaw(int(p.In.Fd()))
+		if err != nil {
+			return m, err
+		}
+		defer term.Restore(int(p.In.Fd()), state)
+	}
+	io.WriteString(p.Out, enterAltScreen)
+	defer io.WriteString(p.Out, exitAltScreen)
+
+	msgs := make(chan Msg, 64)
+	done := make(chan struct{})
+	in, cancel, release := cancelableInput(p.In)
+	reading := make(chan struct{})
+	go func() {
+		defer close(reading)
+		p.readInput(in, msgs, done)
+	}()
+	defer func() {
+		close(done)
+		if cancel != nil {
+			cancel()
+			<-reading
+		}
+		release()
+	}()
+
+	width, height := p.size()
+	go p.watchSize(msgs, done, width, height)
+
+	run := func(cmd Cmd) {
+		if cmd != nil {
+			go func() { send(msgs, done, cmd()) }()
+		}
+	}
+	run(m.Init())
+	msgs <- ResizeMsg{Width: width, Height: height}
+
+	for msg := range msgs {
+		switch msg := msg.(type) {
+		case QuitMsg:
+			return m, nil
+		case BatchMsg:
+			for _, cmd := range msg {
+				run(cmd)
+			}
+			continue
+		}
+
+		var cmd Cmd
+		m, cmd = m.Update(msg)
+		run(cmd)
+		if _, err := io.WriteString(p.Out, frame(m.View())); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// send sends msg unless the program is done, and tells whether it did.
+func send(msgs chan<- Msg, done <-chan struct{}, msg Msg) bool {
+	select {
+	case msgs <- msg:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func (p *Program) readInput(in io.Reader, msgs chan<- Msg, done <-chan struct{}) {
+	buf := make([]byte, 256)
+	for {
+		n, err := in.Read(buf)
+		for _, k := range ParseKeys(buf[:n]) {
+			if !send(msgs, done, KeyMsg(k)) {
+				return
+			}
+		}
+		if err != nil {
+			send(msgs, done, QuitMsg{})
+			return
+		}
+	}
+}
+
+func (p *Program) watchSize(msgs chan<- Msg, done <-chan struct{}, width, height int) {
+	ticker := time.NewTicker(resizePoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		w, h := p.size()
+		if w != width || h != height {
+			width, height = w, h
+			if !send(msgs, done, ResizeMsg{Width: w, Height: h}) {
+				return
+			}
+		}
+	}
+}
+
+func (p *Program) size() (int, int) {
+	w, h, err := term.GetSize(int(p.Out.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
+
+// frame redraws the screen from the top left corner, clearing what's left
+// of every line and below the last one.
+func frame(view string) string {
+	var buf []byte
+	buf = append(buf, "\x1b[H"...)
+	start := 0
+	for i := 0; i <= len(view); i++ {
+		if i == len(view) || view[i] == '\n' {
+			buf = append(buf, view[start:i]...)
+			buf = append(buf, "\x1b[K"...)
+			if i < len(view) {
+				buf = append(buf, "\r\n"...)
+			}
+			start = i + 1
+		}
+	}
+	return string(append(buf, "\x1b[J"...))
+}
@@ -0,0 +1,59 @@
+//go:build unix
+
+package tui
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// quitOnQ quits on q.
+type quitOnQ struct{}
+
+func (m quitOnQ) Init() Cmd { return nil }
+func (m quitOnQ) Update(msg Msg) (Model, Cmd) {
+	if k, ok := msg.(KeyMsg); ok && k.Type == KeyRune && k.Rune == 'q' {
+		return m, Quit
+	}
+	return m, nil
+}
+func (m quitOnQ) View() string { return "" }
+
+func TestRunLeavesInputAfterQuit(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	out, err := os.Create(filepath.Join(t.TempDir(), "screen"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	ran := make(chan error, 1)
+	go func() {
+		_, err := NewProgram(r, out).Run(quitOnQ{})
+		ran <- err
+	}()
+	w.WriteString("q")
+	select {
+	case err := <-ran:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return on q")
+	}
+
+	// what is typed after the program quit goes to the next reader
+	w.WriteString("yes\n")
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil || line != "yes\n" {
+		t.Fatalf("read %q, %v after Run, want the next line", line, err)
+	}
+}
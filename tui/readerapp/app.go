@@ -0,0 +1,576 @@
+// Package readerapp is the full-screen reader of `quail-cli reader tui`.
+//
+// The screen shows subscriptions on the left and their posts on the right.
+// Opening a post shows the article, and below it the comments when they are
+// toggled on. The post list loads the next page when the cursor gets close
+// to its end.
+package readerapp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/markdown"
+	"github.com/quailyquaily/quail-cli/tui"
+)
+
+// Source is the part of the API the reader uses. *client.Client implements
+// it.
+type Source interface {
+	GetSubscriptions() (*client.SubscriptionsResponse, error)
+	GetSubscribedPosts(offset, limit int) (*client.SearchResponse, error)
+	GetListPosts(listID uint64, offset, limit int) (*client.SearchResponse, error)
+	GetPostContent(listIDOrSlug, postIDOrSlug string) (*client.PostContentResponse, error)
+	GetCommentsByPost(postID uint64, offset, limit int) (*client.CommentsResponse, error)
+	CreateComment(postID uint64, content string) (*client.CommentResponse, error)
+}
+
+const (
+	pageSize = 20
+	// loadAhead is how close to the end of the post list the cursor gets
+	// before the next page is loaded.
+	loadAhead = 5
+	// commentLimit is how many comments of a post are shown.
+	commentLimit = 100
+	subsWidth    = 24
+	maxWidth     = 100
+)
+
+type pane int
+
+const (
+	paneSubs pane = iota
+	panePosts
+	paneArticle
+	paneComments
+)
+
+type inputMode int
+
+const (
+	inputNone inputMode = iota
+	inputSearch
+	inputComment
+)
+
+type (
+	subsMsg struct {
+		subs []client.Subscription
+		err  error
+	}
+	postsMsg struct {
+		gen   int
+		posts []client.Post
+		err   error
+	}
+	contentMsg struct {
+		postID  uint64
+		content client.PostContent
+		err     error
+	}
+	commentsMsg struct {
+		postID   uint64
+		comments []client.Comment
+		err      error
+	}
+	commentPostedMsg struct {
+		postID  uint64
+		comment client.Comment
+		err     error
+	}
+)
+
+// list is a cursor over the items of a pane that match its filter.
+type list struct {
+	cursor int
+	top    int
+	filter string
+}
+
+func (l *list) move(delta, n int) {
+	l.cursor = max(0, min(l.cursor+delta, n-1))
+}
+
+// scroll keeps the cursor within the height rows shown.
+func (l *list) scroll(height int) {
+	if l.cursor < l.top {
+		l.top = l.cursor
+	}
+	if l.cursor >= l.top+height {
+		l.top = l.cursor - height + 1
+	}
+	l.top = max(0, l.top)
+}
+
+type App struct {
+	src    Source
+	width  int
+	height int
+	focus  pane
+
+	subs     []client.Subscription
+	subList  list
+	listID   uint64 // the selected subscription, 0 is all of them
+	listName string
+
+	posts        []client.Post
+	postList     list
+	gen          int // ignores pages of a previous selection
+	postsLoading bool
+	postsDone    bool
+
+	post        *client.Post
+	content     *client.PostContent
+	contentErr  string
+	article     []string
+	articleTop  int
+	matches     []int
+	match       int
+	showComment bool
+	comments    []client.Comment
+	commentsErr string
+	commentsTop int
+	commentsFor uint64
+
+	mode   inputMode
+	input  []rune
+	status string
+}
+
+func New(src Source) *App {
+	return &App{src: src, focus: panePosts, listName: "All", width: 80, height: 24}
+}
+
+func (m *App) Init() tui.Cmd {
+	return tui.Batch(m.loadSubs, m.loadPosts())
+}
+
+func (m *App) loadSubs() tui.Msg {
+	resp, err := m.src.GetSubscriptions()
+	if err != nil {
+		return subsMsg{err: err}
+	}
+	return subsMsg{subs: resp.Data}
+}
+
+func (m *App) loadPosts() tui.Cmd {
+	if m.postsLoading || m.postsDone {
+		return nil
+	}
+	m.postsLoading = true
+	gen, listID, offset := m.gen, m.listID, len(m.posts)
+	return func() tui.Msg {
+		var resp *client.SearchResponse
+		var err error
+		if listID == 0 {
+			resp, err = m.src.GetSubscribedPosts(offset, pageSize)
+		} else {
+			resp, err = m.src.GetListPosts(listID, offset, pageSize)
+		}
+		if err != nil {
+			return postsMsg{gen: gen, err: err}
+		}
+		return postsMsg{gen: gen, posts: resp.Data.Items}
+	}
+}
+
+func (m *App) loadContent(p client.Post) tui.Cmd {
+	return func() tui.Msg {
+		resp, err := m.src.GetPostContent(strconv.FormatUint(p.ListID, 10), strconv.FormatUint(p.ID, 10))
+		if err != nil {
+			return contentMsg{postID: p.ID, err: err}
+		}
+		return contentMsg{postID: p.ID, content: resp.Data}
+	}
+}
+
+func (m *App) loadComments(postID uint64) tui.Cmd {
+	return func() tui.Msg {
+		resp, err := m.src.GetCommentsByPost(postID, 0, commentLimit)
+		if err != nil {
+			return commentsMsg{postID: postID, err: err}
+		}
+		return commentsMsg{postID: postID, comments: resp.Data.Items}
+	}
+}
+
+func (m *App) postComment(postID uint64, content string) tui.Cmd {
+	return func() tui.Msg {
+		resp, err := m.src.CreateComment(postID, content)
+		if err != nil {
+			return commentPostedMsg{postID: postID, err: err}
+		}
+		return commentPostedMsg{postID: postID, comment: resp.Data}
+	}
+}
+
+func (m *App) Update(msg tui.Msg) (tui.Model, tui.Cmd) {
+	switch msg := msg.(type) {
+	case tui.ResizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.renderArticle()
+	case tui.KeyMsg:
+		return m, m.handleKey(msg.String(), tui.Key(msg))
+	case subsMsg:
+		if msg.err != nil {
+			m.status = "failed to load subscriptions: " + msg.err.Error()
+			break
+		}
+		m.subs = msg.subs
+	case postsMsg:
+		if msg.gen != m.gen {
+			break
+		}
+		m.postsLoading = false
+		if msg.err != nil {
+			m.status = "failed to load posts: " + msg.err.Error()
+			m.postsDone = true
+			break
+		}
+		m.posts = append(m.posts, msg.posts...)
+		m.postsDone = len(msg.posts) < pageSize
+		return m, m.loadMore()
+	case contentMsg:
+		if m.post == nil || m.post.ID != msg.postID {
+			break
+		}
+		if msg.err != nil {
+			m.contentErr = msg.err.Error()
+		} else {
+			m.content = &msg.content
+		}
+		m.renderArticle()
+	case commentsMsg:
+		if m.post == nil || m.post.ID != msg.postID {
+			break
+		}
+		if msg.err != nil {
+			m.commentsErr = msg.err.Error()
+			break
+		}
+		m.comments = msg.comments
+	case commentPostedMsg:
+		if msg.err != nil {
+			m.status = "failed to post comment: " + msg.err.Error()
+			break
+		}
+		if m.post != nil && m.post.ID == msg.postID {
+			m.comments = append(m.comments, msg.comment)
+			m.commentsTop = max(0, len(m.commentLines())-m.commentsHeight())
+		}
+		m.status = "comment posted"
+	}
+	return m, nil
+}
+
+func (m *App) handleKey(name string, k tui.Key) tui.Cmd {
+	if name == "ctrl+c" {
+		return tui.Quit
+	}
+	if m.mode != inputNone {
+		return m.handleInput(name, k)
+	}
+	m.status = ""
+
+	switch name {
+	case "q":
+		return tui.Quit
+	case "/":
+		m.mode = inputSearch
+		m.input = nil
+		return nil
+	}
+	if m.post != nil {
+		return m.handleArticleKey(name)
+	}
+	return m.handleListKey(name)
+}
+
+func (m *App) handleListKey(name string) tui.Cmd {
+	l, n := &m.postList, len(m.visiblePosts())
+	if m.focus == paneSubs {
+		l, n = &m.subList, len(m.visibleSubs())
+	}
+	switch name {
+	case "j", "down":
+		l.move(1, n)
+	case "k", "up":
+		l.move(-1, n)
+	case "g", "home":
+		l.move(-n, n)
+	case "G", "end":
+		l.move(n, n)
+	case "pgdown", "ctrl+d", "space":
+		l.move(m.bodyHeight(), n)
+	case "pgup", "ctrl+u":
+		l.move(-m.bodyHeight(), n)
+	case "tab", "shift+tab":
+		m.focus = panePosts + paneSubs - m.focus
+	case "h", "left":
+		m.focus = paneSubs
+	case "l", "right":
+		m.focus = panePosts
+	case "esc":
+		l.filter = ""
+		l.cursor = 0
+	case "enter":
+		if m.focus == paneSubs {
+			return m.selectSub()
+		}
+		return m.openPost()
+	}
+	if m.focus == panePosts {
+		return m.loadMore()
+	}
+	return nil
+}
+
+func (m *App) handleArticleKey(name string) tui.Cmd {
+	top, lines, height := &m.articleTop, len(m.article), m.articleHeight()
+	if m.focus == paneComments {
+		top, lines, height = &m.commentsTop, len(m.commentLines()), m.commentsHeight()
+	}
+	scroll := func(delta int) {
+		*top = max(0, min(*top+delta, lines-height))
+	}
+	switch name {
+	case "j", "down":
+		scroll(1)
+	case "k", "up":
+		scroll(-1)
+	case "g", "home":
+		scroll(-lines)
+	case "G", "end":
+		scroll(lines)
+	case "pgdown", "ctrl+d", "space":
+		scroll(height)
+	case "pgup", "ctrl+u":
+		scroll(-height)
+	case "n":
+		m.nextMatch(1)
+	case "N":
+		m.nextMatch(-1)
+	case "tab", "shift+tab":
+		if m.showComment {
+			m.focus = paneArticle + paneComments - m.focus
+		}
+	case "c":
+		return m.toggleComments()
+	case "a":
+		cmd := m.openComments()
+		m.mode = inputComment
+		m.input = nil
+		return cmd
+	case "esc", "h", "left":
+		m.closePost()
+	}
+	return nil
+}
+
+func (m *App) handleInput(name string, k tui.Key) tui.Cmd {
+	switch name {
+	case "esc":
+		if m.mode == inputSearch {
+			m.setSearch("")
+		}
+		m.mode = inputNone
+		return nil
+	case "enter":
+		mode, text := m.mode, strings.TrimSpace(string(m.input))
+		m.mode = inputNone
+		if mode == inputComment && text != "" && m.post != nil {
+			m.status = "posting comment…"
+			return m.postComment(m.post.ID, text)
+		}
+		return nil
+	case "backspace":
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	default:
+		if k.Type != tui.KeyRune {
+			return nil
+		}
+		m.input = append(m.input, k.Rune)
+	}
+	if m.mode == inputSearch {
+		m.setSearch(string(m.input))
+		if m.post == nil && m.focus == panePosts {
+			return m.loadMore()
+		}
+	}
+	return nil
+}
+
+// setSearch filters the focused list, or finds the lines of the article
+// matching query.
+func (m *App) setSearch(query string) {
+	switch m.focus {
+	case paneSubs:
+		m.subList.filter, m.subList.cursor = query, 0
+	case panePosts:
+		m.postList.filter, m.postList.cursor = query, 0
+	default:
+		m.matches, m.match = nil, 0
+		if query == "" {
+			return
+		}
+		q := strings.ToLower(query)
+		for i, line := range m.article {
+			if strings.Contains(strings.ToLower(tui.StripANSI(line)), q) {
+				m.matches = append(m.matches, i)
+			}
+		}
+		for i, line := range m.matches {
+			if line >= m.articleTop {
+				m.match = i
+				break
+			}
+		}
+		m.nextMatch(0)
+	}
+}
+
+func (m *App) nextMatch(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (m.match + delta + len(m.matches)) % len(m.matches)
+	m.articleTop = max(0, min(m.matches[m.match], len(m.article)-m.articleHeight()))
+}
+
+// loadMore loads the next page when the cursor is close to the end of the
+// posts.
+func (m *App) loadMore() tui.Cmd {
+	if len(m.visiblePosts())-m.postList.cursor > loadAhead {
+		return nil
+	}
+	return m.loadPosts()
+}
+
+func (m *App) selectSub() tui.Cmd {
+	subs := m.visibleSubs()
+	if len(subs) == 0 {
+		return nil
+	}
+	sub := subs[m.subList.cursor]
+	m.listID, m.listName = sub.id, sub.name
+	m.gen++
+	m.posts, m.postList = nil, list{}
+	m.postsLoading, m.postsDone = false, false
+	m.focus = panePosts
+	return m.loadPosts()
+}
+
+func (m *App) openPost() tui.Cmd {
+	posts := m.visiblePosts()
+	if len(posts) == 0 {
+		return nil
+	}
+	p := posts[m.postList.cursor]
+	m.post = &p
+	m.content, m.contentErr = nil, ""
+	m.articleTop, m.matches = 0, nil
+	m.comments, m.commentsErr, m.commentsTop, m.commentsFor = nil, "", 0, 0
+	m.focus = paneArticle
+	m.renderArticle()
+	cmd := m.loadContent(p)
+	if m.showComment {
+		return tui.Batch(cmd, m.openComments())
+	}
+	return cmd
+}
+
+func (m *App) closePost() {
+	m.post = nil
+	m.article = nil
+	m.focus = panePosts
+}
+
+func (m *App) toggleComments() tui.Cmd {
+	if m.showComment {
+		m.showComment = false
+		m.focus = paneArticle
+		return nil
+	}
+	return m.openComments()
+}
+
+func (m *App) openComments() tui.Cmd {
+	m.showComment = true
+	m.focus = paneComments
+	if m.commentsFor == m.post.ID {
+		return nil
+	}
+	m.commentsFor = m.post.ID
+	return m.loadComments(m.post.ID)
+}
+
+type subItem struct {
+	id   uint64
+	name string
+}
+
+// visibleSubs returns "All" and the subscriptions matching the filter.
+func (m *App) visibleSubs() []subItem {
+	items := []subItem{{name: "All"}}
+	for _, s := range m.subs {
+		if s.List == nil {
+			continue
+		}
+		name := s.List.Title
+		if name == "" {
+			name = s.List.Slug
+		}
+		if contains(name, m.subList.filter) {
+			items = append(items, subItem{id: s.ListID, name: name})
+		}
+	}
+	return items
+}
+
+func (m *App) visiblePosts() []client.Post {
+	if m.postList.filter == "" {
+		return m.posts
+	}
+	var ret []client.Post
+	for _, p := range m.posts {
+		if contains(p.Title, m.postList.filter) || contains(p.Summary, m.postList.filter) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+func contains(s, query string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(query))
+}
+
+// renderArticle renders the open post for the current width.
+func (m *App) renderArticle() {
+	if m.post == nil {
+		return
+	}
+	var buf strings.Builder
+	opts := markdown.TerminalOptions{Width: min(m.width-2, maxWidth), Color: true}
+	buf.WriteString("\x1b[1m" + m.post.Title + "\x1b[0m\n")
+	if m.post.List.Title != "" {
+		buf.WriteString(m.post.List.Title + " · ")
+	}
+	buf.WriteString(formatDate(m.post.PublishedAt) + "\n\n")
+	switch {
+	case m.content != nil:
+		markdown.RenderTerminal(&buf, markdown.Parse(m.content.FreeContent), opts)
+		if m.content.PaidContent != "" {
+			buf.WriteString("\n── Paid Content ──\n\n")
+			markdown.RenderTerminal(&buf, markdown.Parse(m.content.PaidContent), opts)
+		}
+	case m.contentErr != "":
+		buf.WriteString("failed to load the content: " + m.contentErr + "\n")
+	default:
+		buf.WriteString("Loading…\n")
+	}
+	m.article = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	m.articleTop = max(0, min(m.articleTop, len(m.article)-m.articleHeight()))
+}
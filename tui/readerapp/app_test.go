@@ -0,0 +1,144 @@
+package readerapp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/tui"
+)
+
+type fakeSource struct {
+	posts    []client.Post
+	offsets  []int
+	comments []client.Comment
+	created  []string
+}
+
+func newFakeSource(n int) *fakeSource {
+	f := &fakeSource{}
+	for i := 1; i <= n; i++ {
+		f.posts = append(f.posts, client.Post{ID: uint64(i), ListID: 7, Title: fmt.Sprintf("Post %d", i)})
+	}
+	return f
+}
+
+func (f *fakeSource) GetSubscriptions() (*client.SubscriptionsResponse, error) {
+	return &client.SubscriptionsResponse{Data: []client.Subscription{
+		{ListID: 7, List: &client.List{ID: 7, Title: "Gopher Weekly"}},
+	}}, nil
+}
+
+func (f *fakeSource) GetSubscribedPosts(offset, limit int) (*client.SearchResponse, error) {
+	f.offsets = append(f.offsets, offset)
+	resp := &client.SearchResponse{}
+	resp.Data.Items = f.posts[min(offset, len(f.posts)):min(offset+limit, len(f.posts))]
+	return resp, nil
+}
+
+func (f *fakeSource) GetListPosts(listID uint64, offset, limit int) (*client.SearchResponse, error) {
+	return f.GetSubscribedPosts(offset, limit)
+}
+
+func (f *fakeSource) GetPostContent(listIDOrSlug, postIDOrSlug string) (*client.PostContentResponse, error) {
+	return &client.PostContentResponse{Data: client.PostContent{
+		FreeContent: "# Post " + postIDOrSlug + "\n\nThe first paragraph.\n\nA second one about gophers.",
+	}}, nil
+}
+
+func (f *fakeSource) GetCommentsByPost(postID uint64, offset, limit int) (*client.CommentsResponse, error) {
+	resp := &client.CommentsResponse{}
+	resp.Data.Items = f.comments
+	return resp, nil
+}
+
+func (f *fakeSource) CreateComment(postID uint64, content string) (*client.CommentResponse, error) {
+	f.created = append(f.created, content)
+	return &client.CommentResponse{Data: client.Comment{PostID: postID, Content: content, Author: &client.User{Name: "me"}}}, nil
+}
+
+func screen(h *tui.Headless) string {
+	return tui.StripANSI(h.View())
+}
+
+func TestInfiniteScroll(t *testing.T) {
+	src := newFakeSource(45)
+	h := tui.NewHeadless(New(src), 80, 12)
+
+	if got := screen(h); !strings.Contains(got, "Post 1 ") || !strings.Contains(got, "Gopher Weekly") {
+		t.Fatalf("first screen misses posts or subscriptions:\n%s", got)
+	}
+	h.Type("G")
+	h.Type("G")
+	if want := []int{0, 20, 40}; fmt.Sprint(src.offsets) != fmt.Sprint(want) {
+		t.Errorf("loaded offsets %v, want %v", src.offsets, want)
+	}
+	h.Type("G")
+	if got := screen(h); !strings.Contains(got, "Post 45") {
+		t.Errorf("last post isn't shown:\n%s", got)
+	}
+	if len(src.offsets) != 3 {
+		t.Errorf("loaded past the last page: %v", src.offsets)
+	}
+}
+
+func TestReadAndComment(t *testing.T) {
+	src := newFakeSource(3)
+	src.comments = []client.Comment{{Content: "Nice post", Author: &client.User{Name: "ann"}}}
+	h := tui.NewHeadless(New(src), 80, 20)
+
+	h.Type("j\r")
+	got := screen(h)
+	if !strings.Contains(got, "Post 2") || !strings.Contains(got, "The first paragraph.") {
+		t.Fatalf("article isn't shown:\n%s", got)
+	}
+
+	h.Type("c")
+	if got := screen(h); !strings.Contains(got, "Nice post") {
+		t.Fatalf("comments aren't shown:\n%s", got)
+	}
+
+	h.Type("aGreat read\r")
+	if len(src.created) != 1 || src.created[0] != "Great read" {
+		t.Fatalf("created comments %q, want [Great read]", src.created)
+	}
+	if got := screen(h); !strings.Contains(got, "Great read") || !strings.Contains(got, "comment posted") {
+		t.Errorf("posted comment isn't shown:\n%s", got)
+	}
+
+	h.Type("\x1b")
+	if got := screen(h); !strings.Contains(got, "Post 3") {
+		t.Errorf("esc doesn't return to the list:\n%s", got)
+	}
+	h.Type("q")
+	if !h.Quit() {
+		t.Error("q doesn't quit")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	src := newFakeSource(12)
+	h := tui.NewHeadless(New(src), 80, 20)
+
+	h.Type("/Post 1")
+	got := screen(h)
+	for _, title := range []string{"Post 1 ", "Post 10", "Post 11", "Post 12"} {
+		if !strings.Contains(got, title) {
+			t.Errorf("filtered list misses %q:\n%s", title, got)
+		}
+	}
+	if strings.Contains(got, "Post 2") {
+		t.Errorf("filtered list shows Post 2:\n%s", got)
+	}
+
+	h.Type("1\r\r")
+	if got := screen(h); !strings.Contains(got, "Post 11") || !strings.Contains(got, "The first paragraph.") {
+		t.Fatalf("enter doesn't open the matching post:\n%s", got)
+	}
+
+	h.Type("/gophers\r")
+	if got := screen(h); !strings.Contains(got, "match 1/1") {
+		t.Errorf("article search shows no match:\n%s", got)
+	}
+}
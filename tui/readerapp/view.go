@@ -0,0 +1,176 @@
+package readerapp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/thread"
+	"github.com/quailyquaily/quail-cli/tui"
+)
+
+const (
+	styleReverse = "\x1b[7m"
+	styleDim     = "\x1b[2m"
+	styleBold    = "\x1b[1m"
+	styleReset   = "\x1b[0m"
+)
+
+// bodyHeight is the height between the header and the status line.
+func (m *App) bodyHeight() int {
+	return max(1, m.height-2)
+}
+
+func (m *App) commentsHeight() int {
+	if !m.showComment {
+		return 0
+	}
+	return max(1, m.bodyHeight()*2/5)
+}
+
+func (m *App) articleHeight() int {
+	if !m.showComment {
+		return m.bodyHeight()
+	}
+	// one line separates the article from the comments
+	return max(1, m.bodyHeight()-m.commentsHeight()-1)
+}
+
+func (m *App) View() string {
+	lines := []string{m.header()}
+	if m.post != nil {
+		lines = append(lines, m.articleView()...)
+	} else {
+		lines = append(lines, m.listView()...)
+	}
+	lines = append(lines, m.statusLine())
+	return strings.Join(lines, "\n")
+}
+
+func (m *App) header() string {
+	title := " quail reader · " + m.listName
+	if m.post != nil {
+		title += " · " + m.post.Title
+	}
+	return styleReverse + tui.Pad(title, m.width) + styleReset
+}
+
+func (m *App) statusLine() string {
+	switch m.mode {
+	case inputSearch:
+		return tui.Truncate("/"+string(m.input), m.width)
+	case inputComment:
+		return tui.Truncate("comment: "+string(m.input), m.width)
+	}
+	if m.status != "" {
+		return tui.Truncate(m.status, m.width)
+	}
+	hint := "j/k move · enter open · tab switch · / search · q quit"
+	if m.post != nil {
+		hint = "j/k scroll · c comments · a add comment · / search · n/N next · esc back · q quit"
+		if len(m.matches) > 0 {
+			hint = fmt.Sprintf("match %d/%d · ", m.match+1, len(m.matches)) + hint
+		}
+	}
+	return styleDim + tui.Truncate(hint, m.width) + styleReset
+}
+
+func (m *App) listView() []string {
+	height := m.bodyHeight()
+	subs := m.visibleSubs()
+	m.subList.scroll(height)
+	posts := m.visiblePosts()
+	m.postList.scroll(height)
+
+	postsWidth := max(1, m.width-subsWidth-1)
+	lines := make([]string, height)
+	for i := range lines {
+		var left, right string
+		if j := m.subList.top + i; j < len(subs) {
+			name := subs[j].name
+			if subs[j].id == m.listID {
+				name = styleBold + name + styleReset
+			}
+			left = m.row(name, subsWidth, j == m.subList.cursor, m.focus == paneSubs)
+		} else {
+			left = strings.Repeat(" ", subsWidth)
+		}
+		if j := m.postList.top + i; j < len(posts) {
+			p := posts[j]
+			date := formatDate(p.PublishedAt)
+			// the row starts with a space, and another one precedes the date
+			title := tui.Pad(p.Title, max(0, postsWidth-len(date)-2)) + " " + styleDim + date + styleReset
+			right = m.row(title, postsWidth, j == m.postList.cursor, m.focus == panePosts)
+		} else if j == len(posts) {
+			switch {
+			case m.postsLoading:
+				right = styleDim + "Loading…" + styleReset
+			case len(posts) == 0:
+				right = styleDim + "No posts" + styleReset
+			}
+		}
+		lines[i] = left + styleDim + "│" + styleReset + right
+	}
+	return lines
+}
+
+// row pads s to width, and highlights the selected row of the focused pane.
+func (m *App) row(s string, width int, selected, focused bool) string {
+	s = tui.Pad(" "+s, width)
+	if selected && focused {
+		return styleReverse + tui.StripANSI(s) + styleReset
+	}
+	if selected {
+		return styleBold + tui.StripANSI(s) + styleReset
+	}
+	return s
+}
+
+func (m *App) articleView() []string {
+	lines := window(m.article, m.articleTop, m.articleHeight(), m.width)
+	if !m.showComment {
+		return lines
+	}
+	sep := "─ Comments "
+	if m.focus == paneComments {
+		sep = styleBold + sep + styleReset
+	}
+	lines = append(lines, tui.Truncate(sep+strings.Repeat("─", max(0, m.width-11)), m.width))
+	return append(lines, window(m.commentLines(), m.commentsTop, m.commentsHeight(), m.width)...)
+}
+
+func (m *App) commentLines() []string {
+	if m.commentsErr != "" {
+		return []string{"failed to load comments: " + m.commentsErr}
+	}
+	if len(m.comments) == 0 {
+		return []string{styleDim + "No comments yet, press a to write one." + styleReset}
+	}
+	return thread.Lines(thread.Build(m.comments), thread.Options{Width: m.width, Color: true})
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return strings.Repeat(" ", len("2006-01-02"))
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// window returns height lines of lines starting at top, padded with empty
+// lines.
+func window(lines []string, top, height, width int) []string {
+	ret := make([]string, height)
+	for i := range ret {
+		if j := top + i; j < len(lines) {
+			ret[i] = tui.Truncate(lines[j], width)
+		}
+	}
+	return ret
+}
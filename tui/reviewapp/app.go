@@ -0,0 +1,285 @@
+// Package reviewapp is the moderation queue of `quail-cli comments review`.
+//
+// It steps through comments one at a time. A single key approves, rejects,
+// marks as spam or deletes the comment and moves to the next one. Decisions
+// are queued and sent in batches, so moving on doesn't wait for the API.
+// Going back to a comment whose decision wasn't sent yet changes it.
+package reviewapp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/tui"
+)
+
+// Source is the part of the API the review uses. *client.Client implements
+// it.
+type Source interface {
+	OperateComment(commentID uint64, op string) error
+	ReplyComment(postID, quoteCommentID uint64, content string) (*client.CommentResponse, error)
+}
+
+const (
+	// batchSize is how many decisions are queued before they are sent.
+	batchSize = 10
+	// concurrency is how many decisions of a batch are sent at once.
+	concurrency = 4
+	maxWidth    = 100
+)
+
+var keyOps = map[string]string{
+	"a": "approve",
+	"r": "reject",
+	"s": "spam",
+	"d": "delete",
+}
+
+// Outcome is what happened to a comment during the review.
+type Outcome struct {
+	CommentID uint64 `json:"comment_id"`
+	Operation string `json:"operation"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+type (
+	sentMsg    []Outcome
+	repliedMsg struct {
+		commentID uint64
+		err       error
+	}
+)
+
+type App struct {
+	src      Source
+	comments []client.Comment
+	width    int
+	height   int
+
+	cursor int
+	// decisions are the operations chosen and not sent yet, by comment ID,
+	// in the order of queue. sent are the ones sent, until their outcomes
+	// come back.
+	decisions map[uint64]string
+	queue     []uint64
+	sending   bool
+	sent      map[uint64]string
+	outcomes  map[uint64]Outcome
+	replies   int
+	quitting  bool
+
+	replying bool
+	input    []rune
+	status   string
+}
+
+func New(src Source, comments []client.Comment) *App {
+	return &App{
+		src:       src,
+		comments:  comments,
+		width:     80,
+		height:    24,
+		decisions: map[uint64]string{},
+		sent:      map[uint64]string{},
+		outcomes:  map[uint64]Outcome{},
+	}
+}
+
+func (m *App) Init() tui.Cmd {
+	return nil
+}
+
+func (m *App) Update(msg tui.Msg) (tui.Model, tui.Cmd) {
+	switch msg := msg.(type) {
+	case tui.ResizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tui.KeyMsg:
+		return m, m.handleKey(msg.String(), tui.Key(msg))
+	case sentMsg:
+		m.sending = false
+		failed := 0
+		for _, o := range msg {
+			m.outcomes[o.CommentID] = o
+			if !o.OK {
+				failed++
+			}
+		}
+		if failed > 0 {
+			m.status = fmt.Sprintf("%d decision(s) failed, see the summary", failed)
+		}
+		return m, m.send(m.quitting)
+	case repliedMsg:
+		if msg.err != nil {
+			m.status = "failed to reply: " + msg.err.Error()
+			break
+		}
+		m.replies++
+		m.status = "reply posted"
+	}
+	return m, nil
+}
+
+func (m *App) handleKey(name string, k tui.Key) tui.Cmd {
+	if m.replying {
+		return m.handleInput(name, k)
+	}
+	if m.quitting {
+		return nil
+	}
+	m.status = ""
+
+	switch name {
+	case "q", "ctrl+c":
+		m.quitting = true
+		return m.send(true)
+	case "n", "j", "space", "right", "down":
+		m.move(1)
+	case "p", "k", "left", "up":
+		m.move(-1)
+	case "R":
+		if c := m.current(); c != nil {
+			m.replying, m.input = true, nil
+		}
+	default:
+		op, ok := keyOps[name]
+		c := m.current()
+		if !ok || c == nil {
+			return nil
+		}
+		if _, sent := m.sent[c.ID]; sent {
+			m.status = "already sent, the decision can't be changed"
+			return nil
+		}
+		if _, queued := m.decisions[c.ID]; !queued {
+			m.queue = append(m.queue, c.ID)
+		}
+		m.decisions[c.ID] = op
+		m.move(1)
+		return m.send(false)
+	}
+	return nil
+}
+
+func (m *App) handleInput(name string, k tui.Key) tui.Cmd {
+	switch name {
+	case "esc", "ctrl+c":
+		m.replying = false
+	case "enter":
+		m.replying = false
+		text := strings.TrimSpace(string(m.input))
+		c := m.current()
+		if text == "" || c == nil {
+			return nil
+		}
+		m.status = "posting reply…"
+		return m.reply(*c, text)
+	case "backspace":
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	default:
+		if k.Type == tui.KeyRune {
+			m.input = append(m.input, k.Rune)
+		}
+	}
+	return nil
+}
+
+// move goes to another comment. Moving past the last one shows the end of
+// the queue.
+func (m *App) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.comments)))
+}
+
+func (m *App) current() *client.Comment {
+	if m.cursor >= len(m.comments) {
+		return nil
+	}
+	return &m.comments[m.cursor]
+}
+
+// send sends a batch of queued decisions once batchSize of them are queued,
+// or any queued ones with all set. Quitting ends the program once everything
+// is sent.
+func (m *App) send(all bool) tui.Cmd {
+	if m.sending {
+		return nil
+	}
+	if !all && len(m.queue) < batchSize {
+		return nil
+	}
+	if len(m.queue) == 0 {
+		if m.quitting {
+			return tui.Quit
+		}
+		return nil
+	}
+
+	n := min(len(m.queue), batchSize)
+	batch := make([]Outcome, n)
+	for i, id := range m.queue[:n] {
+		batch[i] = Outcome{CommentID: id, Operation: m.decisions[id]}
+		m.sent[id] = m.decisions[id]
+		delete(m.decisions, id)
+	}
+	m.queue = m.queue[n:]
+	m.sending = true
+	src := m.src
+	return func() tui.Msg {
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				o := &batch[i]
+				o.OK = true
+				if err := src.OperateComment(o.CommentID, o.Operation); err != nil {
+					o.OK, o.Error = false, err.Error()
+				}
+			}()
+		}
+		wg.Wait()
+		return sentMsg(batch)
+	}
+}
+
+func (m *App) reply(c client.Comment, text string) tui.Cmd {
+	return func() tui.Msg {
+		_, err := m.src.ReplyComment(c.PostID, c.ID, text)
+		return repliedMsg{commentID: c.ID, err: err}
+	}
+}
+
+// Summary counts what happened during the review.
+type Summary struct {
+	Reviewed int            `json:"reviewed"`
+	Skipped  int            `json:"skipped"`
+	Replies  int            `json:"replies"`
+	Counts   map[string]int `json:"counts"`
+	Failures []Outcome      `json:"failures,omitempty"`
+}
+
+// Summary returns the outcome of the review, once the program ended.
+func (m *App) Summary() Summary {
+	s := Summary{Replies: m.replies, Counts: map[string]int{}}
+	for _, c := range m.comments {
+		o, ok := m.outcomes[c.ID]
+		switch {
+		case !ok:
+			s.Skipped++
+		case o.OK:
+			s.Reviewed++
+			s.Counts[o.Operation]++
+		default:
+			s.Reviewed++
+			s.Failures = append(s.Failures, o)
+		}
+	}
+	return s
+}
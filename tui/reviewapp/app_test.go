@@ -0,0 +1,109 @@
+package reviewapp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/tui"
+)
+
+// fakeSource records operations and replies. The app sends them from
+// several goroutines at once.
+type fakeSource struct {
+	mu      sync.Mutex
+	ops     []string
+	replies []string
+	fail    map[uint64]bool
+}
+
+func (f *fakeSource) OperateComment(commentID uint64, op string) error {
+	if f.fail[commentID] {
+		return errors.New("forbidden")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ops = append(f.ops, fmt.Sprintf("%d %s", commentID, op))
+	return nil
+}
+
+func (f *fakeSource) ReplyComment(postID, quoteCommentID uint64, content string) (*client.CommentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies = append(f.replies, fmt.Sprintf("%d %d %s", postID, quoteCommentID, content))
+	return &client.CommentResponse{}, nil
+}
+
+func (f *fakeSource) Ops() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.ops...)
+}
+
+func (f *fakeSource) Replies() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.replies...)
+}
+
+func comments(n int) []client.Comment {
+	var ret []client.Comment
+	for i := 1; i <= n; i++ {
+		ret = append(ret, client.Comment{
+			ID: uint64(i), PostID: 100, Content: fmt.Sprintf("Comment number %d", i),
+			Author: &client.User{Name: "ann"}, Post: &client.Post{Title: "Gophers"},
+		})
+	}
+	return ret
+}
+
+func TestReview(t *testing.T) {
+	src := &fakeSource{fail: map[uint64]bool{3: true}}
+	app := New(src, comments(4))
+	h := tui.NewHeadless(app, 80, 20)
+
+	if got := h.View(); !strings.Contains(got, "Gophers") || !strings.Contains(got, "ann") || !strings.Contains(got, "Comment number 1") {
+		t.Fatalf("comment isn't shown:\n%s", got)
+	}
+
+	// spam 1, go back and approve it instead, skip 2, delete 3, reply to 4
+	h.Type("spa")
+	if len(src.Ops()) != 0 {
+		t.Fatalf("decisions sent before the batch is full: %v", src.Ops())
+	}
+	h.Type("nd")
+	h.Type("RThanks!\r")
+	if len(src.Replies()) != 1 || src.Replies()[0] != "100 4 Thanks!" {
+		t.Fatalf("replies = %q", src.Replies())
+	}
+
+	h.Type("q")
+	if !h.Quit() {
+		t.Fatalf("didn't quit after sending the decisions")
+	}
+	if got := strings.Join(src.Ops(), ","); got != "1 approve" {
+		t.Fatalf("sent %q, want 1 approve, with 3 failing", got)
+	}
+	s := app.Summary()
+	if s.Reviewed != 2 || s.Skipped != 2 || s.Replies != 1 || s.Counts["approve"] != 1 || len(s.Failures) != 1 || s.Failures[0].CommentID != 3 {
+		t.Fatalf("summary = %+v", s)
+	}
+}
+
+func TestReviewBatches(t *testing.T) {
+	src := &fakeSource{}
+	h := tui.NewHeadless(New(src, comments(batchSize+2)), 80, 20)
+
+	h.Type(strings.Repeat("a", batchSize))
+	if len(src.Ops()) != batchSize {
+		t.Fatalf("sent %d decisions once %d were queued", len(src.Ops()), batchSize)
+	}
+	h.Type("p")
+	h.Type("s")
+	if got := h.View(); !strings.Contains(got, "already sent") {
+		t.Fatalf("a sent decision was changed:\n%s", got)
+	}
+}
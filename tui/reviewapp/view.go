@@ -0,0 +1,111 @@
+package reviewapp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quailyquaily/quail-cli/client"
+	"github.com/quailyquaily/quail-cli/markdown"
+	"github.com/quailyquaily/quail-cli/tui"
+)
+
+const (
+	styleReverse = "\x1b[7m"
+	styleDim     = "\x1b[2m"
+	styleBold    = "\x1b[1m"
+	styleReset   = "\x1b[0m"
+)
+
+func (m *App) View() string {
+	lines := []string{m.header()}
+	body := m.body()
+	height := max(1, m.height-2)
+	if len(body) > height {
+		body = append(body[:height-1], styleDim+"…"+styleReset)
+	}
+	for len(body) < height {
+		body = append(body, "")
+	}
+	lines = append(lines, body...)
+	lines = append(lines, m.statusLine())
+	return strings.Join(lines, "\n")
+}
+
+func (m *App) header() string {
+	title := fmt.Sprintf(" quail review · %d/%d", min(m.cursor+1, len(m.comments)), len(m.comments))
+	if n := len(m.queue); n > 0 {
+		title += fmt.Sprintf(" · %d queued", n)
+	}
+	return styleReverse + tui.Pad(title, m.width) + styleReset
+}
+
+func (m *App) body() []string {
+	c := m.current()
+	if c == nil {
+		if m.quitting {
+			return []string{"", "Sending decisions…"}
+		}
+		return []string{"", "No more comments. Press q to send the decisions and quit, p to go back."}
+	}
+
+	width := min(m.width, maxWidth)
+	lines := []string{
+		"",
+		tui.Truncate(styleBold+postTitle(*c)+styleReset, width),
+		tui.Truncate(fmt.Sprintf("%s%s · %s · #%d%s", styleDim, author(*c), c.CreatedAt.Local().Format("2006-01-02 15:04"), c.ID, styleReset), width),
+	}
+	if c.QuoteComment != nil {
+		quote := fmt.Sprintf("replying to %s: %s", author(*c.QuoteComment), strings.Join(strings.Fields(c.QuoteComment.Content), " "))
+		lines = append(lines, tui.Truncate(styleDim+quote+styleReset, width))
+	}
+	if d := m.decision(c.ID); d != "" {
+		lines = append(lines, "→ "+d)
+	}
+	lines = append(lines, "")
+	return append(lines, markdown.WrapText(strings.TrimSpace(c.Content), width)...)
+}
+
+// decision describes the decision made for a comment, if any.
+func (m *App) decision(id uint64) string {
+	if op, ok := m.decisions[id]; ok {
+		return op + " (queued)"
+	}
+	if o, ok := m.outcomes[id]; ok {
+		if !o.OK {
+			return o.Operation + " failed: " + o.Error
+		}
+		return o.Operation + " done"
+	}
+	if op, ok := m.sent[id]; ok {
+		return op + " (sending)"
+	}
+	return ""
+}
+
+func (m *App) statusLine() string {
+	if m.replying {
+		return tui.Truncate("reply: "+string(m.input), m.width)
+	}
+	if m.status != "" {
+		return tui.Truncate(m.status, m.width)
+	}
+	hint := "a approve · r reject · s spam · d delete · n skip · p back · R reply · q quit"
+	return styleDim + tui.Truncate(hint, m.width) + styleReset
+}
+
+func postTitle(c client.Comment) string {
+	if c.Post != nil && c.Post.Title != "" {
+		return c.Post.Title
+	}
+	return fmt.Sprintf("Post #%d", c.PostID)
+}
+
+func author(c client.Comment) string {
+	if c.Author != nil && c.Author.Name != "" {
+		return c.Author.Name
+	}
+	if c.AuthorID == 0 {
+		return "anonymous"
+	}
+	return fmt.Sprintf("user %d", c.AuthorID)
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quailyquaily/quail-cli/markdown"
+)
+
+// Truncate cuts s to at most width columns. Escape sequences don't take
+// columns, and a reset is appended if s had any, so styles don't leak into
+// the next line.
+func Truncate(s string, width int) string {
+	var b strings.Builder
+	w := 0
+	styled := false
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			j := escapeEnd(s, i)
+			b.WriteString(s[i:j])
+			styled = true
+			i = j
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		rw := markdown.RuneWidth(r)
+		if w+rw > width {
+			break
+		}
+		b.WriteString(s[i : i+n])
+		w += rw
+		i += n
+	}
+	if styled {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// Pad truncates s to width columns and fills the rest with spaces.
+func Pad(s string, width int) string {
+	s = Truncate(s, width)
+	if w := markdown.Width(s); w < width {
+		s += strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// StripANSI removes escape sequences from s.
+func StripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i = escapeEnd(s, i)
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// escapeEnd returns the end of the CSI sequence starting at i.
+func escapeEnd(s string, i int) int {
+	j := i + 1
+	if j < len(s) && s[j] == '[' {
+		j++
+		for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+			j++
+		}
+	}
+	return min(j+1, len(s))
+}
@@ -0,0 +1,60 @@
+// Package tui is a small framework for full-screen terminal programs.
+//
+// A program is a Model: Update handles a message, like a key press or the
+// result of a request, and returns the next model and optionally a Cmd to
+// run in the background. View returns the screen. Program runs a model in a
+// terminal, Headless runs it in tests with scripted input.
+package tui
+
+type Msg any
+
+// Cmd does work outside of Update, like a request, and returns its result
+// as a message.
+type Cmd func() Msg
+
+type Model interface {
+	Init() Cmd
+	Update(Msg) (Model, Cmd)
+	View() string
+}
+
+// KeyMsg is sent for every key press.
+type KeyMsg Key
+
+func (k KeyMsg) String() string {
+	return Key(k).String()
+}
+
+// ResizeMsg is sent at start and when the terminal size changes.
+type ResizeMsg struct {
+	Width  int
+	Height int
+}
+
+// QuitMsg ends the program.
+type QuitMsg struct{}
+
+// Quit is a Cmd that ends the program.
+func Quit() Msg {
+	return QuitMsg{}
+}
+
+// BatchMsg runs several commands at once.
+type BatchMsg []Cmd
+
+// Batch combines commands, ignoring nil ones.
+func Batch(cmds ...Cmd) Cmd {
+	var ret BatchMsg
+	for _, cmd := range cmds {
+		if cmd != nil {
+			ret = append(ret, cmd)
+		}
+	}
+	switch len(ret) {
+	case 0:
+		return nil
+	case 1:
+		return ret[0]
+	}
+	return func() Msg { return ret }
+}
@@ -24,7 +24,7 @@ func WriteSampleConfig(apiKey string) (string, error) {
 		return configFile, err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+	if err := EnsureDir(filepath.Dir(configFile)); err != nil {
 		return configFile, err
 	}
 
@@ -71,3 +71,39 @@ post:
     cover_image_url: featureImage
 `, strconv.Quote(apiKey))
 }
+
+// ReadConfigSettings returns the nested settings stored in the config file,
+// without environment or flag overrides.
+func ReadConfigSettings(configFile string) (map[string]any, error) {
+	v, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// EditConfigFile applies edit to the settings of the config file while
+// holding the config lock, then writes the file atomically.
+func EditConfigFile(configFile string, edit func(settings map[string]any) error) error {
+	unlock, err := LockFile(configFile, tokenLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	v, err := readConfigFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	settings := v.AllSettings()
+	if err := edit(settings); err != nil {
+		return err
+	}
+
+	out := viper.New()
+	out.SetConfigType("yaml")
+	if err := out.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	return WriteConfigAtomic(out, configFile)
+}
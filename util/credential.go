@@ -0,0 +1,59 @@
+package util
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/quailyquaily/quail-cli/oauth"
+)
+
+const (
+	CredentialSourceNone   = "none"
+	CredentialSourceEnv    = "env"
+	CredentialSourceAPIKey = "api_key"
+	CredentialSourceOAuth  = "oauth"
+)
+
+// Credential is the credential quail-cli authenticates with, and where it
+// came from.
+type Credential struct {
+	Source string
+	Token  string
+	Expiry time.Time
+	Scopes []string
+}
+
+// ResolveCredential picks the credential in the documented order:
+// QUAIL_API_KEY, then app.api_key, then the OAuth token.
+func ResolveCredential() Credential {
+	if apiKey := strings.TrimSpace(os.Getenv("QUAIL_API_KEY")); apiKey != "" {
+		return Credential{Source: CredentialSourceEnv, Token: apiKey}
+	}
+	c := config.Current()
+	if apiKey := strings.TrimSpace(c.App.APIKey); apiKey != "" {
+		return Credential{Source: CredentialSourceAPIKey, Token: apiKey}
+	}
+	token := c.App.Token()
+	if token.AccessToken != "" {
+		return Credential{
+			Source: CredentialSourceOAuth,
+			Token:  token.AccessToken,
+			Expiry: token.Expiry,
+			Scopes: oauth.Scopes(token),
+		}
+	}
+	return Credential{Source: CredentialSourceNone}
+}
+
+// MaskSecret keeps just enough of a key or token to tell it apart.
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 12 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:6] + "..." + secret[len(secret)-4:]
+}
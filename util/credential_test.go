@@ -0,0 +1,38 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveCredentialOrder(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	viper.Set("app.access_token", "oauth-token")
+	viper.Set("app.scope", "user.full post.write")
+	t.Setenv("QUAIL_API_KEY", "")
+	if got := ResolveCredential(); got.Source != CredentialSourceOAuth || len(got.Scopes) != 2 {
+		t.Fatalf("ResolveCredential() = %+v, want oauth with 2 scopes", got)
+	}
+
+	viper.Set("app.api_key", "QK-config")
+	if got := ResolveCredential(); got.Source != CredentialSourceAPIKey || got.Token != "QK-config" {
+		t.Fatalf("ResolveCredential() = %+v, want config api key", got)
+	}
+
+	t.Setenv("QUAIL_API_KEY", " QK-env ")
+	if got := ResolveCredential(); got.Source != CredentialSourceEnv || got.Token != "QK-env" {
+		t.Fatalf("ResolveCredential() = %+v, want env api key", got)
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	if got := MaskSecret("QK-1234567890abcdef"); got != "QK-123...cdef" {
+		t.Fatalf("MaskSecret() = %q", got)
+	}
+	if got := MaskSecret("short"); got != "*****" {
+		t.Fatalf("MaskSecret() = %q, want fully masked", got)
+	}
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+const appDirName = "quail-cli"
+
+// GetConfigFilePath returns the directory of config.yaml:
+// $XDG_CONFIG_HOME/quail-cli, or ~/.config/quail-cli.
+// Like the other directory helpers it doesn't create the directory, writers
+// call EnsureDir first.
+func GetConfigFilePath() string {
+	return filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), appDirName)
+}
+
+// GetStateDir returns the directory for data that should survive restarts
+// but isn't configuration, like sync state, queues and logs:
+// $XDG_STATE_HOME/quail-cli, or ~/.local/state/quail-cli.
+func GetStateDir() string {
+	return filepath.Join(xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state")), appDirName)
+}
+
+// GetCacheDir returns the directory for data that can be fetched again,
+// like reader caches: $XDG_CACHE_HOME/quail-cli, or ~/.cache/quail-cli.
+func GetCacheDir() string {
+	return filepath.Join(xdgDir("XDG_CACHE_HOME", ".cache"), appDirName)
+}
+
+// EnsureDir creates dir, readable by the current user only.
+func EnsureDir(dir string) error {
+	return os.MkdirAll(dir, 0700)
+}
+
+func xdgDir(env, fallback string) string {
+	// the spec says relative paths are invalid and must be ignored
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	cobra.CheckErr(err)
+	return filepath.Join(home, fallback)
+}
+
+func legacyConfigDir() string {
+	home, err := os.UserHomeDir()
+	cobra.CheckErr(err)
+	return filepath.Join(home, ".config", appDirName)
+}
+
+// MigrateLegacyConfig moves config.yaml from ~/.config/quail-cli to the XDG
+// config directory, when XDG_CONFIG_HOME points elsewhere and no config
+// exists there yet. It returns the new path if it moved the file.
+func MigrateLegacyConfig() (string, error) {
+	newDir := GetConfigFilePath()
+	oldDir := legacyConfigDir()
+	if filepath.Clean(newDir) == filepath.Clean(oldDir) {
+		return "", nil
+	}
+
+	oldFile := filepath.Join(oldDir, "config.yaml")
+	newFile := filepath.Join(newDir, "config.yaml")
+	if _, err := os.Stat(newFile); err == nil || !os.IsNotExist(err) {
+		return "", nil
+	}
+	if _, err := os.Stat(oldFile); err != nil {
+		return "", nil
+	}
+
+	if err := EnsureDir(newDir); err != nil {
+		return "", err
+	}
+	if err := moveFile(oldFile, newFile); err != nil {
+		return "", fmt.Errorf("failed to move %s to %s: %w", oldFile, newFile, err)
+	}
+	return newFile, nil
+}
+
+// moveFile renames src to dst, copying when they are on different devices.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
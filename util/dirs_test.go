@@ -0,0 +1,120 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirsHonorXDG(t *testing.T) {
+	home := t.TempDir()
+	xdg := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(xdg, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(xdg, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(xdg, "cache"))
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"config", GetConfigFilePath(), filepath.Join(xdg, "config", "quail-cli")},
+		{"state", GetStateDir(), filepath.Join(xdg, "state", "quail-cli")},
+		{"cache", GetCacheDir(), filepath.Join(xdg, "cache", "quail-cli")},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Fatalf("%s dir = %q, want %q", tt.name, tt.got, tt.want)
+		}
+		if _, err := os.Stat(tt.got); !os.IsNotExist(err) {
+			t.Fatalf("%s dir was created as a side effect", tt.name)
+		}
+	}
+}
+
+func TestDirsFallBackToHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "relative/is/ignored")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	if got := GetConfigFilePath(); got != filepath.Join(home, ".config", "quail-cli") {
+		t.Fatalf("GetConfigFilePath() = %q", got)
+	}
+	if got := GetStateDir(); got != filepath.Join(home, ".local", "state", "quail-cli") {
+		t.Fatalf("GetStateDir() = %q", got)
+	}
+	if got := GetCacheDir(); got != filepath.Join(home, ".cache", "quail-cli") {
+		t.Fatalf("GetCacheDir() = %q", got)
+	}
+}
+
+func TestEnsureDirIsPrivate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	if err := EnsureDir(dir); err != nil {
+		t.Fatalf("EnsureDir() error = %v", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Fatalf("permissions = %o, want 700", perm)
+	}
+}
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	home := t.TempDir()
+	xdg := filepath.Join(t.TempDir(), "config")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	legacy := filepath.Join(home, ".config", "quail-cli", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(legacy, []byte("app:\n  api_key: QK-test\n"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := MigrateLegacyConfig()
+	if err != nil {
+		t.Fatalf("MigrateLegacyConfig() error = %v", err)
+	}
+	want := filepath.Join(xdg, "quail-cli", "config.yaml")
+	if got != want {
+		t.Fatalf("MigrateLegacyConfig() = %q, want %q", got, want)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != "app:\n  api_key: QK-test\n" {
+		t.Fatalf("migrated config = %q, %v", data, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatal("legacy config still exists")
+	}
+
+	// the migration runs only once
+	if got, err := MigrateLegacyConfig(); got != "" || err != nil {
+		t.Fatalf("second MigrateLegacyConfig() = %q, %v; want no-op", got, err)
+	}
+}
+
+func TestMigrateLegacyConfigKeepsExistingConfig(t *testing.T) {
+	home := t.TempDir()
+	xdg := filepath.Join(t.TempDir(), "config")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	for _, path := range []string{
+		filepath.Join(home, ".config", "quail-cli", "config.yaml"),
+		filepath.Join(xdg, "quail-cli", "config.yaml"),
+	} {
+		os.MkdirAll(filepath.Dir(path), 0700)
+		os.WriteFile(path, []byte(path), 0600)
+	}
+
+	if got, err := MigrateLegacyConfig(); got != "" || err != nil {
+		t.Fatalf("MigrateLegacyConfig() = %q, %v; want no-op", got, err)
+	}
+}
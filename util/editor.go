@@ -0,0 +1,54 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Scissors marks the end of the text in EditText. It and the lines after it
+// are removed.
+const Scissors = "# ------------------------ >8 ------------------------"
+
+// EditText opens $VISUAL, $EDITOR or vi on a temporary file holding initial,
+// and returns the file once the editor exits, without the scissors line and
+// what follows it.
+func EditText(initial string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return "", errors.New("no editor, set $EDITOR")
+	}
+
+	f, err := os.CreateTemp("", "quail-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(initial); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	text, _, _ := strings.Cut(string(data), Scissors)
+	return text, nil
+}
@@ -0,0 +1,16 @@
+package util
+
+import "testing"
+
+func TestEditText(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "sed -i -e 1s/^$/hello/")
+
+	got, err := EditText("\n" + Scissors + "\n# help\n")
+	if err != nil {
+		t.Fatalf("EditText() error = %v", err)
+	}
+	if got != "hello\n" {
+		t.Fatalf("EditText() = %q, want %q", got, "hello\n")
+	}
+}
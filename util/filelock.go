@@ -0,0 +1,47 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// LockFile takes an exclusive lock next to path, shared by all quail-cli
+// processes. The lock is an OS file lock, flock or LockFileEx, so it is
+// released when its process exits, even by a crash, and never has to be
+// judged stale. The lock file itself is left in place: removing it would let
+// a waiter lock a file no longer used as the lock.
+func LockFile(path string, timeout time.Duration) (unlock func(), err error) {
+	lockPath := path + ".lock"
+	if err := EnsureDir(filepath.Dir(lockPath)); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s", ErrLockTimeout, lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
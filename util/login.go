@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,23 +10,10 @@ import (
 
 	"github.com/quailyquaily/quail-cli/client"
 	"github.com/quailyquaily/quail-cli/oauth"
-	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/term"
 )
 
-func GetConfigFilePath() string {
-	home, err := os.UserHomeDir()
-	cobra.CheckErr(err)
-
-	fullpath := filepath.Join(home, ".config", "quail-cli")
-	if _, err := os.Stat(fullpath); os.IsNotExist(err) {
-		os.MkdirAll(fullpath, 0755)
-	}
-
-	return fullpath
-}
-
 func LoginAPIKey(apiKey string) error {
 	apiKey = strings.TrimSpace(apiKey)
 	if apiKey == "" {
@@ -62,10 +50,7 @@ func Login(authBase, apiBase string) (authCodeURL string, err error) {
 		return
 	}
 
-	viper.Set("app.access_token", token.AccessToken)
-	viper.Set("app.refresh_token", token.RefreshToken)
-	viper.Set("app.token_type", token.TokenType)
-	viper.Set("app.expiry", token.Expiry)
+	setToken(viper.GetViper(), token)
 
 	cl := client.New(token.AccessToken, apiBase)
 	result, err := cl.GetMe()
@@ -93,11 +78,70 @@ func writeConfig() error {
 		viper.SetConfigFile(configFile)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+	unlock, err := LockFile(configFile, tokenLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return WriteConfigAtomic(viper.GetViper(), configFile)
+}
+
+var ErrRevokeFailed = errors.New("token revocation failed")
+
+// credentialKeys are the config keys that hold secrets or the identity they
+// belong to.
+var credentialKeys = map[string]any{
+	"app.api_key":       "",
+	"app.access_token":  "",
+	"app.refresh_token": "",
+	"app.token_type":    "",
+	"app.expiry":        "",
+	"app.scope":         "",
+	"app.user.id":       0,
+	"app.user.name":     "",
+	"app.user.bio":      "",
+}
+
+// Logout revokes the OAuth token at the server and scrubs credentials from
+// the config file. The config is scrubbed even if revocation fails, that
+// error is returned afterwards wrapped in ErrRevokeFailed.
+func Logout(apiBase string) error {
+	configFile := ResolveConfigFile()
+	unlock, err := LockFile(configFile, tokenLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	v, err := readConfigFile(configFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
 		return err
 	}
-	if _, err := os.Stat(configFile); err == nil {
-		return viper.WriteConfig()
+
+	var revokeErr error
+	token := tokenFromViper(v)
+	if token.RefreshToken != "" {
+		if err := oauth.RevokeToken(apiBase, token.RefreshToken, "refresh_token"); err != nil {
+			revokeErr = errors.Join(revokeErr, fmt.Errorf("failed to revoke refresh token: %w", err))
+		}
+	}
+	if token.AccessToken != "" {
+		if err := oauth.RevokeToken(apiBase, token.AccessToken, "access_token"); err != nil {
+			revokeErr = errors.Join(revokeErr, fmt.Errorf("failed to revoke access token: %w", err))
+		}
 	}
-	return viper.WriteConfigAs(configFile)
+
+	for key, value := range credentialKeys {
+		v.Set(key, value)
+		viper.Set(key, value)
+	}
+	if err := WriteConfigAtomic(v, configFile); err != nil {
+		return err
+	}
+	if revokeErr != nil {
+		return fmt.Errorf("%w: %w", ErrRevokeFailed, revokeErr)
+	}
+	return nil
 }
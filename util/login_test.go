@@ -0,0 +1,71 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLogoutRevokesAndScrubs(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	revoked := make([]string, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/revoke" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.ParseForm()
+		revoked = append(revoked, r.Form.Get("token_type_hint")+":"+r.Form.Get("token"))
+	}))
+	defer srv.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configFile, []byte("app:\n  api_key: QK-secret\n  access_token: at\n  refresh_token: rt\npost:\n  frontmatter_mapping:\n    title: name\n"), 0600)
+	viper.SetConfigFile(configFile)
+
+	if err := Logout(srv.URL); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if strings.Join(revoked, ",") != "refresh_token:rt,access_token:at" {
+		t.Fatalf("revoked = %v", revoked)
+	}
+
+	data, _ := os.ReadFile(configFile)
+	for _, secret := range []string{"QK-secret", "at\n", "rt\n"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("config still contains %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "title: name") {
+		t.Fatalf("config lost other settings:\n%s", data)
+	}
+}
+
+func TestLogoutScrubsWhenRevocationFails(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configFile, []byte("app:\n  access_token: at\n"), 0600)
+	viper.SetConfigFile(configFile)
+
+	if err := Logout(srv.URL); !errors.Is(err, ErrRevokeFailed) {
+		t.Fatalf("Logout() error = %v, want ErrRevokeFailed", err)
+	}
+	if token, _ := NewConfigTokenStore(configFile).Load(); token.AccessToken != "" {
+		t.Fatalf("access token = %q, want scrubbed", token.AccessToken)
+	}
+}
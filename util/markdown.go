@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/quailyquaily/quail-cli/core"
@@ -51,3 +52,13 @@ func ParseMarkdownWithFrontMatter(filepath string, frontMatterMapping map[string
 
 	return frontMatter, content.String(), nil
 }
+
+var imageURLPattern = regexp.MustCompile(`(!\[[^\]]*\]\()([^)\s]+)`)
+
+// RewriteImageURLs passes the URL of every Markdown image through rewrite.
+func RewriteImageURLs(content string, rewrite func(string) string) string {
+	return imageURLPattern.ReplaceAllStringFunc(content, func(m string) string {
+		parts := imageURLPattern.FindStringSubmatch(m)
+		return parts[1] + rewrite(parts[2])
+	})
+}
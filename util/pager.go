@@ -0,0 +1,74 @@
+package util
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// StdoutIsTerminal reports whether stdout is an interactive terminal.
+func StdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// TerminalWidth returns the width of the terminal on stdout, or fallback when
+// stdout isn't a terminal.
+func TerminalWidth(fallback int) int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	return fallback
+}
+
+// UseColor reports whether output to stdout should be styled. NO_COLOR
+// turns styling off, see https://no-color.org.
+func UseColor() bool {
+	return StdoutIsTerminal() && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// StartPager pipes what is written to the returned writer through $PAGER
+// when stdout is a terminal, or `less` when PAGER isn't set. Otherwise it
+// writes to stdout. Call wait after writing to let the pager exit.
+func StartPager() (w io.Writer, wait func()) {
+	stdout := func() (io.Writer, func()) { return os.Stdout, func() {} }
+	if !StdoutIsTerminal() {
+		return stdout()
+	}
+
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = "less"
+	}
+	args := strings.Fields(pager)
+	if len(args) == 0 || args[0] == "cat" {
+		return stdout()
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return stdout()
+	}
+
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// quit when the text fits on one screen, keep colors, don't clear
+		// the screen on exit, like git does
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return stdout()
+	}
+	if err := cmd.Start(); err != nil {
+		return stdout()
+	}
+	return in, func() {
+		in.Close()
+		cmd.Wait()
+	}
+}
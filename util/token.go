@@ -0,0 +1,113 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quailyquaily/quail-cli/config"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+const tokenLockTimeout = 15 * time.Second
+
+// ConfigTokenStore keeps the OAuth token in the app section of the config
+// file. Every save re-reads the file, so settings written by other processes
+// are kept.
+type ConfigTokenStore struct {
+	ConfigFile string
+}
+
+func NewConfigTokenStore(configFile string) *ConfigTokenStore {
+	return &ConfigTokenStore{ConfigFile: configFile}
+}
+
+func (s *ConfigTokenStore) Lock() (func(), error) {
+	return LockFile(s.ConfigFile, tokenLockTimeout)
+}
+
+func (s *ConfigTokenStore) Load() (*oauth2.Token, error) {
+	v, err := readConfigFile(s.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	return tokenFromViper(v), nil
+}
+
+func (s *ConfigTokenStore) Save(token *oauth2.Token) error {
+	v, err := readConfigFile(s.ConfigFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	setToken(v, token)
+	if err := WriteConfigAtomic(v, s.ConfigFile); err != nil {
+		return err
+	}
+
+	// keep the process-wide config in sync with the file
+	setToken(viper.GetViper(), token)
+	return nil
+}
+
+// TokenFromConfig returns the OAuth token of the loaded config.
+func TokenFromConfig() *oauth2.Token {
+	return tokenFromViper(viper.GetViper())
+}
+
+// WriteConfigAtomic writes the settings of v to a temporary file and renames
+// it over configFile, so other processes never read a half-written config.
+func WriteConfigAtomic(v *viper.Viper, configFile string) error {
+	dir := filepath.Dir(configFile)
+	if err := EnsureDir(dir); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(configFile)
+	if ext == "" {
+		ext = ".yaml"
+	}
+	tmp, err := os.CreateTemp(dir, ".config-*"+ext)
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpName)
+
+	if err := v.WriteConfigAs(tmpName); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, configFile)
+}
+
+func readConfigFile(configFile string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if filepath.Ext(configFile) == "" {
+		v.SetConfigType("yaml")
+	}
+	if _, err := os.Stat(configFile); err != nil {
+		return v, err
+	}
+	return v, v.ReadInConfig()
+}
+
+func tokenFromViper(v *viper.Viper) *oauth2.Token {
+	c, _ := config.FromViper(v)
+	return c.App.Token()
+}
+
+func setToken(v *viper.Viper, token *oauth2.Token) {
+	v.Set("app.access_token", token.AccessToken)
+	v.Set("app.refresh_token", token.RefreshToken)
+	v.Set("app.token_type", token.TokenType)
+	v.Set("app.expiry", token.Expiry)
+	// a refresh response may leave out the scope, keep the granted one then
+	if scope, _ := token.Extra("scope").(string); scope != "" {
+		v.Set("app.scope", scope)
+	}
+}
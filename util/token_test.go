@@ -0,0 +1,88 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+func TestConfigTokenStoreKeepsOtherSettings(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("app:\n  access_token: old\npost:\n  frontmatter_mapping:\n    cover_image_url: featureImage\n"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	store := NewConfigTokenStore(configFile)
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := store.Save(&oauth2.Token{AccessToken: "new", RefreshToken: "r", TokenType: "Bearer", Expiry: expiry}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if token.AccessToken != "new" || token.RefreshToken != "r" || !token.Expiry.Equal(expiry) {
+		t.Fatalf("Load() = %+v, want saved token", token)
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "cover_image_url: featureImage") {
+		t.Fatalf("config lost other settings:\n%s", data)
+	}
+	info, err := os.Stat(configFile)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Fatalf("config permissions = %o, want 600", perm)
+	}
+	if viper.GetString("app.access_token") != "new" {
+		t.Fatal("Save() did not update the loaded config")
+	}
+}
+
+func TestLockFileIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	unlock, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	if _, err := LockFile(path, 100*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("second LockFile() error = %v, want ErrLockTimeout", err)
+	}
+
+	unlock()
+	unlock, err = LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() after unlock error = %v", err)
+	}
+	unlock()
+}
+
+func TestLockFileIgnoresLeftoverFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	// a process that crashed holding the lock leaves its file behind
+	if err := os.WriteFile(path+".lock", []byte("123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := LockFile(path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	unlock()
+}